@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/rudraa2005/mic-website-main/backend/internal/db"
+	"github.com/rudraa2005/mic-website-main/backend/internal/storage"
+	"github.com/rudraa2005/mic-website-main/backend/internal/textextract"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	all := flag.Bool("all", false, "extract again from files that already have text")
+	flag.Usage = func() {
+		fmt.Println("Usage: go run ./cmd/extract-text [flags]")
+		fmt.Println()
+		fmt.Println("Reads the text of submission attachments that have none yet, such as")
+		fmt.Println("files uploaded before search existed, so that search finds them.")
+		fmt.Println("It is safe to run more than once.")
+		fmt.Println()
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	pool, err := db.NewPool()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	blobs, err := storage.Open(storage.ConfigFromEnv())
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	rows, err := pool.Query(ctx, `
+		SELECT id, storage_path, mime_type
+		FROM submission_files
+		WHERE $1 OR text_content = ''
+		ORDER BY created_at
+	`, *all)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	type file struct{ id, key, mimeType string }
+	var pending []file
+	for rows.Next() {
+		var f file
+		if err := rows.Scan(&f.id, &f.key, &f.mimeType); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if textextract.Supported(f.mimeType) {
+			pending = append(pending, f)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	done, failed := 0, 0
+	for _, f := range pending {
+		text, err := extract(ctx, blobs, f.key, f.mimeType)
+		if err != nil {
+			fmt.Println("file", f.id+":", err)
+			failed++
+			continue
+		}
+		if _, err := pool.Exec(ctx, `UPDATE submission_files SET text_content = $2 WHERE id = $1`, f.id, text); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		done++
+	}
+
+	fmt.Printf("Extracted text from %d files\n", done)
+	if failed > 0 {
+		fmt.Printf("%d files could not be read\n", failed)
+		os.Exit(1)
+	}
+}
+
+func extract(ctx context.Context, blobs storage.Blob, key, mimeType string) (string, error) {
+	rc, err := blobs.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	body, err := io.ReadAll(rc)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, textextract.Timeout)
+	defer cancel()
+	return textextract.Extract(ctx, bytes.NewReader(body), int64(len(body)), mimeType)
+}
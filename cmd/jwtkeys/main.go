@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+)
+
+func usage() {
+	fmt.Println("Usage: go run ./cmd/jwtkeys <command>")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  init     create a key ring with a single active key")
+	fmt.Println("  rotate   retire the active key and generate a new one")
+	fmt.Println("  list     show the keys in the ring")
+	fmt.Println()
+	fmt.Println("The key ring path is read from JWT_KEYS_FILE. After rotating, send")
+	fmt.Println("SIGHUP to the server (or restart it) to pick up the new key.")
+}
+
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	cfg, err := auth.ConfigFromEnv()
+	if err != nil && !errors.Is(err, auth.ErrNoSigningKeys) {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if cfg.KeysFile == "" {
+		fmt.Println("Error: JWT_KEYS_FILE is not set")
+		os.Exit(1)
+	}
+
+	now := time.Now().UTC()
+
+	switch os.Args[1] {
+	case "init":
+		if _, err := os.Stat(cfg.KeysFile); err == nil {
+			fmt.Println("Error:", cfg.KeysFile, "already exists, use rotate instead")
+			os.Exit(1)
+		}
+		key, err := auth.NewSigningKey(now)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		kr := &auth.KeyRing{Keys: []auth.SigningKey{*key}}
+		if err := kr.Save(cfg.KeysFile); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Created key ring with active key", key.ID)
+
+	case "rotate":
+		kr, err := auth.LoadKeyRing(cfg.KeysFile)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		key, err := kr.Rotate(now, cfg.KeyGrace)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if err := kr.Save(cfg.KeysFile); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("New active key:", key.ID)
+		fmt.Println("Previous keys stay valid for verification for", cfg.KeyGrace)
+
+	case "list":
+		kr, err := auth.LoadKeyRing(cfg.KeysFile)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		for _, k := range kr.Keys {
+			status := "active"
+			if k.RetiredAt != nil {
+				status = fmt.Sprintf("retired %s, verifies until %s",
+					k.RetiredAt.Format(time.RFC3339),
+					k.RetiredAt.Add(cfg.KeyGrace).Format(time.RFC3339))
+			}
+			fmt.Printf("%s  created %s  %s\n", k.ID, k.CreatedAt.Format(time.RFC3339), status)
+		}
+
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
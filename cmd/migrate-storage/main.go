@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"github.com/rudraa2005/mic-website-main/backend/internal/db"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+	"github.com/rudraa2005/mic-website-main/backend/internal/storage"
+)
+
+// legacyPhotoPrefix is where profile photos used to be served from, out of
+// frontend/static/uploads.
+const legacyPhotoPrefix = "/static/uploads/"
+
+type migrator struct {
+	pool   *pgxpool.Pool
+	blobs  storage.Blob
+	root   string
+	dryRun bool
+	remove bool
+}
+
+func main() {
+	_ = godotenv.Load()
+
+	m := &migrator{}
+	flag.StringVar(&m.root, "root", ".", "directory that relative paths in the database are resolved against")
+	flag.BoolVar(&m.dryRun, "dry-run", false, "list what would be moved without changing anything")
+	flag.BoolVar(&m.remove, "remove", false, "delete local files after they have been moved")
+	flag.Usage = func() {
+		fmt.Println("Usage: go run ./cmd/migrate-storage [flags]")
+		fmt.Println()
+		fmt.Println("Moves submission attachments and profile photos that are still stored")
+		fmt.Println("as files on disk into the blob store configured by STORAGE_BACKEND,")
+		fmt.Println("and points the database at their content-addressed keys. Files that")
+		fmt.Println("were already moved are skipped, so it is safe to run more than once.")
+		fmt.Println()
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	pool, err := db.NewPool()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+	m.pool = pool
+
+	m.blobs, err = storage.Open(storage.ConfigFromEnv())
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	files, failedFiles, err := m.submissionFiles(ctx)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	photos, failedPhotos, err := m.profilePhotos(ctx)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Moved %d submission files and %d profile photos\n", files, photos)
+	if failedFiles+failedPhotos > 0 {
+		fmt.Printf("%d submission files and %d profile photos could not be moved\n", failedFiles, failedPhotos)
+		os.Exit(1)
+	}
+}
+
+// submissionFiles moves every attachment whose storage_path is still a disk
+// path.
+func (m *migrator) submissionFiles(ctx context.Context) (int, int, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT id, storage_path, mime_type
+		FROM submission_files
+		WHERE storage_path NOT LIKE $1
+		ORDER BY created_at
+	`, service.SubmissionFilePrefix+"/%")
+	if err != nil {
+		return 0, 0, err
+	}
+	type legacyFile struct{ id, path, mimeType string }
+	var pending []legacyFile
+	for rows.Next() {
+		var f legacyFile
+		if err := rows.Scan(&f.id, &f.path, &f.mimeType); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		pending = append(pending, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	moved, failed := 0, 0
+	for _, f := range pending {
+		obj, err := m.move(ctx, f.path, service.SubmissionFilePrefix, f.mimeType)
+		if err != nil {
+			fmt.Println("file", f.id+":", err)
+			failed++
+			continue
+		}
+		if obj == nil {
+			continue
+		}
+		_, err = m.pool.Exec(ctx, `
+			UPDATE submission_files
+			SET storage_path = $2, size_bytes = $3, checksum = $4
+			WHERE id = $1
+		`, f.id, obj.Key, obj.Size, obj.SHA256)
+		if err != nil {
+			return moved, failed, err
+		}
+		m.cleanUp(f.path)
+		moved++
+	}
+	return moved, failed, nil
+}
+
+// profilePhotos moves every photo still served from frontend/static/uploads.
+func (m *migrator) profilePhotos(ctx context.Context) (int, int, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT user_id, photo_url
+		FROM profiles
+		WHERE photo_url LIKE $1
+	`, legacyPhotoPrefix+"%")
+	if err != nil {
+		return 0, 0, err
+	}
+	type legacyPhoto struct{ userID, url string }
+	var pending []legacyPhoto
+	for rows.Next() {
+		var p legacyPhoto
+		if err := rows.Scan(&p.userID, &p.url); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	moved, failed := 0, 0
+	for _, p := range pending {
+		name := filepath.Base(strings.TrimPrefix(p.url, legacyPhotoPrefix))
+		path := filepath.Join("frontend", "static", "uploads", name)
+		obj, err := m.move(ctx, path, service.ProfilePhotoPrefix, "")
+		if err != nil {
+			fmt.Println("photo of", p.userID+":", err)
+			failed++
+			continue
+		}
+		if obj == nil {
+			continue
+		}
+		_, err = m.pool.Exec(ctx, `
+			UPDATE profiles SET photo_url = $2, photo_size_bytes = $3 WHERE user_id = $1
+		`, p.userID, service.ProfilePhotoURL(obj.SHA256), obj.Size)
+		if err != nil {
+			return moved, failed, err
+		}
+		m.cleanUp(path)
+		moved++
+	}
+	return moved, failed, nil
+}
+
+// move copies a local file into the blob store. In a dry run it only checks
+// that the file exists and returns nil.
+func (m *migrator) move(ctx context.Context, path, prefix, contentType string) (*storage.Object, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.root, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if m.dryRun {
+		fmt.Println("would move", path)
+		return nil, nil
+	}
+	obj, err := storage.PutContent(ctx, m.blobs, prefix, f, contentType)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("moved", path, "to", obj.Key)
+	return obj, nil
+}
+
+func (m *migrator) cleanUp(path string) {
+	if !m.remove {
+		return
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.root, path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Println("could not remove", path+":", err)
+	}
+}
@@ -1,18 +1,30 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
 	"github.com/rudraa2005/mic-website-main/backend/internal/db"
 	"github.com/rudraa2005/mic-website-main/backend/internal/email"
 	"github.com/rudraa2005/mic-website-main/backend/internal/handler"
 	h "github.com/rudraa2005/mic-website-main/backend/internal/handler"
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/oidc"
 	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
 	r "github.com/rudraa2005/mic-website-main/backend/internal/router"
+	"github.com/rudraa2005/mic-website-main/backend/internal/scan"
 	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+	"github.com/rudraa2005/mic-website-main/backend/internal/storage"
 )
 
 func main() {
@@ -21,6 +33,28 @@ func main() {
 		log.Println("No .env file found, relying on environment variables")
 	}
 	log.Println("Starting MIC Website Backend Server...")
+
+	authConfig, err := auth.ConfigFromEnv()
+	if err != nil {
+		log.Fatal("JWT configuration invalid: ", err)
+	}
+	if err := auth.Init(authConfig); err != nil {
+		log.Fatal("Failed to load JWT signing keys: ", err)
+	}
+	go reloadKeysOnSignal()
+
+	passwordMinLength := 10
+	if v := os.Getenv("PASSWORD_MIN_LENGTH"); v != "" {
+		passwordMinLength, err = strconv.Atoi(v)
+		if err != nil || passwordMinLength < 8 {
+			log.Fatal("PASSWORD_MIN_LENGTH must be a number of at least 8")
+		}
+	}
+	passwordPolicy, err := auth.NewPasswordPolicy(passwordMinLength, os.Getenv("PASSWORD_BLOCKLIST_FILE"))
+	if err != nil {
+		log.Fatal("Failed to load password policy: ", err)
+	}
+
 	log.Println("Connecting to database...")
 
 	pool, err := db.NewPool()
@@ -31,6 +65,29 @@ func main() {
 
 	log.Println("Database connection established successfully")
 
+	blobs, err := storage.Open(storage.ConfigFromEnv())
+	if err != nil {
+		log.Fatal("Storage configuration invalid: ", err)
+	}
+
+	// Uploads are scanned by clamd when CLAMD_ADDR is set
+	var scanner scan.Scanner = scan.Noop{}
+	if addr := os.Getenv("CLAMD_ADDR"); addr != "" {
+		scanner = scan.NewClamd(addr, 30*time.Second)
+	} else {
+		log.Println("CLAMD_ADDR not set, uploads are not virus scanned")
+	}
+	uploadQuotaMB := 100
+	if v := os.Getenv("UPLOAD_QUOTA_MB"); v != "" {
+		uploadQuotaMB, err = strconv.Atoi(v)
+		if err != nil || uploadQuotaMB < 0 {
+			log.Fatal("UPLOAD_QUOTA_MB must be a non-negative number")
+		}
+	}
+	storageReleaseRepo := repository.NewStorageReleaseRepo(pool)
+	uploadInspector := service.NewUploadInspector(blobs, scanner, repository.NewUploadUsageRepo(pool), storageReleaseRepo, int64(uploadQuotaMB)<<20)
+	go service.NewStorageSweeper(storageReleaseRepo, blobs).Run(context.Background(), 15*time.Minute)
+
 	userRepo := repository.NewAuthRepository(pool)
 	profileRepo := repository.NewProfileRepo(pool)
 	settingsRepo := repository.NewSettingsRepo(pool)
@@ -42,7 +99,6 @@ func main() {
 	queryService := service.NewQueryService(queryRepo)
 	feedbackService := service.NewFeedbackService(feedbackRepo)
 
-	facultyRepo := repository.NewFacultyRepository(pool)
 	emailService := email.NewSMTPService(
 		os.Getenv("SMTP_FROM"),
 		os.Getenv("SMTP_PASSWORD"),
@@ -53,8 +109,32 @@ func main() {
 	notificationService := service.NewNotificationService(notificationRepo, emailService)
 
 	queryHandler := handler.NewQueryHandler(queryService)
+	permissionRepo := repository.NewPermissionRepo(pool)
+	permissionService := service.NewPermissionService(permissionRepo)
+
+	// Every submission status change goes through the workflow
+	submissionWorkflowRepo := repository.NewSubmissionWorkflowRepo(pool)
+	submissionWorkflow := service.NewSubmissionWorkflow(submissionWorkflowRepo, permissionService)
+	submissionWorkflow.OnEnter(model.SubmissionApproved, service.StartIncubationHook(submissionWorkflowRepo))
+	submissionTeamRepo := repository.NewSubmissionTeamRepo(pool)
+	submissionWorkflow.OnEnter("", notificationService.SubmissionTransitionHook(submissionTeamRepo))
+	submissionSettingsService := service.NewSubmissionSettingsService(repository.NewSubmissionSettingsRepo(pool))
+	submissionWorkflow.Guard(model.SubmissionRevising, submissionSettingsService.CheckRevisionLimit)
+	submissionWorkflow.OnEnter(model.SubmissionAdminApproved, notificationService.ResubmissionHook(submissionWorkflowRepo))
+	submissionRevisionService := service.NewSubmissionRevisionService(repository.NewSubmissionRevisionRepo(pool))
+	submissionWorkflow.OnEnter(model.SubmissionSubmitted, submissionRevisionService.SubmittedHook)
+	submissionWorkflow.OnEnter(model.SubmissionAdminApproved, submissionRevisionService.SubmittedHook)
+	submissionFileService := service.NewSubmissionFileService(repository.NewSubmissionFileRepo(pool), submissionWorkflow, submissionRevisionService, uploadInspector, storageReleaseRepo, blobs)
+	submissionWorkflow.Guard(model.SubmissionSubmitted, submissionFileService.CheckRequired)
+	submissionWorkflow.Guard(model.SubmissionAdminApproved, submissionFileService.CheckRequired)
+	applicationCycleService := service.NewApplicationCycleService(repository.NewApplicationCycleRepo(pool))
+	submissionWorkflow.Guard(model.SubmissionSubmitted, applicationCycleService.CheckOpen)
+	applicationFormService := service.NewApplicationFormService(repository.NewApplicationFormRepo(pool))
+	submissionWorkflow.Guard(model.SubmissionSubmitted, applicationFormService.CheckAnswers)
+	submissionWorkflow.Guard(model.SubmissionAdminApproved, applicationFormService.CheckAnswers)
+
 	facultyReviewRepo := repository.NewFacultySubmissionRepo(pool)
-	facultyReviewService := service.NewFacultyReviewService(facultyReviewRepo, notificationService)
+	facultyReviewService := service.NewFacultyReviewService(facultyReviewRepo, submissionWorkflow, submissionRevisionService, submissionFileService)
 	facultyReviewHandler := handler.NewFacultyReviewHandler(facultyReviewService)
 	feedbackHandler := handler.NewFeedbackHandler(feedbackService)
 	aiRepo := repository.NewAIRepo(pool)
@@ -63,9 +143,50 @@ func main() {
 		aiRepo,
 		&http.Client{},
 	)
-	aiHandler := handler.NewAIHandler(aiService)
-	authService := service.NewAuthService(userRepo, facultyRepo, profileRepo, settingsRepo)
-	authHandler := handler.NewAuthHandler(authService)
+	appBaseURL := os.Getenv("APP_BASE_URL")
+	if appBaseURL == "" {
+		appBaseURL = "http://localhost:8080"
+	}
+	userTokenRepo := repository.NewUserTokenRepo(pool)
+	emailVerificationService := service.NewEmailVerificationService(userRepo, userRepo, userTokenRepo, emailService, appBaseURL)
+	emailVerificationHandler := handler.NewEmailVerificationHandler(emailVerificationService)
+
+	// Cookie sessions are for the server-rendered site. COOKIE_SECURE=false
+	// is only for local development over plain http.
+	sessionCookies := handler.NewSessionCookies(
+		os.Getenv("AUTH_COOKIE_MODE") == "true",
+		os.Getenv("COOKIE_SECURE") != "false",
+	)
+
+	sessionRepo := repository.NewSessionRepo(pool)
+	accessTokenRepo := repository.NewAccessTokenRepo(pool)
+	mfaIssuer := os.Getenv("MFA_ISSUER")
+	if mfaIssuer == "" {
+		mfaIssuer = "MAHE Innovation Centre"
+	}
+	mfaRepo := repository.NewMFARepo(pool)
+	mfaService := service.NewMFAService(mfaRepo, sessionRepo, mfaIssuer)
+	mfaHandler := handler.NewMFAHandler(mfaService, sessionCookies)
+
+	loginAttemptRepo := repository.NewLoginAttemptRepo(pool)
+	loginThrottle := service.NewLoginThrottle(loginAttemptRepo)
+	lockoutHandler := handler.NewLockoutHandler(loginThrottle)
+
+	rolePolicyRepo := repository.NewRolePolicyRepo(pool)
+	roleService := service.NewRoleService(rolePolicyRepo, sessionRepo, accessTokenRepo, emailService, appBaseURL)
+	roleAdminHandler := handler.NewRoleAdminHandler(roleService)
+
+	authService := service.NewAuthService(userRepo, profileRepo, settingsRepo, sessionRepo, accessTokenRepo, emailVerificationService, mfaService, loginThrottle, roleService, passwordPolicy)
+	authService.DisablePasswordLogin(strings.Split(os.Getenv("PASSWORD_LOGIN_DISABLED_ROLES"), ","))
+	authHandler := handler.NewAuthHandler(authService, sessionCookies)
+
+	oidcProvider := oidc.NewProvider(oidc.ConfigFromEnv(), nil)
+	identityRepo := repository.NewIdentityRepo(pool)
+	ssoService := service.NewSSOService(oidcProvider, identityRepo, userRepo, authService)
+	ssoHandler := handler.NewSSOHandler(ssoService, appBaseURL+"/sso-callback", sessionCookies)
+
+	passwordResetService := service.NewPasswordResetService(userRepo, userTokenRepo, sessionRepo, accessTokenRepo, emailService, passwordPolicy, appBaseURL)
+	passwordResetHandler := handler.NewPasswordResetHandler(passwordResetService)
 
 	contentRepo := repository.NewContentRepository(pool)
 	contentService := service.NewContentService(contentRepo)
@@ -77,22 +198,24 @@ func main() {
 
 	startupService := service.NewStartupService(startupRepo)
 	startupHandler := h.NewStartupHandler(startupService)
-	submissionService := service.NewSubmissionsService(notificationService, submissionRepo, profileRepo, aiService)
-	submissionHandler := handler.NewSubmissionsHandler(submissionService)
+	submissionService := service.NewSubmissionsService(submissionRepo, submissionWorkflow, submissionRevisionService, submissionFileService, applicationCycleService, applicationFormService, aiService)
+	submissionTeamService := service.NewSubmissionTeamService(submissionTeamRepo, submissionWorkflow, emailService, appBaseURL)
+	submissionHandler := handler.NewSubmissionsHandler(submissionService, submissionTeamService)
+	aiHandler := handler.NewAIHandler(aiService, submissionService)
 	testEmailHandler := handler.NewTestEmailHandler(emailService)
 	settingService := service.NewSettingService(settingsRepo)
-	profileService := service.NewProfileService(profileRepo)
+	profileService := service.NewProfileService(profileRepo, uploadInspector, blobs)
 	profileHandler := handler.NewProfileHandler(profileService)
 	settingsHandler := handler.NewSettingsHandler(settingService, profileService, authService)
 	facultyEventService := service.NewEventInvitationService(facultyEventRepo)
 	facultyEventHandler := handler.NewEventInvitationHandler(facultyEventService)
 
 	adminFacultyRepo := repository.NewAdminFacultyRepository(pool)
-	adminFacultyService := service.NewAdminFacultyService(adminFacultyRepo)
+	adminFacultyService := service.NewAdminFacultyService(adminFacultyRepo, sessionRepo, accessTokenRepo, passwordPolicy)
 	adminFacultyHandler := handler.NewAdminFacultyHandler(adminFacultyService)
 
 	adminSubmissionRepo := repository.NewAdminSubmissionRepo(pool)
-	adminSubmissionHandler := handler.NewAdminSubmissionHandler(adminSubmissionRepo)
+	adminSubmissionHandler := handler.NewAdminSubmissionHandler(adminSubmissionRepo, submissionWorkflow, submissionSettingsService, submissionRevisionService, submissionFileService, applicationCycleService, applicationFormService)
 
 	adminWorkRepo := repository.NewAdminWorkRepo(pool)
 	adminWorkHandler := handler.NewAdminWorkHandler(adminWorkRepo)
@@ -100,9 +223,37 @@ func main() {
 	facultyIncubationHandler := handler.NewFacultyIncubationHandler(facultyProgressService, companyRepo)
 	workHandler := handler.NewWorkHandler(submissionRepo)
 
-	router := r.NewRouter(startupHandler, authHandler, profileHandler, settingsHandler, submissionHandler, feedbackHandler, queryHandler, testEmailHandler, aiHandler, contentHandler, facultyReviewHandler, facultyEventHandler, facultyProgressHandler, adminFacultyHandler, adminSubmissionHandler, workHandler, facultyIncubationHandler, adminWorkHandler)
+	permissionHandler := handler.NewPermissionHandler(permissionService)
+
+	accessTokenService := service.NewAccessTokenService(accessTokenRepo, permissionService)
+	accessTokenHandler := handler.NewAccessTokenHandler(accessTokenService)
+
+	impersonationRepo := repository.NewImpersonationRepo(pool)
+	impersonationService := service.NewImpersonationService(impersonationRepo, userRepo)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationService)
+
+	sessionService := service.NewSessionService(sessionRepo)
+	sessionHandler := handler.NewSessionHandler(sessionService, sessionCookies)
+
+	authMiddleware := middleware.NewAuth(sessionRepo, mfaRepo, permissionService, accessTokenService, impersonationService)
+
+	router := r.NewRouter(startupHandler, authHandler, profileHandler, settingsHandler, submissionHandler, feedbackHandler, queryHandler, testEmailHandler, aiHandler, contentHandler, facultyReviewHandler, facultyEventHandler, facultyProgressHandler, adminFacultyHandler, adminSubmissionHandler, workHandler, facultyIncubationHandler, adminWorkHandler, passwordResetHandler, emailVerificationHandler, mfaHandler, lockoutHandler, ssoHandler, roleAdminHandler, permissionHandler, accessTokenHandler, impersonationHandler, sessionHandler, authMiddleware)
 
 	log.Println("Server running on :8080")
 	http.ListenAndServe(":8080", router)
 
 }
+
+// reloadKeysOnSignal re-reads the JWT key ring whenever the process receives
+// SIGHUP, so `jwtkeys rotate` takes effect without a restart.
+func reloadKeysOnSignal() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := auth.ReloadKeys(); err != nil {
+			log.Println("JWT key reload failed:", err)
+			continue
+		}
+		log.Println("JWT signing keys reloaded")
+	}
+}
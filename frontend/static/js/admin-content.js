@@ -392,9 +392,22 @@ let ideasFilter = 'all';
 async function loadIdeas() {
   try {
     // Fetch ALL ideas (not just pending)
-    const res = await fetch('/api/admin/submissions/all', { headers });
-    if (!res.ok) throw new Error('Failed to fetch ideas');
-    ideasCache = await res.json() || [];
+    const cycleId = document.getElementById('ideas-cycle-filter')?.value;
+    const search = document.getElementById('ideas-search')?.value.trim();
+    if (search) {
+      // Ranked full-text search over ideas and their attached files
+      const params = new URLSearchParams({ q: search, limit: '100' });
+      if (cycleId) params.set('cycle_id', cycleId);
+      const res = await fetch(`/api/admin/submissions/search?${params}`, { headers });
+      if (!res.ok) throw new Error('Failed to search ideas');
+      const data = await res.json();
+      ideasCache = data.results || [];
+    } else {
+      const query = cycleId ? `?cycle_id=${encodeURIComponent(cycleId)}` : '';
+      const res = await fetch(`/api/admin/submissions/all${query}`, { headers });
+      if (!res.ok) throw new Error('Failed to fetch ideas');
+      ideasCache = await res.json() || [];
+    }
 
     // Also load faculty for assignment
     await loadAllFacultyForAssignment();
@@ -457,11 +470,12 @@ function renderIdeas() {
     return `
       <div class="bg-white p-4 rounded shadow">
         <div class="flex justify-between items-start mb-2">
-          <h3 class="font-bold text-lg">${escapeHtml(i.title)}</h3>
+          <h3 class="font-bold text-lg">${i.title_html || escapeHtml(i.title)}</h3>
           <span class="text-xs px-2 py-1 rounded ${statusColor}">${statusLabel}</span>
         </div>
-        <p class="text-sm text-gray-700 mt-1">${escapeHtml(i.description || 'No description')}</p>
+        <p class="text-sm text-gray-700 mt-1">${i.snippet !== undefined ? i.snippet : escapeHtml(i.description || 'No description')}</p>
         <p class="text-sm text-gray-600 mt-2">Submitted by: <span class="font-medium">${escapeHtml(i.student || 'Unknown')}</span></p>
+        ${(i.team || []).length > 1 ? `<p class="text-xs text-gray-500">Team: ${i.team.map(m => `${escapeHtml(m.name || m.email)} (${escapeHtml(m.role)})`).join(', ')}</p>` : ''}
         <p class="text-xs text-gray-400">Submitted: ${new Date(i.submitted_on).toLocaleDateString()}</p>
         ${i.file_path ? `<p class="text-xs text-blue-600 mt-1"><a href="${escapeHtml(i.file_path)}" target="_blank">📄 View Attached File</a></p>` : ''}
         
@@ -505,8 +519,29 @@ function setupIdeasFilterTabs() {
   });
 }
 
+// Fill the cycle filter and reload ideas when it or the search box changes
+async function setupIdeasCycleFilter() {
+  const select = document.getElementById('ideas-cycle-filter');
+  if (!select) return;
+  select.addEventListener('change', loadIdeas);
+  let searchTimer;
+  document.getElementById('ideas-search')?.addEventListener('input', () => {
+    clearTimeout(searchTimer);
+    searchTimer = setTimeout(loadIdeas, 300);
+  });
+  try {
+    const res = await fetch('/api/admin/cycles', { headers });
+    if (!res.ok) return;
+    const cycles = await res.json() || [];
+    cycles.forEach(c => select.add(new Option(c.name, c.id)));
+  } catch (err) {
+    console.error('Error loading cycles:', err);
+  }
+}
+
 // Initialize filter tabs after DOM load
 document.addEventListener('DOMContentLoaded', setupIdeasFilterTabs);
+document.addEventListener('DOMContentLoaded', setupIdeasCycleFilter);
 
 window.decide = async (id, decision) => {
   try {
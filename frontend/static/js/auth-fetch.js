@@ -0,0 +1,53 @@
+// In cookie mode the session lives in HttpOnly cookies and localStorage only
+// holds a placeholder. Page scripts still send "Authorization: Bearer ...";
+// this drops that header, sends the cookies and adds the CSRF token the API
+// expects on state-changing requests.
+(function () {
+  if (localStorage.getItem("authMode") !== "cookie") return;
+
+  const SAFE = ["GET", "HEAD", "OPTIONS"];
+  const nativeFetch = window.fetch.bind(window);
+
+  function csrfToken() {
+    const m = document.cookie.match(/(?:^|;\s*)mic_csrf=([^;]*)/);
+    return m ? decodeURIComponent(m[1]) : "";
+  }
+
+  let refreshing = null;
+  function refresh() {
+    if (!refreshing) {
+      refreshing = nativeFetch("/api/token/refresh", {
+        method: "POST",
+        credentials: "same-origin",
+        headers: { "X-CSRF-Token": csrfToken() },
+      }).then((res) => res.ok).finally(() => { refreshing = null; });
+    }
+    return refreshing;
+  }
+
+  window.fetch = async function (input, init) {
+    init = Object.assign({}, init);
+    const url = typeof input === "string" ? input : input.url;
+    if (!url.startsWith("/") || url.startsWith("//")) {
+      return nativeFetch(input, init);
+    }
+
+    const headers = new Headers(init.headers || (input instanceof Request ? input.headers : undefined));
+    headers.delete("Authorization");
+    const method = (init.method || (input instanceof Request ? input.method : "GET")).toUpperCase();
+    init.headers = headers;
+    init.credentials = "same-origin";
+
+    const send = () => {
+      if (!SAFE.includes(method)) headers.set("X-CSRF-Token", csrfToken());
+      return nativeFetch(input, init);
+    };
+
+    // The access cookie is short-lived; refresh once and retry on 401.
+    const res = await send();
+    if (res.status !== 401 || url.startsWith("/api/token/refresh") || init.body instanceof ReadableStream) {
+      return res;
+    }
+    return (await refresh()) ? send() : res;
+  };
+})();
@@ -47,29 +47,48 @@ document.addEventListener('DOMContentLoaded', async function () {
     }
   }
 
+  // Lists the student's answers to the cycle's form in the form's order.
+  // Fields without an answer were left empty or hidden by a condition.
+  function renderAnswers(idea) {
+    const fields = (idea.form || []).filter(f => idea.answers && f.key in idea.answers);
+    const container = document.getElementById('ideaAnswers');
+    if (!fields.length) {
+      container.classList.add('hidden');
+      return;
+    }
+    document.getElementById('ideaFormVersion').textContent = idea.form_version ? `v${idea.form_version}` : '';
+    const list = document.getElementById('ideaAnswersList');
+    list.innerHTML = '';
+    fields.forEach(f => {
+      const value = idea.answers[f.key];
+      const dt = document.createElement('dt');
+      dt.className = 'text-xs text-gray-500';
+      dt.textContent = f.label;
+      const dd = document.createElement('dd');
+      dd.className = 'text-gray-800';
+      dd.textContent = Array.isArray(value) ? value.join(', ')
+        : typeof value === 'boolean' ? (value ? 'Yes' : 'No')
+        : String(value);
+      const item = document.createElement('div');
+      item.append(dt, dd);
+      list.appendChild(item);
+    });
+    container.classList.remove('hidden');
+  }
+
   function renderIdea(idea) {
     titleEl.textContent = idea.title;
-    studentEl.textContent = `Submitted by ${idea.student}`;
+    const cofounders = (idea.team || []).filter(m => m.role !== 'owner');
+    studentEl.textContent = `Submitted by ${idea.student}` +
+      (cofounders.length ? ` with ${cofounders.map(m => m.name || m.email).join(', ')}` : '');
     studentEmailEl.textContent = idea.email;
     descriptionEl.textContent = idea.description || 'No description provided.';
+    renderAnswers(idea);
 
     submittedOnEl.textContent =
       `Submitted on ${new Date(idea.submitted_on).toLocaleDateString()}`;
 
-    if (idea.file_path) {
-      // Extract filename from path (e.g., ./uploads/uuid_filename.ext -> filename.ext)
-      const parts = idea.file_path.split('_');
-      const filename = parts.length > 1 ? parts.slice(1).join('_') : idea.file_path.split('/').pop();
-      attachmentEl.textContent = filename;
-
-      viewSubmissionBtn.onclick = () => {
-        window.open(`/api/submissions/${idea.id}/file`, '_blank');
-      };
-      viewSubmissionBtn.classList.remove('hidden');
-    } else {
-      attachmentEl.textContent = 'No attachment';
-      viewSubmissionBtn.classList.add('hidden');
-    }
+    loadFiles(idea.id);
 
     domainBadge.innerHTML = `
       <i class="fas fa-tag text-[10px]"></i>
@@ -135,6 +154,91 @@ document.addEventListener('DOMContentLoaded', async function () {
     }
 
     ideaContainer.classList.remove('hidden');
+    loadChanges(idea.id);
+  }
+
+  // ---- Attachments ----
+  async function loadFiles(id) {
+    attachmentEl.textContent = 'No attachment';
+    viewSubmissionBtn.classList.add('hidden');
+    try {
+      const res = await fetch(`/api/faculty/reviews/${id}/files`, {
+        headers: { Authorization: 'Bearer ' + token }
+      });
+      if (!res.ok) return;
+      const files = await res.json();
+      if (files.length === 0) return;
+
+      attachmentEl.innerHTML = '';
+      files.forEach((file, i) => {
+        const link = document.createElement('button');
+        link.className = 'text-orange-primary hover:underline';
+        link.textContent = file.file_name;
+        link.onclick = () => downloadFile(id, file);
+        if (i > 0) attachmentEl.append(', ');
+        attachmentEl.appendChild(link);
+      });
+    } catch (e) {
+      console.error('Failed to load files:', e);
+    }
+  }
+
+  async function downloadFile(id, file) {
+    const res = await fetch(`/api/faculty/reviews/${id}/files/${file.id}`, {
+      headers: { Authorization: 'Bearer ' + token }
+    });
+    if (!res.ok) {
+      alert('Download failed');
+      return;
+    }
+    const url = URL.createObjectURL(await res.blob());
+    const a = document.createElement('a');
+    a.href = url;
+    a.download = file.file_name;
+    a.click();
+    URL.revokeObjectURL(url);
+  }
+
+  // ---- Revision Diff ----
+  // Compares the two most recent submitted revisions, i.e. what the student
+  // changed since reviewers last saw the idea.
+  async function loadChanges(id) {
+    try {
+      const res = await fetch(`/api/faculty/reviews/${id}/revisions`, {
+        headers: { Authorization: 'Bearer ' + token }
+      });
+      if (!res.ok) return;
+      const submitted = (await res.json()).filter(rev => rev.kind === 'submit');
+      if (submitted.length < 2) return;
+
+      const to = submitted[0].number;
+      const from = submitted[1].number;
+      const diffRes = await fetch(`/api/faculty/reviews/${id}/revisions/diff?from=${from}&to=${to}`, {
+        headers: { Authorization: 'Bearer ' + token }
+      });
+      if (!diffRes.ok) return;
+      const diff = await diffRes.json();
+
+      document.getElementById('ideaChangesLabel').textContent =
+        `Changes from revision ${from} to revision ${to}`;
+      renderDiff(document.getElementById('ideaChangesTitle'), diff.title);
+      renderDiff(document.getElementById('ideaChangesDescription'), diff.description);
+      document.getElementById('ideaChangesFile').classList.toggle('hidden', !diff.file_changed);
+      document.getElementById('ideaChanges').classList.remove('hidden');
+    } catch (e) {
+      console.error('Failed to load revision diff:', e);
+    }
+  }
+
+  function renderDiff(el, segments) {
+    el.innerHTML = '';
+    (segments || []).forEach(seg => {
+      const span = document.createElement(seg.op === 'insert' ? 'ins' : seg.op === 'delete' ? 'del' : 'span');
+      if (seg.op === 'insert') span.className = 'bg-green-100 text-green-800 no-underline';
+      if (seg.op === 'delete') span.className = 'bg-red-100 text-red-700';
+      span.textContent = seg.text;
+      el.appendChild(span);
+    });
   }
 
   // ---- Feedback Logic ----
@@ -9,6 +9,8 @@ require (
 	golang.org/x/crypto v0.37.0
 )
 
+require golang.org/x/sys v0.32.0 // indirect
+
 require github.com/joho/godotenv v1.5.1 // direct
 
 require (
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+var ErrNoSigningKeys = errors.New("no JWT signing keys configured: set JWT_KEYS_FILE or JWT_SECRET")
+
+// Config controls how tokens are signed and which claims are required when
+// they are verified.
+type Config struct {
+	// KeysFile points at the JSON key ring written by cmd/jwtkeys.
+	KeysFile string
+	// Secret is a single static key used when no key ring file is configured.
+	Secret string
+
+	Issuer     string
+	Audience   string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// KeyGrace is how long a retired key keeps verifying tokens after a
+	// rotation. It should be at least as long as AccessTTL.
+	KeyGrace time.Duration
+}
+
+// ConfigFromEnv reads the token configuration from the environment.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		KeysFile:   os.Getenv("JWT_KEYS_FILE"),
+		Secret:     os.Getenv("JWT_SECRET"),
+		Issuer:     os.Getenv("JWT_ISSUER"),
+		Audience:   os.Getenv("JWT_AUDIENCE"),
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 30 * 24 * time.Hour,
+		KeyGrace:   48 * time.Hour,
+	}
+
+	if cfg.Issuer == "" {
+		cfg.Issuer = "mic-website"
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = "mic-website-api"
+	}
+
+	if v := os.Getenv("JWT_ACCESS_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, errors.New("invalid JWT_ACCESS_TTL: " + err.Error())
+		}
+		cfg.AccessTTL = d
+	}
+	if v := os.Getenv("JWT_REFRESH_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, errors.New("invalid JWT_REFRESH_TTL: " + err.Error())
+		}
+		cfg.RefreshTTL = d
+	}
+	if v := os.Getenv("JWT_KEY_GRACE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, errors.New("invalid JWT_KEY_GRACE: " + err.Error())
+		}
+		cfg.KeyGrace = d
+	}
+
+	if cfg.KeysFile == "" && cfg.Secret == "" {
+		return cfg, ErrNoSigningKeys
+	}
+
+	return cfg, nil
+}
@@ -1,18 +1,116 @@
 package auth
 
 import (
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+var (
+	mu      sync.RWMutex
+	config  Config
+	keyRing *KeyRing
+)
+
+// Init loads the signing keys described by cfg. It must be called before any
+// token is created or parsed.
+func Init(cfg Config) error {
+	kr, err := loadKeys(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := kr.Active(); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	config = cfg
+	keyRing = kr
+	mu.Unlock()
+	return nil
+}
+
+// ReloadKeys re-reads the key ring file so a rotation takes effect without a
+// restart.
+func ReloadKeys() error {
+	mu.RLock()
+	cfg := config
+	mu.RUnlock()
+
+	return Init(cfg)
+}
+
+func loadKeys(cfg Config) (*KeyRing, error) {
+	if cfg.KeysFile != "" {
+		return LoadKeyRing(cfg.KeysFile)
+	}
+	if cfg.Secret != "" {
+		return staticKeyRing(cfg.Secret), nil
+	}
+	return nil, ErrNoSigningKeys
+}
+
+// PurposeMFAChallenge marks the short-lived token handed out between the
+// password step and the TOTP step of a login. It is not an access token.
+const PurposeMFAChallenge = "mfa_challenge"
+
+const mfaChallengeTTL = 5 * time.Minute
+
 type Claims struct {
 	UserID string `json:"user_id"`
-	Role   string `json:"role"`
-	Email  string `json:"email"`
+	// Role is the role the session is acting as. Roles is every role the
+	// user holds; access checks use Roles.
+	Role      string   `json:"role"`
+	Roles     []string `json:"roles,omitempty"`
+	Email     string   `json:"email"`
+	SessionID string   `json:"sid"`
+	// MFA records that the session completed a second factor.
+	MFA bool `json:"mfa,omitempty"`
+	// Purpose is empty for access tokens.
+	Purpose string `json:"purpose,omitempty"`
+	// ImpersonatorID is the admin viewing the site as UserID, and
+	// ImpersonationID the audit record for it. ReadOnly blocks writes.
+	ImpersonatorID  string `json:"imp_by,omitempty"`
+	ImpersonationID string `json:"imp_id,omitempty"`
+	ReadOnly        bool   `json:"ro,omitempty"`
+	// TokenID and Scopes are set when the request used a personal access
+	// token instead of a JWT. Scopes limits it to those permissions.
+	TokenID string   `json:"-"`
+	Scopes  []string `json:"-"`
 	jwt.RegisteredClaims
 }
 
+// RoleSet returns every role the user holds. Tokens issued before roles
+// were added only carry Role.
+func (c *Claims) RoleSet() []string {
+	if len(c.Roles) == 0 {
+		return []string{c.Role}
+	}
+	return c.Roles
+}
+
+// HasRole reports whether the user holds role.
+func (c *Claims) HasRole(role string) bool {
+	for _, r := range c.RoleSet() {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPersonalAccessToken reports whether the request used a personal access
+// token.
+func (c *Claims) IsPersonalAccessToken() bool {
+	return c.TokenID != ""
+}
+
+// IsImpersonating reports whether an admin is acting as this user.
+func (c *Claims) IsImpersonating() bool {
+	return c.ImpersonationID != ""
+}
+
 func (c *Claims) Deadline() (deadline time.Time, ok bool) {
 	panic("unimplemented")
 }
@@ -29,31 +127,139 @@ func (c *Claims) Value(key any) any {
 	panic("unimplemented")
 }
 
-func CreateToken(userID string, role string, email string) (string, error) {
-	claims := Claims{
-		userID,
-		role,
-		email,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   userID,
-		},
+// AccessTTL returns the configured lifetime of access tokens.
+func AccessTTL() time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config.AccessTTL
+}
+
+// RefreshTTL returns the configured lifetime of refresh tokens.
+func RefreshTTL() time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config.RefreshTTL
+}
+
+// CreateToken issues an access token acting as role, one of roles.
+func CreateToken(userID string, role string, roles []string, email string, sessionID string, mfa bool) (string, error) {
+	mu.RLock()
+	ttl := config.AccessTTL
+	mu.RUnlock()
+
+	return sign(Claims{
+		UserID:    userID,
+		Role:      role,
+		Roles:     roles,
+		Email:     email,
+		SessionID: sessionID,
+		MFA:       mfa,
+	}, ttl)
+}
+
+// CreateImpersonationToken issues a short-lived access token for userID
+// that records the admin behind it. It is tied to the admin's session.
+func CreateImpersonationToken(userID string, role string, roles []string, email string, sessionID string, mfa bool, adminID string, impersonationID string, readOnly bool, ttl time.Duration) (string, error) {
+	return sign(Claims{
+		UserID:          userID,
+		Role:            role,
+		Roles:           roles,
+		Email:           email,
+		SessionID:       sessionID,
+		MFA:             mfa,
+		ImpersonatorID:  adminID,
+		ImpersonationID: impersonationID,
+		ReadOnly:        readOnly,
+	}, ttl)
+}
+
+// CreateMFAChallengeToken issues the token a client exchanges, together with
+// a TOTP or recovery code, for a real session.
+func CreateMFAChallengeToken(userID string) (string, error) {
+	return sign(Claims{
+		UserID:  userID,
+		Purpose: PurposeMFAChallenge,
+	}, mfaChallengeTTL)
+}
+
+// ParseMFAChallengeToken validates a challenge token and returns the user it
+// was issued to.
+func ParseMFAChallengeToken(token string) (string, error) {
+	c, err := ParseJWT(token, &Claims{})
+	if err != nil {
+		return "", err
+	}
+	if c.Purpose != PurposeMFAChallenge {
+		return "", jwt.ErrTokenInvalidClaims
+	}
+	return c.UserID, nil
+}
+
+func sign(claims Claims, ttl time.Duration) (string, error) {
+	mu.RLock()
+	cfg, kr := config, keyRing
+	mu.RUnlock()
+	if kr == nil {
+		return "", ErrNoSigningKeys
+	}
+
+	key, err := kr.Active()
+	if err != nil {
+		return "", err
+	}
+	secret, err := key.secretBytes()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    cfg.Issuer,
+		Audience:  jwt.ClaimStrings{cfg.Audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		Subject:   claims.UserID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte("jwt-secret-key"))
+	token.Header["kid"] = key.ID
+	return token.SignedString(secret)
 }
 
 func KeyFunction(token *jwt.Token) (interface{}, error) {
 	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 		return nil, jwt.ErrTokenSignatureInvalid
 	}
-	return []byte("jwt-secret-key"), nil
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrUnknownKey
+	}
+
+	mu.RLock()
+	cfg, kr := config, keyRing
+	mu.RUnlock()
+	if kr == nil {
+		return nil, ErrNoSigningKeys
+	}
+
+	key, err := kr.Lookup(kid, time.Now(), cfg.KeyGrace)
+	if err != nil {
+		return nil, err
+	}
+	return key.secretBytes()
 }
 
 func ParseJWT(token string, c *Claims) (*Claims, error) {
-	parse, err := jwt.ParseWithClaims(token, c, KeyFunction)
+	mu.RLock()
+	cfg := config
+	mu.RUnlock()
+
+	parse, err := jwt.ParseWithClaims(token, c, KeyFunction,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+	)
 
 	if err != nil {
 		return nil, err
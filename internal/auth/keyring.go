@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+var (
+	ErrNoActiveKey = errors.New("key ring has no active signing key")
+	ErrUnknownKey  = errors.New("token signed with unknown or expired key")
+)
+
+// SigningKey is a single HMAC secret identified by the kid header of the
+// tokens it signs.
+type SigningKey struct {
+	ID        string     `json:"kid"`
+	Secret    string     `json:"secret"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
+
+func (k SigningKey) secretBytes() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(k.Secret)
+}
+
+// KeyRing holds the active signing key plus any retired keys that are still
+// accepted for verification.
+type KeyRing struct {
+	Keys []SigningKey `json:"keys"`
+}
+
+// LoadKeyRing reads a key ring from a JSON file.
+func LoadKeyRing(path string) (*KeyRing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kr KeyRing
+	if err := json.Unmarshal(data, &kr); err != nil {
+		return nil, err
+	}
+	return &kr, nil
+}
+
+// Save writes the key ring to path, readable only by the owner.
+func (kr *KeyRing) Save(path string) error {
+	data, err := json.MarshalIndent(kr, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Active returns the newest key that has not been retired.
+func (kr *KeyRing) Active() (*SigningKey, error) {
+	var active *SigningKey
+	for i := range kr.Keys {
+		k := &kr.Keys[i]
+		if k.RetiredAt != nil {
+			continue
+		}
+		if active == nil || k.CreatedAt.After(active.CreatedAt) {
+			active = k
+		}
+	}
+	if active == nil {
+		return nil, ErrNoActiveKey
+	}
+	return active, nil
+}
+
+// Lookup returns the key with the given id if it may still verify tokens at
+// time now.
+func (kr *KeyRing) Lookup(kid string, now time.Time, grace time.Duration) (*SigningKey, error) {
+	for i := range kr.Keys {
+		k := &kr.Keys[i]
+		if k.ID != kid {
+			continue
+		}
+		if k.RetiredAt != nil && now.After(k.RetiredAt.Add(grace)) {
+			return nil, ErrUnknownKey
+		}
+		return k, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// Rotate retires the current active key, adds a freshly generated one and
+// drops keys whose grace window has already passed.
+func (kr *KeyRing) Rotate(now time.Time, grace time.Duration) (*SigningKey, error) {
+	key, err := NewSigningKey(now)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := kr.Keys[:0]
+	for _, k := range kr.Keys {
+		if k.RetiredAt == nil {
+			retired := now
+			k.RetiredAt = &retired
+		}
+		if now.After(k.RetiredAt.Add(grace)) {
+			continue
+		}
+		kept = append(kept, k)
+	}
+	kr.Keys = append(kept, *key)
+
+	return key, nil
+}
+
+// NewSigningKey generates a random 256-bit HMAC key with a random kid.
+func NewSigningKey(now time.Time) (*SigningKey, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		ID:        hex.EncodeToString(id),
+		Secret:    base64.StdEncoding.EncodeToString(secret),
+		CreatedAt: now.UTC(),
+	}, nil
+}
+
+// staticKeyRing wraps a single configured secret so deployments without a
+// key ring file keep working.
+func staticKeyRing(secret string) *KeyRing {
+	return &KeyRing{
+		Keys: []SigningKey{{
+			ID:     "static",
+			Secret: base64.StdEncoding.EncodeToString([]byte(secret)),
+		}},
+	}
+}
@@ -1,19 +1,110 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Argon2id parameters for new hashes. They are written into every hash, so
+// raising them later only makes NeedsRehash report older hashes.
+const (
+	argon2Memory  = 19 * 1024 // KiB
+	argon2Time    = 2
+	argon2Threads = 1
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+// HashPassword returns an Argon2id hash in the PHC string format:
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
 func HashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
-	return string(hash), nil
+
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
 }
 
+// CompareHashedPassword checks password against an Argon2id hash or a bcrypt
+// hash from before the switch.
 func CompareHashedPassword(hashPassword, password string) bool {
+	if strings.HasPrefix(hashPassword, "$argon2id$") {
+		p, salt, key, err := decodeArgon2id(hashPassword)
+		if err != nil {
+			return false
+		}
+		got := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(got, key) == 1
+	}
+
 	err := bcrypt.CompareHashAndPassword(
 		[]byte(hashPassword),
 		[]byte(password),
 	)
 	return err == nil
 }
+
+// NeedsRehash reports whether a stored hash should be replaced with a fresh
+// HashPassword result the next time the plain password is known.
+func NeedsRehash(hashPassword string) bool {
+	if !strings.HasPrefix(hashPassword, "$argon2id$") {
+		return true
+	}
+	p, _, _, err := decodeArgon2id(hashPassword)
+	if err != nil {
+		return true
+	}
+	return p.version != argon2.Version || p.memory != argon2Memory || p.time != argon2Time || p.threads != argon2Threads
+}
+
+type argon2Params struct {
+	version int
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+func decodeArgon2id(encoded string) (argon2Params, []byte, []byte, error) {
+	var p argon2Params
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &p.version); err != nil {
+		return p, nil, nil, err
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, nil, nil, err
+	}
+
+	if p.time == 0 || p.threads == 0 {
+		return p, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	if len(key) == 0 {
+		return p, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+	return p, salt, key, nil
+}
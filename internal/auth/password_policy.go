@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// maxPasswordLength keeps hashing cost bounded.
+const maxPasswordLength = 128
+
+var sha1Line = regexp.MustCompile(`^[0-9A-Fa-f]{40}(:\d+)?$`)
+
+// PasswordViolation is one rule a password breaks. Code is stable for
+// clients; Message is for people.
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every rule a rejected password breaks.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return "password does not meet the policy: " + strings.Join(msgs, "; ")
+}
+
+// PasswordPolicy decides whether a new password is acceptable.
+type PasswordPolicy struct {
+	MinLength int
+
+	// blocked holds lowercased passwords, blockedSHA1 upper-case hex SHA-1
+	// digests as published in breach corpora.
+	blocked     map[string]struct{}
+	blockedSHA1 map[string]struct{}
+}
+
+// NewPasswordPolicy builds a policy from the built-in common password list
+// plus, when blocklistFile is set, a file with one password or SHA-1 digest
+// (optionally followed by ":count") per line.
+func NewPasswordPolicy(minLength int, blocklistFile string) (*PasswordPolicy, error) {
+	p := &PasswordPolicy{
+		MinLength:   minLength,
+		blocked:     make(map[string]struct{}),
+		blockedSHA1: make(map[string]struct{}),
+	}
+
+	for _, line := range strings.Split(commonPasswords, "\n") {
+		p.addBlocked(line)
+	}
+
+	if blocklistFile != "" {
+		f, err := os.Open(blocklistFile)
+		if err != nil {
+			return nil, fmt.Errorf("password blocklist: %w", err)
+		}
+		defer f.Close()
+
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			p.addBlocked(sc.Text())
+		}
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("password blocklist: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+func (p *PasswordPolicy) addBlocked(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+	if sha1Line.MatchString(line) {
+		p.blockedSHA1[strings.ToUpper(line[:40])] = struct{}{}
+		return
+	}
+	p.blocked[strings.ToLower(line)] = struct{}{}
+}
+
+// Validate checks password for an account with the given email and name and
+// returns a *PasswordPolicyError listing every rule it breaks.
+func (p *PasswordPolicy) Validate(password, email, name string) error {
+	var violations []PasswordViolation
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, PasswordViolation{
+			Code:    "TOO_SHORT",
+			Message: fmt.Sprintf("must be at least %d characters", p.MinLength),
+		})
+	}
+	if length > maxPasswordLength {
+		violations = append(violations, PasswordViolation{
+			Code:    "TOO_LONG",
+			Message: fmt.Sprintf("must be at most %d characters", maxPasswordLength),
+		})
+	}
+
+	if p.isBlocked(password) {
+		violations = append(violations, PasswordViolation{
+			Code:    "COMMON_PASSWORD",
+			Message: "is too common or has appeared in a data breach",
+		})
+	}
+
+	lower := strings.ToLower(password)
+	local, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	if len(local) >= 3 && strings.Contains(lower, local) {
+		violations = append(violations, PasswordViolation{
+			Code:    "CONTAINS_EMAIL",
+			Message: "must not contain your email address",
+		})
+	}
+	for _, part := range strings.Fields(strings.ToLower(name)) {
+		if utf8.RuneCountInString(part) >= 3 && strings.Contains(lower, part) {
+			violations = append(violations, PasswordViolation{
+				Code:    "CONTAINS_NAME",
+				Message: "must not contain your name",
+			})
+			break
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func (p *PasswordPolicy) isBlocked(password string) bool {
+	if _, ok := p.blocked[strings.ToLower(password)]; ok {
+		return true
+	}
+	if len(p.blockedSHA1) == 0 {
+		return false
+	}
+	sum := sha1.Sum([]byte(password))
+	_, ok := p.blockedSHA1[strings.ToUpper(hex.EncodeToString(sum[:]))]
+	return ok
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+// AccessTokenPrefix starts every personal access token, which is how they
+// are told apart from JWTs in the Authorization header.
+const AccessTokenPrefix = "mic_pat_"
+
+var ErrInvalidAccessToken = errors.New("invalid or revoked access token")
+
+// NewOpaqueToken returns a random URL-safe token for refresh tokens and other
+// one-time secrets. Only its hash should ever be stored.
+func NewOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex SHA-256 of an opaque token, which is what gets
+// persisted and looked up.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+type AccessTokenHandler struct {
+	service *service.AccessTokenService
+}
+
+func NewAccessTokenHandler(s *service.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{service: s}
+}
+
+type CreateAccessTokenResponse struct {
+	model.PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// List returns the current user's active tokens, without their values
+func (h *AccessTokenHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tokens, err := h.service.List(r.Context(), user.UserID)
+	if err != nil {
+		http.Error(w, "failed to load access tokens", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, tokens)
+}
+
+// Create issues a token. Its value is in the response and cannot be shown
+// again.
+func (h *AccessTokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req service.CreateAccessTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	t, token, err := h.service.Create(r.Context(), user.UserID, user.RoleSet(), user.MFA, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreateAccessTokenResponse{PersonalAccessToken: *t, Token: token})
+}
+
+func (h *AccessTokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.service.Revoke(r.Context(), chi.URLParam(r, "id"), user.UserID)
+	if errors.Is(err, repository.ErrAccessTokenNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to revoke access token", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
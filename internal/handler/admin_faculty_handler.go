@@ -47,6 +47,9 @@ func (h *AdminFacultyHandler) CreateFaculty(w http.ResponseWriter, r *http.Reque
 	}
 
 	err := h.service.CreateFaculty(r.Context(), req.Name, req.Email, req.Password)
+	if writePasswordPolicyError(w, err) {
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -75,6 +78,9 @@ func (h *AdminFacultyHandler) UpdateFaculty(w http.ResponseWriter, r *http.Reque
 	}
 
 	err := h.service.UpdateFaculty(r.Context(), id, req.Name, req.Email, req.Password)
+	if writePasswordPolicyError(w, err) {
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
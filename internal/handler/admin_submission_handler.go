@@ -2,25 +2,65 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
 	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
 )
 
 type AdminSubmissionHandler struct {
-	repo *repository.AdminSubmissionRepo
+	repo      *repository.AdminSubmissionRepo
+	workflow  *service.SubmissionWorkflow
+	settings  *service.SubmissionSettingsService
+	revisions *service.SubmissionRevisionService
+	files     *service.SubmissionFileService
+	cycles    *service.ApplicationCycleService
+	forms     *service.ApplicationFormService
 }
 
-func NewAdminSubmissionHandler(repo *repository.AdminSubmissionRepo) *AdminSubmissionHandler {
-	return &AdminSubmissionHandler{repo: repo}
+func NewAdminSubmissionHandler(
+	repo *repository.AdminSubmissionRepo,
+	workflow *service.SubmissionWorkflow,
+	settings *service.SubmissionSettingsService,
+	revisions *service.SubmissionRevisionService,
+	files *service.SubmissionFileService,
+	cycles *service.ApplicationCycleService,
+	forms *service.ApplicationFormService,
+) *AdminSubmissionHandler {
+	return &AdminSubmissionHandler{repo: repo, workflow: workflow, settings: settings, revisions: revisions, files: files, cycles: cycles, forms: forms}
 }
 
-// GetPendingSubmissions returns all submissions awaiting admin review
+// cycleFilter reads the optional ?cycle_id= filter.
+func cycleFilter(r *http.Request) (*string, bool) {
+	id := r.URL.Query().Get("cycle_id")
+	if id == "" {
+		return nil, true
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, false
+	}
+	return &id, true
+}
+
+type SubmissionSettingsRequest struct {
+	MaxRevisionRounds int `json:"max_revision_rounds"`
+}
+
+// GetPendingSubmissions returns all submissions awaiting admin review,
+// optionally only those of ?cycle_id=
 func (h *AdminSubmissionHandler) GetPendingSubmissions(w http.ResponseWriter, r *http.Request) {
-	submissions, err := h.repo.GetPendingSubmissions(r.Context())
+	cycleID, ok := cycleFilter(r)
+	if !ok {
+		http.Error(w, "invalid cycle_id", http.StatusBadRequest)
+		return
+	}
+	submissions, err := h.repo.GetPendingSubmissions(r.Context(), cycleID)
 	if err != nil {
 		log.Println("[ADMIN] GetPendingSubmissions failed:", err)
 		http.Error(w, "failed to fetch submissions", http.StatusInternalServerError)
@@ -46,20 +86,26 @@ func (h *AdminSubmissionHandler) DecideSubmission(w http.ResponseWriter, r *http
 		return
 	}
 
-	var err error
+	claims, err := middleware.GetUser(r)
+	if err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var to string
 	switch req.Decision {
 	case "approved":
-		err = h.repo.ApproveForFaculty(r.Context(), submissionID)
+		to = model.SubmissionAdminApproved
 	case "rejected":
-		err = h.repo.RejectSubmission(r.Context(), submissionID, req.Reason)
+		to = model.SubmissionAdminRejected
 	default:
 		http.Error(w, "invalid decision: must be 'approved' or 'rejected'", http.StatusBadRequest)
 		return
 	}
 
-	if err != nil {
+	if _, err := h.workflow.Transition(r.Context(), submissionID, to, claims, req.Reason); err != nil {
 		log.Println("[ADMIN] DecideSubmission failed:", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeWorkflowError(w, err)
 		return
 	}
 
@@ -67,9 +113,25 @@ func (h *AdminSubmissionHandler) DecideSubmission(w http.ResponseWriter, r *http
 	w.Write([]byte(`{"success": true}`))
 }
 
-// GetAllSubmissions returns ALL submissions (not just pending) for admin view
+// Timeline returns the full status history of a submission
+func (h *AdminSubmissionHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	history, err := h.workflow.FullTimeline(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+	writeJSON(w, history)
+}
+
+// GetAllSubmissions returns ALL submissions (not just pending) for admin view,
+// optionally only those of ?cycle_id=
 func (h *AdminSubmissionHandler) GetAllSubmissions(w http.ResponseWriter, r *http.Request) {
-	submissions, err := h.repo.GetAllSubmissions(r.Context())
+	cycleID, ok := cycleFilter(r)
+	if !ok {
+		http.Error(w, "invalid cycle_id", http.StatusBadRequest)
+		return
+	}
+	submissions, err := h.repo.GetAllSubmissions(r.Context(), cycleID)
 	if err != nil {
 		log.Println("[ADMIN] GetAllSubmissions failed:", err)
 		http.Error(w, "failed to fetch submissions", http.StatusInternalServerError)
@@ -167,3 +229,281 @@ func (h *AdminSubmissionHandler) UpdateTags(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"success": true}`))
 }
+
+// Revisions lists the saved versions of a submission
+func (h *AdminSubmissionHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	revisions, err := h.revisions.List(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	writeJSON(w, revisions)
+}
+
+func (h *AdminSubmissionHandler) Revision(w http.ResponseWriter, r *http.Request) {
+	number, ok := revisionNumber(r)
+	if !ok {
+		http.Error(w, "invalid revision number", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := h.revisions.Get(r.Context(), chi.URLParam(r, "id"), number)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	writeJSON(w, revision)
+}
+
+// RevisionDiff compares two revisions given as ?from=&to=
+func (h *AdminSubmissionHandler) RevisionDiff(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := revisionRange(r)
+	if !ok {
+		http.Error(w, service.ErrInvalidRevisionRange.Error(), http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.revisions.Diff(r.Context(), chi.URLParam(r, "id"), from, to)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	writeJSON(w, diff)
+}
+
+// Files lists the attachments of a submission
+func (h *AdminSubmissionHandler) Files(w http.ResponseWriter, r *http.Request) {
+	files, err := h.files.List(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+	writeJSON(w, files)
+}
+
+func (h *AdminSubmissionHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
+	f, body, err := h.files.Open(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "file_id"))
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+	serveSubmissionFile(w, r, f, body)
+}
+
+// ListFileSlots returns the slots of ?cycle_id=, or the default set
+func (h *AdminSubmissionHandler) ListFileSlots(w http.ResponseWriter, r *http.Request) {
+	cycleID, ok := cycleFilter(r)
+	if !ok {
+		http.Error(w, "invalid cycle_id", http.StatusBadRequest)
+		return
+	}
+	slots, err := h.files.Slots(r.Context(), cycleID)
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+	writeJSON(w, slots)
+}
+
+// SetFileSlot creates or updates the {key} attachment slot of ?cycle_id=, or
+// of the default set
+func (h *AdminSubmissionHandler) SetFileSlot(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	cycleID, ok := cycleFilter(r)
+	if !ok {
+		http.Error(w, "invalid cycle_id", http.StatusBadRequest)
+		return
+	}
+
+	var slot model.SubmissionFileSlot
+	if err := json.NewDecoder(r.Body).Decode(&slot); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+	slot.Key = chi.URLParam(r, "key")
+	slot.CycleID = cycleID
+
+	if err := h.files.SetSlot(r.Context(), &slot, admin.UserID); err != nil {
+		writeFileError(w, err)
+		return
+	}
+	writeJSON(w, slot)
+}
+
+// DeleteFileSlot removes an attachment slot of ?cycle_id=, or of the default
+// set, that no submission uses
+func (h *AdminSubmissionHandler) DeleteFileSlot(w http.ResponseWriter, r *http.Request) {
+	cycleID, ok := cycleFilter(r)
+	if !ok {
+		http.Error(w, "invalid cycle_id", http.StatusBadRequest)
+		return
+	}
+	if err := h.files.DeleteSlot(r.Context(), cycleID, chi.URLParam(r, "key")); err != nil {
+		writeFileError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCycles returns every application cycle
+func (h *AdminSubmissionHandler) ListCycles(w http.ResponseWriter, r *http.Request) {
+	cycles, err := h.cycles.List(r.Context())
+	if err != nil {
+		writeCycleAdminError(w, err)
+		return
+	}
+	writeJSON(w, cycles)
+}
+
+func (h *AdminSubmissionHandler) CreateCycle(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var cycle model.ApplicationCycle
+	if err := json.NewDecoder(r.Body).Decode(&cycle); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.cycles.Create(r.Context(), &cycle, admin.UserID); err != nil {
+		writeCycleAdminError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(cycle)
+}
+
+func (h *AdminSubmissionHandler) UpdateCycle(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, repository.ErrCycleNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	var cycle model.ApplicationCycle
+	if err := json.NewDecoder(r.Body).Decode(&cycle); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+	cycle.ID = id
+
+	if err := h.cycles.Update(r.Context(), &cycle, admin.UserID); err != nil {
+		writeCycleAdminError(w, err)
+		return
+	}
+	writeJSON(w, cycle)
+}
+
+// DeleteCycle removes an application cycle no submission was made to
+func (h *AdminSubmissionHandler) DeleteCycle(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, repository.ErrCycleNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if err := h.cycles.Delete(r.Context(), id); err != nil {
+		writeCycleAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeCycleAdminError maps application cycle errors to responses.
+func writeCycleAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrCycleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidCycle), errors.Is(err, service.ErrInvalidForm):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrCycleNameTaken):
+		writeJSONError(w, http.StatusConflict, "CYCLE_NAME_TAKEN", err.Error())
+	case errors.Is(err, repository.ErrCycleInUse):
+		writeJSONError(w, http.StatusConflict, "CYCLE_IN_USE", err.Error())
+	default:
+		log.Println("[CYCLES]", err)
+		http.Error(w, "failed to process application cycle", http.StatusInternalServerError)
+	}
+}
+
+// CycleForms lists every version of a cycle's application form
+func (h *AdminSubmissionHandler) CycleForms(w http.ResponseWriter, r *http.Request) {
+	forms, err := h.forms.Versions(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeCycleAdminError(w, err)
+		return
+	}
+	writeJSON(w, forms)
+}
+
+type PublishFormRequest struct {
+	Fields []model.FormField `json:"fields"`
+}
+
+// PublishForm makes the given fields the next version of a cycle's form
+func (h *AdminSubmissionHandler) PublishForm(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req PublishFormRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	form, err := h.forms.Publish(r.Context(), chi.URLParam(r, "id"), req.Fields, admin.UserID)
+	if err != nil {
+		writeCycleAdminError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(form)
+}
+
+// GetSettings returns the submission workflow settings
+func (h *AdminSubmissionHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.settings.Get(r.Context())
+	if err != nil {
+		log.Println("[ADMIN] GetSettings failed:", err)
+		http.Error(w, "failed to load submission settings", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, settings)
+}
+
+// UpdateSettings changes how many revision rounds a submission may have
+func (h *AdminSubmissionHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req SubmissionSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.settings.SetMaxRevisionRounds(r.Context(), req.MaxRevisionRounds, admin.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,21 +2,25 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
 	"github.com/rudraa2005/mic-website-main/backend/internal/service"
 )
 
 type AIHandler struct {
-	ai *service.AIService
+	ai          *service.AIService
+	submissions *service.SubmissionsService
 }
 
-func NewAIHandler(ai *service.AIService) *AIHandler {
-	return &AIHandler{ai: ai}
+func NewAIHandler(ai *service.AIService, submissions *service.SubmissionsService) *AIHandler {
+	return &AIHandler{ai: ai, submissions: submissions}
 }
 
 func (h *AIHandler) AnalyzeDraft(w http.ResponseWriter, r *http.Request) {
@@ -28,7 +32,7 @@ func (h *AIHandler) AnalyzeDraft(w http.ResponseWriter, r *http.Request) {
 
 	var req struct {
 		SubmissionID string `json:"submission_id"`
-		FilePath     string `json:"file_path"`
+		FileID       string `json:"file_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -36,19 +40,32 @@ func (h *AIHandler) AnalyzeDraft(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	absPath := fmt.Sprintf("./uploads/%s_%s", req.SubmissionID, req.FilePath)
+	file, body, err := h.submissions.OpenFile(r.Context(), req.SubmissionID, user.UserID, req.FileID)
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
 	log.Printf(
 		"[AI] analyze request received | submission=%s user=%s file=%s\n",
 		req.SubmissionID,
 		user.UserID,
-		absPath,
+		file.StoragePath,
 	)
 
-	err := h.ai.CreateDraft(
+	// The analyzer reads from disk, so it gets a temporary copy of the file
+	absPath, err := spoolFile(file, body)
+	if err != nil {
+		log.Println("[AI] failed to copy file:", err)
+		http.Error(w, "failed to create ai draft", 500)
+		return
+	}
+	defer os.Remove(absPath)
+
+	err = h.ai.CreateDraft(
 		r.Context(),
 		req.SubmissionID,
 		user.UserID,
-		absPath,
+		file.StoragePath,
 	)
 	if err != nil {
 		log.Println("[AI] CreateDraft failed:", err)
@@ -87,3 +104,25 @@ func (h *AIHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
 		"insights": insights,
 	})
 }
+
+// spoolFile copies a stored attachment to a temporary file that keeps its
+// extension, and closes body.
+func spoolFile(f *model.SubmissionFile, body io.ReadCloser) (string, error) {
+	defer body.Close()
+
+	tmp, err := os.CreateTemp("", "analyze-*"+filepath.Ext(f.FileName))
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
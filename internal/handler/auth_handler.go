@@ -2,26 +2,35 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
 	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
 	"github.com/rudraa2005/mic-website-main/backend/internal/service"
 )
 
 type AuthHandler struct {
 	authService *service.AuthService
+	cookies     *SessionCookies
 }
 
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+func NewAuthHandler(authService *service.AuthService, cookies *SessionCookies) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
+		cookies:     cookies,
 	}
 }
 
 type signupRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	Name        string `json:"name"`
+	InviteToken string `json:"invite_token"`
 }
 
 type LoginRequest struct {
@@ -29,11 +38,39 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 type LoginResponse struct {
-	Token string `json:"token"`
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	Token        string   `json:"token,omitempty"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	ExpiresIn    int      `json:"expires_in,omitempty"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Email        string   `json:"email"`
+	Role         string   `json:"role"`
+	Roles        []string `json:"roles"`
+	AuthMode     string   `json:"auth_mode,omitempty"`
+
+	MFARequired           bool   `json:"mfa_required,omitempty"`
+	MFAToken              string `json:"mfa_token,omitempty"`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+}
+
+type SwitchRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type LoginMFARequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshResponse struct {
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in"`
 }
 
 type ChangePasswordRequest struct {
@@ -48,14 +85,17 @@ func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.authService.Signup(r.Context(), req.Email, req.Password, req.Name)
+	err := h.authService.Signup(r.Context(), req.Email, req.Password, req.Name, req.InviteToken, middleware.ClientIP(r))
+	if writePasswordPolicyError(w, err) {
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"message": "User created successfully"})
+	json.NewEncoder(w).Encode(map[string]string{"message": "User created successfully. Check your email to verify your account."})
 }
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -65,14 +105,199 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, token, err := h.authService.Login(req.Email, req.Password)
+	result, err := h.authService.Login(r.Context(), req.Email, req.Password, sessionMeta(r))
 
+	var locked *service.LoginLockedError
+	if errors.As(err, &locked) {
+		writeLockedError(w, locked)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		writeJSONError(w, http.StatusUnauthorized, "INVALID_CREDENTIALS", err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrPasswordLoginDisabled) {
+		writeJSONError(w, http.StatusForbidden, "PASSWORD_LOGIN_DISABLED", err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		writeJSONError(w, http.StatusForbidden, "EMAIL_NOT_VERIFIED", err.Error())
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	json.NewEncoder(w).Encode(LoginResponse{Token: token, ID: user.UserID, Name: user.Name, Email: user.Email, Role: user.Role})
+	h.writeLogin(w, r, result)
+}
+
+// LoginMFA is the second step of a login for users with 2FA enabled
+func (h *AuthHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req LoginMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.authService.CompleteMFALogin(r.Context(), req.MFAToken, req.Code, req.RecoveryCode, sessionMeta(r))
+	var locked *service.LoginLockedError
+	if errors.As(err, &locked) {
+		writeLockedError(w, locked)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusUnauthorized, "INVALID_MFA_CODE", err.Error())
+		return
+	}
+
+	h.writeLogin(w, r, result)
+}
+
+// SwitchRole makes the current session act as another of the user's roles
+// and returns a new access token
+func (h *AuthHandler) SwitchRole(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req SwitchRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.authService.SwitchRole(r.Context(), claims.UserID, claims.SessionID, strings.ToUpper(req.Role), claims.MFA)
+	if errors.Is(err, service.ErrRoleNotHeld) {
+		writeJSONError(w, http.StatusForbidden, "ROLE_NOT_HELD", err.Error())
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to switch role", http.StatusInternalServerError)
+		return
+	}
+
+	resp := map[string]any{
+		"expires_in": int(auth.AccessTTL().Seconds()),
+		"role":       strings.ToUpper(req.Role),
+	}
+	if h.cookies.Wants(r) {
+		h.cookies.SetAccess(w, token)
+	} else {
+		resp["token"] = token
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// writeLogin sends the login result, moving the tokens into cookies when the
+// client uses cookie sessions
+func (h *AuthHandler) writeLogin(w http.ResponseWriter, r *http.Request, result *service.LoginResult) {
+	resp := loginResponse(result)
+	if result.Tokens != nil && h.cookies.Wants(r) {
+		if err := h.cookies.SetSession(w, resp.Token, resp.RefreshToken); err != nil {
+			http.Error(w, "failed to start session", http.StatusInternalServerError)
+			return
+		}
+		resp.Token = ""
+		resp.RefreshToken = ""
+		resp.AuthMode = "cookie"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func writeLockedError(w http.ResponseWriter, locked *service.LoginLockedError) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+	writeJSONError(w, http.StatusTooManyRequests, "TOO_MANY_ATTEMPTS", locked.Error())
+}
+
+func loginResponse(result *service.LoginResult) LoginResponse {
+	resp := LoginResponse{
+		ID:                    result.User.UserID,
+		Name:                  result.User.Name,
+		Email:                 result.User.Email,
+		Role:                  result.User.Role,
+		Roles:                 result.User.Roles,
+		MFARequired:           result.MFAToken != "",
+		MFAToken:              result.MFAToken,
+		MFAEnrollmentRequired: result.MFAEnrollmentRequired,
+	}
+	if len(resp.Roles) == 0 {
+		resp.Roles = []string{resp.Role}
+	}
+	if result.Tokens != nil {
+		resp.Token = result.Tokens.AccessToken
+		resp.RefreshToken = result.Tokens.RefreshToken
+		resp.ExpiresIn = result.Tokens.ExpiresIn
+	}
+	return resp
+}
+
+// Refresh rotates the refresh token. Cookie clients may send an empty body
+// and the token is read from the refresh cookie.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	useCookies := req.RefreshToken == "" && h.cookies.Wants(r)
+	if useCookies {
+		if c, err := r.Cookie(middleware.RefreshCookieName); err == nil {
+			req.RefreshToken = c.Value
+		}
+	}
+
+	tokens, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		if useCookies {
+			h.cookies.Clear(w)
+		}
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	resp := RefreshResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+	if useCookies {
+		if err := h.cookies.SetSession(w, tokens.AccessToken, tokens.RefreshToken); err != nil {
+			http.Error(w, "failed to refresh session", http.StatusInternalServerError)
+			return
+		}
+		resp.Token = ""
+		resp.RefreshToken = ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authService.Logout(r.Context(), user.UserID, user.SessionID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if middleware.UsesCookieAuth(r) {
+		h.cookies.Clear(w)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out"})
 }
 
 func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
@@ -80,15 +305,19 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	var req ChangePasswordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
 	}
 
 	user, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		http.Error(w, "User Unauthorized", http.StatusUnauthorized)
+		return
 	}
 
 	err := h.authService.ChangePassword(r.Context(), user.UserID, req.CurrentPassword, req.NewPassword)
-
+	if writePasswordPolicyError(w, err) {
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -96,3 +325,10 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Password updated successfully"})
 }
+
+func sessionMeta(r *http.Request) model.SessionMeta {
+	return model.SessionMeta{
+		UserAgent: r.UserAgent(),
+		IPAddress: middleware.ClientIP(r),
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+type EmailVerificationHandler struct {
+	service *service.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(s *service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{service: s}
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+// Verify confirms an email address from the token in a verification link
+func (h *EmailVerificationHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Verify(r.Context(), req.Token); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "INVALID_VERIFICATION_TOKEN", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Email verified. You can now log in."})
+}
+
+// Resend emails a new verification link. The response does not reveal
+// whether the account exists or is already verified.
+func (h *EmailVerificationHandler) Resend(w http.ResponseWriter, r *http.Request) {
+	var req ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	h.service.Resend(r.Context(), req.Email, middleware.ClientIP(r))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "If that account still needs verifying, a new link has been sent.",
+	})
+}
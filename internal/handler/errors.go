@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+// writeJSONError sends an error with a stable machine-readable code that the
+// frontend can switch on.
+func writeJSONError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+		"code":  code,
+	})
+}
+
+// writeWorkflowError maps submission workflow errors to responses.
+func writeWorkflowError(w http.ResponseWriter, err error) {
+	var missing *service.MissingFilesError
+	if writeAnswersError(w, err) {
+		return
+	}
+	switch {
+	case errors.As(err, &missing):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{
+			"error":   missing.Error(),
+			"code":    "MISSING_FILES",
+			"missing": missing.Slots,
+		})
+	case errors.Is(err, repository.ErrSubmissionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrTransitionNotAllowed):
+		writeJSONError(w, http.StatusConflict, "INVALID_TRANSITION", err.Error())
+	case errors.Is(err, repository.ErrStatusChanged):
+		writeJSONError(w, http.StatusConflict, "STATUS_CHANGED", err.Error())
+	case errors.Is(err, service.ErrTransitionForbidden):
+		writeJSONError(w, http.StatusForbidden, "TRANSITION_FORBIDDEN", err.Error())
+	case errors.Is(err, service.ErrReasonRequired):
+		writeJSONError(w, http.StatusBadRequest, "REASON_REQUIRED", err.Error())
+	case errors.Is(err, service.ErrCycleClosed):
+		writeJSONError(w, http.StatusConflict, "CYCLE_CLOSED", err.Error())
+	case errors.Is(err, service.ErrCycleRequired):
+		writeJSONError(w, http.StatusBadRequest, "CYCLE_REQUIRED", err.Error())
+	case errors.Is(err, service.ErrRevisionLimitReached):
+		writeJSONError(w, http.StatusConflict, "REVISION_LIMIT_REACHED", err.Error())
+	default:
+		log.Println("[WORKFLOW]", err)
+		http.Error(w, "failed to update submission status", http.StatusInternalServerError)
+	}
+}
+
+// writeCycleError answers for a cycle a submission cannot be made to and
+// reports whether err was one of those.
+func writeCycleError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, repository.ErrCycleNotFound):
+		writeJSONError(w, http.StatusBadRequest, "CYCLE_NOT_FOUND", err.Error())
+	case errors.Is(err, service.ErrCycleClosed):
+		writeJSONError(w, http.StatusConflict, "CYCLE_CLOSED", err.Error())
+	case errors.Is(err, service.ErrCycleRequired):
+		writeJSONError(w, http.StatusBadRequest, "CYCLE_REQUIRED", err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// writeAnswersError answers 400 with code INVALID_ANSWERS and what is wrong
+// with each answer when err is an application form failure. It reports
+// whether it wrote a response.
+func writeAnswersError(w http.ResponseWriter, err error) bool {
+	var invalid *service.InvalidAnswersError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":  invalid.Error(),
+		"code":   "INVALID_ANSWERS",
+		"fields": invalid.Fields,
+	})
+	return true
+}
+
+// writePasswordPolicyError answers 400 with code WEAK_PASSWORD and every
+// violated rule when err is a password policy failure. It reports whether it
+// wrote a response.
+func writePasswordPolicyError(w http.ResponseWriter, err error) bool {
+	var policyErr *auth.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":      policyErr.Error(),
+		"code":       "WEAK_PASSWORD",
+		"violations": policyErr.Violations,
+	})
+	return true
+}
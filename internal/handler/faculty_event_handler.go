@@ -47,11 +47,6 @@ func (h *EventInvitationHandler) GetMyInvitations(
 		return
 	}
 
-	if claims.Role != "FACULTY" {
-		http.Error(w, "forbidden", http.StatusForbidden)
-		return
-	}
-
 	events, err := h.service.GetFacultyEvents(
 		r.Context(),
 		claims.UserID,
@@ -96,11 +91,6 @@ func (h *EventInvitationHandler) UpdateRSVP(
 		return
 	}
 
-	if claims.Role != "FACULTY" {
-		http.Error(w, "forbidden", http.StatusForbidden)
-		return
-	}
-
 	invitationID := chi.URLParam(r, "invitation_id")
 	if invitationID == "" {
 		http.Error(w, "missing invitation id", http.StatusBadRequest)
@@ -31,11 +31,6 @@ func (h *FacultyProgressHandler) GetMyProgress(
 		return
 	}
 
-	if claims.Role != "FACULTY" {
-		http.Error(w, "forbidden", http.StatusForbidden)
-		return
-	}
-
 	data, err := h.service.GetProgressForFaculty(
 		r.Context(),
 		claims.UserID,
@@ -60,11 +55,6 @@ func (h *FacultyProgressHandler) GetProgressBySubmission(
 		return
 	}
 
-	if claims.Role != "FACULTY" {
-		http.Error(w, "forbidden", http.StatusForbidden)
-		return
-	}
-
 	submissionID := chi.URLParam(r, "submission_id")
 	if submissionID == "" {
 		http.Error(w, "missing submission id", http.StatusBadRequest)
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -51,13 +52,13 @@ func (h *FacultyReviewHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *FacultyReviewHandler) Decide(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	claims, err := middleware.GetUser(r)
-
-	if err != nil || claims.Role != "FACULTY" {
-		http.Error(w, "forbidden", http.StatusForbidden)
+	if err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 	var body struct {
 		Decision string `json:"decision"`
+		Reason   string `json:"reason"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -65,11 +66,85 @@ func (h *FacultyReviewHandler) Decide(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.Decide(r.Context(), id, claims.UserID, body.Decision)
-	if err != nil {
+	err = h.service.Decide(r.Context(), id, claims, body.Decision, body.Reason)
+	if errors.Is(err, service.ErrInvalidDecision) {
 		http.Error(w, err.Error(), 400)
 		return
 	}
+	if err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Timeline returns the status history of a submission under review
+func (h *FacultyReviewHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	history, err := h.service.Timeline(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+	writeJSON(w, history)
+}
+
+// Revisions lists the saved versions of a submission under review
+func (h *FacultyReviewHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	revisions, err := h.service.Revisions(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	writeJSON(w, revisions)
+}
+
+func (h *FacultyReviewHandler) Revision(w http.ResponseWriter, r *http.Request) {
+	number, ok := revisionNumber(r)
+	if !ok {
+		http.Error(w, "invalid revision number", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := h.service.Revision(r.Context(), chi.URLParam(r, "id"), number)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	writeJSON(w, revision)
+}
+
+// RevisionDiff compares two revisions given as ?from=&to=
+func (h *FacultyReviewHandler) RevisionDiff(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := revisionRange(r)
+	if !ok {
+		http.Error(w, service.ErrInvalidRevisionRange.Error(), http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.service.RevisionDiff(r.Context(), chi.URLParam(r, "id"), from, to)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	writeJSON(w, diff)
+}
+
+// Files lists the attachments of a submission under review
+func (h *FacultyReviewHandler) Files(w http.ResponseWriter, r *http.Request) {
+	files, err := h.service.Files(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+	writeJSON(w, files)
+}
+
+func (h *FacultyReviewHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
+	f, body, err := h.service.OpenFile(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "file_id"))
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+	serveSubmissionFile(w, r, f, body)
+}
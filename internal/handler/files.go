@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+	"github.com/rudraa2005/mic-website-main/backend/internal/storage"
+)
+
+// maxUploadSize is the largest file accepted per upload.
+const maxUploadSize = service.MaxUploadSize
+
+// serveSubmissionFile streams a stored attachment as a download. Range
+// requests are only supported when the blob store can seek.
+func serveSubmissionFile(w http.ResponseWriter, r *http.Request, f *model.SubmissionFile, body io.ReadCloser) {
+	defer body.Close()
+	w.Header().Set("Content-Type", f.MimeType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": f.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if rs, ok := body.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, f.FileName, f.CreatedAt, rs)
+		return
+	}
+	if f.SizeBytes > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(f.SizeBytes, 10))
+	}
+	w.Header().Set("Last-Modified", f.CreatedAt.UTC().Format(http.TimeFormat))
+	if r.Method != http.MethodHead {
+		io.Copy(w, body)
+	}
+}
+
+// writeFileError maps submission file errors to responses.
+func writeFileError(w http.ResponseWriter, err error) {
+	if writeUploadError(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, repository.ErrSubmissionNotFound),
+		errors.Is(err, storage.ErrNotFound),
+		errors.Is(err, repository.ErrFileNotFound),
+		errors.Is(err, repository.ErrSlotNotFound),
+		errors.Is(err, repository.ErrCycleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrSubmissionLocked):
+		writeJSONError(w, http.StatusConflict, "SUBMISSION_LOCKED", err.Error())
+	case errors.Is(err, service.ErrViewerCannotEdit):
+		writeJSONError(w, http.StatusForbidden, "VIEWER_CANNOT_EDIT", err.Error())
+	case errors.Is(err, repository.ErrSlotInUse):
+		writeJSONError(w, http.StatusConflict, "SLOT_IN_USE", err.Error())
+	case errors.Is(err, service.ErrInvalidSlot):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Println("[FILES]", err)
+		http.Error(w, "failed to process file", http.StatusInternalServerError)
+	}
+}
+
+// writeUploadError answers for uploads the inspector rejected and reports
+// whether err was one of those.
+func writeUploadError(w http.ResponseWriter, err error) bool {
+	var infected *service.InfectedFileError
+	switch {
+	case errors.Is(err, service.ErrFileTooLarge):
+		writeJSONError(w, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", err.Error())
+	case errors.Is(err, service.ErrFileTypeNotAllowed):
+		writeJSONError(w, http.StatusUnsupportedMediaType, "FILE_TYPE_NOT_ALLOWED", err.Error())
+	case errors.Is(err, service.ErrQuotaExceeded):
+		writeJSONError(w, http.StatusRequestEntityTooLarge, "QUOTA_EXCEEDED", err.Error())
+	case errors.As(err, &infected):
+		writeJSONError(w, http.StatusUnprocessableEntity, "FILE_INFECTED", err.Error())
+	case errors.Is(err, service.ErrScanUnavailable):
+		writeJSONError(w, http.StatusServiceUnavailable, "SCAN_UNAVAILABLE", err.Error())
+	default:
+		return false
+	}
+	return true
+}
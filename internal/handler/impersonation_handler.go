@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+type ImpersonationHandler struct {
+	service *service.ImpersonationService
+}
+
+func NewImpersonationHandler(s *service.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{service: s}
+}
+
+type StartImpersonationRequest struct {
+	UserID      string `json:"user_id"`
+	Reason      string `json:"reason"`
+	AllowWrites bool   `json:"allow_writes"`
+}
+
+// Start returns a short-lived token for viewing the site as another user
+func (h *ImpersonationHandler) Start(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req StartImpersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	start, err := h.service.Start(r.Context(), admin, req.UserID, req.Reason, req.AllowWrites)
+	if errors.Is(err, service.ErrCannotImpersonate) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(start)
+}
+
+// End stops one of the current admin's impersonations
+func (h *ImpersonationHandler) End(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.service.End(r.Context(), chi.URLParam(r, "id"), admin.UserID)
+	if errors.Is(err, repository.ErrImpersonationNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to end impersonation", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// List returns recent impersonations by any admin
+func (h *ImpersonationHandler) List(w http.ResponseWriter, r *http.Request) {
+	imps, err := h.service.List(r.Context())
+	if err != nil {
+		http.Error(w, "failed to load impersonations", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, imps)
+}
+
+// ListRequests returns the audit log of one impersonation
+func (h *ImpersonationHandler) ListRequests(w http.ResponseWriter, r *http.Request) {
+	reqs, err := h.service.ListRequests(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "failed to load impersonation requests", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, reqs)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+type LockoutHandler struct {
+	throttle *service.LoginThrottle
+}
+
+func NewLockoutHandler(t *service.LoginThrottle) *LockoutHandler {
+	return &LockoutHandler{throttle: t}
+}
+
+// List returns every account and IP that is currently locked out
+func (h *LockoutHandler) List(w http.ResponseWriter, r *http.Request) {
+	lockouts, err := h.throttle.ListLockouts(r.Context())
+	if err != nil {
+		http.Error(w, "failed to load lockouts", http.StatusInternalServerError)
+		return
+	}
+	if lockouts == nil {
+		lockouts = []model.LoginAttempt{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lockouts)
+}
+
+// Clear lifts the lockout named by the key query parameter,
+// e.g. ?key=account:user@manipal.edu or ?key=ip:10.0.0.1
+func (h *LockoutHandler) Clear(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		http.Error(w, "key is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.throttle.ClearLockout(r.Context(), key); err != nil {
+		http.Error(w, "failed to clear lockout", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+type MFAHandler struct {
+	service *service.MFAService
+	cookies *SessionCookies
+}
+
+func NewMFAHandler(s *service.MFAService, cookies *SessionCookies) *MFAHandler {
+	return &MFAHandler{service: s, cookies: cookies}
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+type MFAConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Token         string   `json:"token,omitempty"`
+}
+
+type MFAPolicyRequest struct {
+	Required bool `json:"required"`
+}
+
+// Status reports whether the current user has 2FA enabled and whether their
+// role requires it
+func (h *MFAHandler) Status(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	status, err := h.service.Status(r.Context(), user.UserID, user.RoleSet())
+	if err != nil {
+		log.Println("[MFA] status failed:", err)
+		http.Error(w, "failed to load two-factor status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// Enroll starts TOTP setup and returns the secret for the authenticator app
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	enrollment, err := h.service.BeginEnrollment(r.Context(), user.UserID, user.Email)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// ConfirmEnrollment turns 2FA on and hands back the recovery codes together
+// with an access token that carries the mfa claim
+func (h *MFAHandler) ConfirmEnrollment(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.service.ConfirmEnrollment(r.Context(), user.UserID, user.SessionID, req.Code)
+	if errors.Is(err, service.ErrInvalidMFACode) {
+		writeJSONError(w, http.StatusBadRequest, "INVALID_MFA_CODE", err.Error())
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token, err := auth.CreateToken(user.UserID, user.Role, user.RoleSet(), user.Email, user.SessionID, true)
+	if err != nil {
+		http.Error(w, "failed to issue token", http.StatusInternalServerError)
+		return
+	}
+
+	resp := MFAConfirmResponse{RecoveryCodes: codes, Token: token}
+	if h.cookies.Wants(r) {
+		h.cookies.SetAccess(w, token)
+		resp.Token = ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(r.Context(), user.UserID, req.Code)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "INVALID_MFA_CODE", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]string{"recovery_codes": codes})
+}
+
+// Disable turns 2FA off for the current user
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.Disable(r.Context(), user.UserID, user.RoleSet(), req.Code)
+	if errors.Is(err, service.ErrMFARequiredByRole) {
+		writeJSONError(w, http.StatusForbidden, "MFA_REQUIRED", err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "INVALID_MFA_CODE", err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPolicies lists the per-role 2FA policies
+func (h *MFAHandler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.service.GetPolicies(r.Context())
+	if err != nil {
+		http.Error(w, "failed to load policies", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+// SetPolicy makes 2FA mandatory or optional for a role
+func (h *MFAHandler) SetPolicy(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req MFAPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	role := strings.ToUpper(chi.URLParam(r, "role"))
+	if err := h.service.SetPolicy(r.Context(), role, req.Required, admin.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
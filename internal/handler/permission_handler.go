@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+type PermissionHandler struct {
+	service *service.PermissionService
+}
+
+func NewPermissionHandler(s *service.PermissionService) *PermissionHandler {
+	return &PermissionHandler{service: s}
+}
+
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// Mine lists the permissions the current user's roles grant, so the UI can
+// hide what the user cannot do
+func (h *PermissionHandler) Mine(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	perms, err := h.service.UserPermissions(r.Context(), user.RoleSet())
+	if err != nil {
+		http.Error(w, "failed to load permissions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string][]string{"permissions": perms})
+}
+
+func (h *PermissionHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	perms, err := h.service.ListPermissions(r.Context())
+	if err != nil {
+		http.Error(w, "failed to load permissions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, perms)
+}
+
+// ListRoles returns every role with the permissions it grants
+func (h *PermissionHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.service.ListRoles(r.Context())
+	if err != nil {
+		http.Error(w, "failed to load roles", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, roles)
+}
+
+// SetRolePermissions replaces the permissions a role grants
+func (h *PermissionHandler) SetRolePermissions(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req RolePermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	role := strings.ToUpper(chi.URLParam(r, "role"))
+	err := h.service.SetRolePermissions(r.Context(), role, req.Permissions, admin.UserID)
+	if errors.Is(err, repository.ErrRoleNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
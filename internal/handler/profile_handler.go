@@ -1,17 +1,19 @@
 package handler
 
 import (
+	"bufio"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
+	"log"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
 	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+	"github.com/rudraa2005/mic-website-main/backend/internal/storage"
 )
 
 type ProfileHandler struct {
@@ -24,6 +26,19 @@ func NewProfileHandler(s *service.ProfileService) *ProfileHandler {
 	}
 }
 
+// ImpersonationMarker tells the UI that an admin is viewing the site as
+// this user.
+type ImpersonationMarker struct {
+	ImpersonatorID string    `json:"impersonator_id"`
+	ReadOnly       bool      `json:"read_only"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+type MeResponse struct {
+	*model.Profile
+	Impersonation *ImpersonationMarker `json:"impersonation,omitempty"`
+}
+
 func (h *ProfileHandler) Me(w http.ResponseWriter, r *http.Request) {
 	user, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
@@ -38,7 +53,18 @@ func (h *ProfileHandler) Me(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	json.NewEncoder(w).Encode(profile)
+	resp := MeResponse{Profile: profile}
+	if user.IsImpersonating() {
+		resp.Impersonation = &ImpersonationMarker{
+			ImpersonatorID: user.ImpersonatorID,
+			ReadOnly:       user.ReadOnly,
+		}
+		if user.ExpiresAt != nil {
+			resp.Impersonation.ExpiresAt = user.ExpiresAt.Time
+		}
+	}
+
+	json.NewEncoder(w).Encode(resp)
 }
 
 func (h *ProfileHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
@@ -49,7 +75,8 @@ func (h *ProfileHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse multipart form with 10MB max file size
-	err := r.ParseMultipartForm(10 << 20) // 10MB
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize+1<<20)
+	err := r.ParseMultipartForm(maxUploadSize)
 	if err != nil {
 		http.Error(w, "failed to parse form: "+err.Error(), http.StatusBadRequest)
 		return
@@ -62,57 +89,13 @@ func (h *ProfileHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	// Validate file type
-	ext := strings.ToLower(filepath.Ext(handler.Filename))
-	allowedExts := []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
-	validExt := false
-	for _, allowedExt := range allowedExts {
-		if ext == allowedExt {
-			validExt = true
-			break
-		}
-	}
-	if !validExt {
-		http.Error(w, "invalid file type. Allowed: jpg, jpeg, png, gif, webp", http.StatusBadRequest)
-		return
-	}
-
-	// Create uploads directory if it doesn't exist
-	workDir, _ := os.Getwd()
-	uploadsDir := filepath.Join(workDir, "frontend", "static", "uploads")
-	if err := os.MkdirAll(uploadsDir, 0755); err != nil {
-		http.Error(w, "failed to create uploads directory", http.StatusInternalServerError)
+	photoURL, err := h.profileService.UploadPhoto(r.Context(), user.UserID, handler.Filename, file)
+	if writeUploadError(w, err) {
 		return
 	}
-
-	// Generate unique filename
-	filename := fmt.Sprintf("%s_%d%s", user.UserID, time.Now().Unix(), ext)
-	filePath := filepath.Join(uploadsDir, filename)
-
-	// Create file on disk
-	dst, err := os.Create(filePath)
 	if err != nil {
-		http.Error(w, "failed to save file: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	defer dst.Close()
-
-	// Copy file content
-	_, err = io.Copy(dst, file)
-	if err != nil {
-		os.Remove(filePath) // Clean up on error
-		http.Error(w, "failed to save file: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Generate URL path
-	photoURL := fmt.Sprintf("/static/uploads/%s", filename)
-
-	// Update profile with photo URL
-	err = h.profileService.UpdatePhotoURL(r.Context(), user.UserID, photoURL)
-	if err != nil {
-		os.Remove(filePath) // Clean up on error
-		http.Error(w, "failed to update profile: "+err.Error(), http.StatusInternalServerError)
+		log.Println("[PROFILE] photo upload failed:", err)
+		http.Error(w, "failed to save photo", http.StatusInternalServerError)
 		return
 	}
 
@@ -123,3 +106,26 @@ func (h *ProfileHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 		"photo_url": photoURL,
 	})
 }
+
+// Photo serves a stored profile photo. Photos are addressed by their content
+// hash, so they never change and can be cached indefinitely.
+func (h *ProfileHandler) Photo(w http.ResponseWriter, r *http.Request) {
+	body, err := h.profileService.Photo(r.Context(), chi.URLParam(r, "sum"))
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "photo not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("[PROFILE] photo read failed:", err)
+		http.Error(w, "failed to load photo", http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	br := bufio.NewReader(body)
+	head, _ := br.Peek(512)
+	w.Header().Set("Content-Type", http.DetectContentType(head))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	io.Copy(w, br)
+}
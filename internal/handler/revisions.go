@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+// revisionNumber reads the {number} URL parameter.
+func revisionNumber(r *http.Request) (int, bool) {
+	n, err := strconv.Atoi(chi.URLParam(r, "number"))
+	return n, err == nil && n > 0
+}
+
+// revisionRange reads ?from= and ?to= for a diff.
+func revisionRange(r *http.Request) (int, int, bool) {
+	from, err1 := strconv.Atoi(r.URL.Query().Get("from"))
+	to, err2 := strconv.Atoi(r.URL.Query().Get("to"))
+	return from, to, err1 == nil && err2 == nil
+}
+
+// writeRevisionError maps submission revision errors to responses.
+func writeRevisionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrSubmissionNotFound), errors.Is(err, repository.ErrRevisionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidRevisionRange):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Println("[REVISIONS]", err)
+		http.Error(w, "failed to load revisions", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+type RoleAdminHandler struct {
+	service *service.RoleService
+}
+
+func NewRoleAdminHandler(s *service.RoleService) *RoleAdminHandler {
+	return &RoleAdminHandler{service: s}
+}
+
+type RoleRuleRequest struct {
+	Role string `json:"role"`
+	Note string `json:"note"`
+}
+
+type RoleInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type ChangeRoleRequest struct {
+	Role   string `json:"role"`
+	Reason string `json:"reason"`
+}
+
+func (h *RoleAdminHandler) ListDomainRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ListDomainRules(r.Context())
+	if err != nil {
+		http.Error(w, "failed to load domain rules", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, rules)
+}
+
+func (h *RoleAdminHandler) SetDomainRule(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req RoleRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.SetDomainRule(r.Context(), chi.URLParam(r, "domain"), strings.ToUpper(req.Role), admin.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoleAdminHandler) DeleteDomainRule(w http.ResponseWriter, r *http.Request) {
+	err := h.service.DeleteDomainRule(r.Context(), chi.URLParam(r, "domain"))
+	if errors.Is(err, repository.ErrRoleRuleNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to delete domain rule", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoleAdminHandler) ListOverrides(w http.ResponseWriter, r *http.Request) {
+	overrides, err := h.service.ListOverrides(r.Context())
+	if err != nil {
+		http.Error(w, "failed to load overrides", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, overrides)
+}
+
+func (h *RoleAdminHandler) SetOverride(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req RoleRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.SetOverride(r.Context(), chi.URLParam(r, "email"), strings.ToUpper(req.Role), req.Note, admin.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoleAdminHandler) DeleteOverride(w http.ResponseWriter, r *http.Request) {
+	err := h.service.DeleteOverride(r.Context(), chi.URLParam(r, "email"))
+	if errors.Is(err, repository.ErrRoleRuleNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to delete override", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoleAdminHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	invites, err := h.service.ListInvites(r.Context())
+	if err != nil {
+		http.Error(w, "failed to load invites", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, invites)
+}
+
+// CreateInvite emails a signup link that grants the chosen role
+func (h *RoleAdminHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req RoleInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	inv, err := h.service.Invite(r.Context(), req.Email, strings.ToUpper(req.Role), admin.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(inv)
+}
+
+func (h *RoleAdminHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	err := h.service.RevokeInvite(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, repository.ErrInviteNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to revoke invite", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChangeUserRole sets an existing user's role. The change is audited and the
+// user is signed out everywhere.
+func (h *RoleAdminHandler) ChangeUserRole(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req ChangeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	if userID == admin.UserID {
+		http.Error(w, "you cannot change your own role", http.StatusForbidden)
+		return
+	}
+
+	err := h.service.ChangeUserRole(r.Context(), userID, strings.ToUpper(req.Role), admin.UserID, req.Reason)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GrantRole gives an existing user an additional role
+func (h *RoleAdminHandler) GrantRole(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req ChangeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.GrantRole(r.Context(), chi.URLParam(r, "id"), strings.ToUpper(req.Role), admin.UserID, req.Reason)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeRole takes an additional role away from a user. The reason is passed
+// as ?reason=
+func (h *RoleAdminHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	if userID == admin.UserID {
+		http.Error(w, "you cannot change your own roles", http.StatusForbidden)
+		return
+	}
+
+	role := strings.ToUpper(chi.URLParam(r, "role"))
+	err := h.service.RevokeRole(r.Context(), userID, role, admin.UserID, r.URL.Query().Get("reason"))
+	if errors.Is(err, repository.ErrRoleNotGranted) || errors.Is(err, repository.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAudit returns role changes, optionally for one user via ?user_id=
+func (h *RoleAdminHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.service.ListAudit(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, "failed to load role audit log", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, entries)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+)
+
+// AuthModeHeader lets a browser ask for cookie sessions at login instead of
+// tokens in the response body.
+const AuthModeHeader = "X-Auth-Mode"
+
+const refreshCookiePath = "/api/token/refresh"
+
+// oidcStateCookie ties a single sign-on callback to the browser that started
+// the login, so a callback URL from someone else's login is refused.
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/auth/oidc"
+)
+
+// SessionCookies issues the HttpOnly session cookies used by the
+// server-rendered site. When disabled every client gets bearer tokens.
+type SessionCookies struct {
+	enabled bool
+	secure  bool
+}
+
+func NewSessionCookies(enabled, secure bool) *SessionCookies {
+	return &SessionCookies{enabled: enabled, secure: secure}
+}
+
+// Enabled reports whether cookie sessions are turned on.
+func (c *SessionCookies) Enabled() bool {
+	return c != nil && c.enabled
+}
+
+// Wants reports whether the response to r should carry cookies rather than
+// tokens: the client asked for cookie mode at login, or is already using it.
+func (c *SessionCookies) Wants(r *http.Request) bool {
+	if !c.Enabled() {
+		return false
+	}
+	if r.Header.Get(AuthModeHeader) == "cookie" || middleware.UsesCookieAuth(r) {
+		return true
+	}
+	if r.Header.Get("Authorization") != "" {
+		return false
+	}
+	rc, err := r.Cookie(middleware.RefreshCookieName)
+	return err == nil && rc.Value != ""
+}
+
+// SetSession stores a new token pair and a fresh CSRF token.
+func (c *SessionCookies) SetSession(w http.ResponseWriter, accessToken, refreshToken string) error {
+	csrf, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	refreshAge := int(auth.RefreshTTL().Seconds())
+
+	c.SetAccess(w, accessToken)
+	http.SetCookie(w, c.cookie(middleware.RefreshCookieName, refreshToken, refreshCookiePath, refreshAge, true))
+	http.SetCookie(w, c.cookie(middleware.CSRFCookieName, csrf, "/", refreshAge, false))
+	return nil
+}
+
+// SetAccess replaces only the access token, e.g. after a role switch.
+func (c *SessionCookies) SetAccess(w http.ResponseWriter, accessToken string) {
+	http.SetCookie(w, c.cookie(middleware.AccessCookieName, accessToken, "/", int(auth.AccessTTL().Seconds()), true))
+}
+
+// Clear removes all session cookies.
+func (c *SessionCookies) Clear(w http.ResponseWriter) {
+	http.SetCookie(w, c.cookie(middleware.AccessCookieName, "", "/", -1, true))
+	http.SetCookie(w, c.cookie(middleware.RefreshCookieName, "", refreshCookiePath, -1, true))
+	http.SetCookie(w, c.cookie(middleware.CSRFCookieName, "", "/", -1, false))
+}
+
+// SetOIDCState remembers the state of a login started by this browser. It is
+// SameSite=Lax because the identity provider redirects back cross-site.
+func (c *SessionCookies) SetOIDCState(w http.ResponseWriter, state string, ttl time.Duration) {
+	ck := c.cookie(oidcStateCookie, state, oidcStateCookiePath, int(ttl.Seconds()), true)
+	ck.SameSite = http.SameSiteLaxMode
+	http.SetCookie(w, ck)
+}
+
+// OIDCStateMatches reports whether state is the one this browser started
+// a login with.
+func (c *SessionCookies) OIDCStateMatches(r *http.Request, state string) bool {
+	ck, err := r.Cookie(oidcStateCookie)
+	if err != nil || ck.Value == "" || state == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(ck.Value), []byte(state)) == 1
+}
+
+// ClearOIDCState removes the login state cookie once the callback has run.
+func (c *SessionCookies) ClearOIDCState(w http.ResponseWriter) {
+	ck := c.cookie(oidcStateCookie, "", oidcStateCookiePath, -1, true)
+	ck.SameSite = http.SameSiteLaxMode
+	http.SetCookie(w, ck)
+}
+
+func (c *SessionCookies) cookie(name, value, path string, maxAge int, httpOnly bool) *http.Cookie {
+	ck := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: httpOnly,
+		Secure:   c.secure,
+		SameSite: http.SameSiteStrictMode,
+	}
+	if maxAge > 0 {
+		ck.Expires = time.Now().Add(time.Duration(maxAge) * time.Second)
+	}
+	return ck
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+type SessionHandler struct {
+	service *service.SessionService
+	cookies *SessionCookies
+}
+
+func NewSessionHandler(s *service.SessionService, cookies *SessionCookies) *SessionHandler {
+	return &SessionHandler{service: s, cookies: cookies}
+}
+
+// List returns the current user's active sessions
+func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessions, err := h.service.List(r.Context(), user.UserID, user.SessionID)
+	if err != nil {
+		http.Error(w, "failed to load sessions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, sessions)
+}
+
+// Revoke signs one session out. Revoking the current session is the same as
+// logging out.
+func (h *SessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "id")
+	err := h.service.Revoke(r.Context(), user.UserID, sessionID)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+
+	if sessionID == user.SessionID && middleware.UsesCookieAuth(r) {
+		h.cookies.Clear(w)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeOthers signs the user out everywhere except the current session
+func (h *SessionHandler) RevokeOthers(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	n, err := h.service.RevokeOthers(r.Context(), user.UserID, user.SessionID)
+	if err != nil {
+		http.Error(w, "failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]int64{"revoked": n})
+}
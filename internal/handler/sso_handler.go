@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/oidc"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+type SSOHandler struct {
+	service *service.SSOService
+	// callbackPage is the frontend page that picks the tokens up from the
+	// URL fragment after a login.
+	callbackPage string
+	cookies      *SessionCookies
+}
+
+func NewSSOHandler(s *service.SSOService, callbackPage string, cookies *SessionCookies) *SSOHandler {
+	return &SSOHandler{service: s, callbackPage: callbackPage, cookies: cookies}
+}
+
+// Login redirects the browser to the identity provider
+func (h *SSOHandler) Login(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := h.service.Begin(r.Context(), safeRedirect(r.URL.Query().Get("redirect")))
+	if errors.Is(err, oidc.ErrNotConfigured) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("[SSO] begin failed:", err)
+		http.Error(w, "single sign-on is unavailable", http.StatusBadGateway)
+		return
+	}
+
+	h.cookies.SetOIDCState(w, state, service.OIDCLoginStateTTL)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback finishes the login and hands the result to the frontend in the
+// URL fragment, which browsers never send to a server. In cookie mode the
+// tokens go into session cookies instead. Only the browser that started the
+// login may finish it.
+func (h *SSOHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	frag := url.Values{}
+
+	stateOK := h.cookies.OIDCStateMatches(r, q.Get("state"))
+	h.cookies.ClearOIDCState(w)
+
+	if idpErr := q.Get("error"); idpErr != "" {
+		frag.Set("error", idpErr)
+		h.finish(w, r, frag)
+		return
+	}
+	if !stateOK {
+		log.Println("[SSO] callback state does not match this browser's login")
+		frag.Set("error", service.ErrSSOLoginFailed.Error())
+		h.finish(w, r, frag)
+		return
+	}
+
+	result, redirectTo, err := h.service.Callback(r.Context(), q.Get("state"), q.Get("code"), sessionMeta(r))
+	if redirectTo != "" {
+		frag.Set("redirect", redirectTo)
+	}
+	if err != nil {
+		log.Println("[SSO] callback failed:", err)
+		switch {
+		case errors.Is(err, service.ErrSSOEmailMissing), errors.Is(err, service.ErrSSORoleNotAllowed):
+			frag.Set("error", err.Error())
+		default:
+			frag.Set("error", service.ErrSSOLoginFailed.Error())
+		}
+		h.finish(w, r, frag)
+		return
+	}
+
+	resp := loginResponse(result)
+	frag.Set("role", resp.Role)
+	if resp.MFARequired {
+		frag.Set("mfa_token", resp.MFAToken)
+	} else if h.cookies.Enabled() {
+		if err := h.cookies.SetSession(w, resp.Token, resp.RefreshToken); err != nil {
+			log.Println("[SSO] set session cookies failed:", err)
+			frag.Set("error", service.ErrSSOLoginFailed.Error())
+			h.finish(w, r, frag)
+			return
+		}
+		frag.Set("auth_mode", "cookie")
+		frag.Set("roles", strings.Join(resp.Roles, ","))
+	} else {
+		frag.Set("token", resp.Token)
+		frag.Set("refresh_token", resp.RefreshToken)
+		frag.Set("expires_in", strconv.Itoa(resp.ExpiresIn))
+	}
+	if resp.MFAEnrollmentRequired {
+		frag.Set("mfa_enrollment_required", "true")
+	}
+	h.finish(w, r, frag)
+}
+
+func (h *SSOHandler) finish(w http.ResponseWriter, r *http.Request, frag url.Values) {
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, h.callbackPage+"#"+frag.Encode(), http.StatusFound)
+}
+
+// safeRedirect only allows same-site paths, so the login flow cannot be used
+// as an open redirect
+func safeRedirect(p string) string {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.Contains(p, "\\") {
+		return ""
+	}
+	return p
+}
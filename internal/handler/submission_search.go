@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// queryList reads a parameter given several times or comma-separated.
+func queryList(r *http.Request, name string) []string {
+	var list []string
+	for _, v := range r.URL.Query()[name] {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// optionalUUID reads a uuid parameter that may be left out.
+func optionalUUID(r *http.Request, name string) (*string, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	if _, err := uuid.Parse(v); err != nil {
+		return nil, errors.New("invalid " + name)
+	}
+	return &v, nil
+}
+
+// parseSearch reads a submission search from the query string: q, status
+// and tag, domain, from and to as inclusive dates, faculty_id, cycle_id,
+// limit and offset. faculty_id=me stands for the caller.
+func parseSearch(r *http.Request, userID string) (model.SubmissionSearch, error) {
+	q := r.URL.Query()
+	f := model.SubmissionSearch{
+		Query:    strings.TrimSpace(q.Get("q")),
+		Statuses: queryList(r, "status"),
+		Tags:     queryList(r, "tag"),
+		Domain:   strings.TrimSpace(q.Get("domain")),
+		Limit:    defaultSearchLimit,
+	}
+
+	if v := q.Get("from"); v != "" {
+		from, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return f, errors.New("from must be a date like 2026-01-31")
+		}
+		f.From = &from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return f, errors.New("to must be a date like 2026-01-31")
+		}
+		to = to.AddDate(0, 0, 1)
+		f.To = &to
+	}
+
+	var err error
+	if q.Get("faculty_id") == "me" {
+		f.FacultyID = &userID
+	} else if f.FacultyID, err = optionalUUID(r, "faculty_id"); err != nil {
+		return f, err
+	}
+	if f.CycleID, err = optionalUUID(r, "cycle_id"); err != nil {
+		return f, err
+	}
+
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 1 || f.Limit > maxSearchLimit {
+			return f, errors.New("limit must be between 1 and 100")
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil || f.Offset < 0 {
+			return f, errors.New("offset must not be negative")
+		}
+	}
+	return f, nil
+}
+
+// SearchSubmissions runs a ranked full-text search over every submission
+// that is not a draft
+func (h *AdminSubmissionHandler) SearchSubmissions(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	f, err := parseSearch(r, user.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.repo.Search(r.Context(), f)
+	if err != nil {
+		log.Println("[SEARCH]", err)
+		http.Error(w, "failed to search submissions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, results)
+}
+
+// Search runs the same search over the submissions reviewers can see
+func (h *FacultyReviewHandler) Search(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	f, err := parseSearch(r, user.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.service.Search(r.Context(), f)
+	if err != nil {
+		log.Println("[SEARCH]", err)
+		http.Error(w, "failed to search submissions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, results)
+}
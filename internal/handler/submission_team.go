@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+	"github.com/rudraa2005/mic-website-main/backend/internal/service"
+)
+
+type TeamInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type TeamRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type AcceptTeamInviteRequest struct {
+	Token string `json:"token"`
+}
+
+// writeTeamError maps submission team errors to responses.
+func writeTeamError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrSubmissionNotFound),
+		errors.Is(err, repository.ErrMemberNotFound),
+		errors.Is(err, repository.ErrTeamInviteNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrNotSubmissionOwner):
+		writeJSONError(w, http.StatusForbidden, "NOT_OWNER", err.Error())
+	case errors.Is(err, service.ErrInviteEmailMismatch):
+		writeJSONError(w, http.StatusForbidden, "INVITE_EMAIL_MISMATCH", err.Error())
+	case errors.Is(err, service.ErrInvalidInvite):
+		writeJSONError(w, http.StatusGone, "INVALID_INVITE", err.Error())
+	case errors.Is(err, service.ErrAlreadyOnTeam):
+		writeJSONError(w, http.StatusConflict, "ALREADY_ON_TEAM", err.Error())
+	case errors.Is(err, service.ErrOwnerCannotLeave):
+		writeJSONError(w, http.StatusConflict, "OWNER_CANNOT_LEAVE", err.Error())
+	case errors.Is(err, service.ErrInvalidMemberRole), errors.Is(err, service.ErrInvalidEmail):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Println("[TEAM]", err)
+		http.Error(w, "failed to update team", http.StatusInternalServerError)
+	}
+}
+
+// Members lists the team of one of the student's submissions
+func (sh *SubmissionsHandler) Members(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	members, err := sh.teamService.Members(r.Context(), chi.URLParam(r, "submission_id"), user.UserID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeJSON(w, members)
+}
+
+// InviteMember emails a co-founder a link to join the submission
+func (sh *SubmissionsHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req TeamInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	inv, err := sh.teamService.Invite(r.Context(), chi.URLParam(r, "submission_id"), req.Email, req.Role, user)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(inv)
+}
+
+func (sh *SubmissionsHandler) Invites(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	invites, err := sh.teamService.Invites(r.Context(), chi.URLParam(r, "submission_id"), user.UserID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeJSON(w, invites)
+}
+
+func (sh *SubmissionsHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err := sh.teamService.RevokeInvite(r.Context(), chi.URLParam(r, "submission_id"), chi.URLParam(r, "invite_id"), user.UserID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetMemberRole switches a co-founder between editor and viewer
+func (sh *SubmissionsHandler) SetMemberRole(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req TeamRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	err := sh.teamService.SetRole(r.Context(), chi.URLParam(r, "submission_id"), chi.URLParam(r, "user_id"), req.Role, user.UserID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeJSON(w, map[string]string{"role": req.Role})
+}
+
+// RemoveMember takes a co-founder off the team, or lets them leave it
+func (sh *SubmissionsHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err := sh.teamService.Remove(r.Context(), chi.URLParam(r, "submission_id"), chi.URLParam(r, "user_id"), user.UserID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AcceptInvite joins the team an emailed invite link was sent for
+func (sh *SubmissionsHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req AcceptTeamInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
+		return
+	}
+
+	inv, err := sh.teamService.Accept(r.Context(), req.Token, user)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+	writeJSON(w, inv)
+}
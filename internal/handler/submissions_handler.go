@@ -2,33 +2,36 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/rudraa2005/mic-website-main/backend/internal/middleware"
 	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
 	"github.com/rudraa2005/mic-website-main/backend/internal/service"
 )
 
 type SubmissionsHandler struct {
 	submissionsService *service.SubmissionsService
+	teamService        *service.SubmissionTeamService
 }
 type CreateSubmissionRequest struct {
-	SubmissionID string  `json:"submission_id,omitempty"`
-	Title        string  `json:"title"`
-	Description  string  `json:"description"`
-	FilePath     *string `json:"file_path"`
+	SubmissionID string         `json:"submission_id,omitempty"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	FilePath     *string        `json:"file_path"`
+	CycleID      *string        `json:"cycle_id"`
+	Answers      map[string]any `json:"answers"`
 }
 
-func NewSubmissionsHandler(ss *service.SubmissionsService) *SubmissionsHandler {
+func NewSubmissionsHandler(ss *service.SubmissionsService, team *service.SubmissionTeamService) *SubmissionsHandler {
 	return &SubmissionsHandler{
 		submissionsService: ss,
+		teamService:        team,
 	}
 }
 
@@ -39,11 +42,18 @@ type SubmissionResponse struct {
 	Status       string    `json:"status"`
 	FilePath     *string   `json:"file_path"`
 	CreatedAt    time.Time `json:"created_at"`
+	Role         string    `json:"role"`
+	CycleID      *string   `json:"cycle_id"`
+	CycleName    *string   `json:"cycle_name"`
 }
 type SubmitSubmissionRequest struct {
 	SubmissionID string `json:"submission_id"`
 }
 
+type ResubmitRequest struct {
+	Response string `json:"response"`
+}
+
 func (sh *SubmissionsHandler) CreateSubmission(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -65,9 +75,13 @@ func (sh *SubmissionsHandler) CreateSubmission(w http.ResponseWriter, r *http.Re
 		Title:        req.Title,
 		Description:  req.Description,
 		Status:       "draft",
+		CycleID:      cycleID(req.CycleID),
 	}
 
-	if err := sh.submissionsService.Create(ctx, submission); err != nil {
+	if err := sh.submissionsService.Create(ctx, submission, user); err != nil {
+		if writeCycleError(w, err) {
+			return
+		}
 		log.Println("CREATE SUBMISSION ERROR:", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -109,9 +123,14 @@ func (sh *SubmissionsHandler) UpdateSubmission(w http.ResponseWriter, r *http.Re
 		UserID:       user.UserID,
 		Title:        req.Title,
 		Description:  req.Description,
+		CycleID:      cycleID(req.CycleID),
+		Answers:      req.Answers,
 	}
 	log.Println("UpdateSubmission: updating submission for userID:", user.UserID, submission)
 	if err := sh.submissionsService.UpdateDraft(ctx, submission); err != nil {
+		if writeCycleError(w, err) || writeAnswersError(w, err) {
+			return
+		}
 		http.Error(w, "failed to update submission", http.StatusInternalServerError)
 		return
 	}
@@ -123,6 +142,40 @@ func (sh *SubmissionsHandler) UpdateSubmission(w http.ResponseWriter, r *http.Re
 	})
 }
 
+// cycleID treats an empty cycle_id like a missing one.
+func cycleID(id *string) *string {
+	if id == nil || *id == "" {
+		return nil
+	}
+	return id
+}
+
+// OpenCycles lists the application cycles accepting submissions now
+func (sh *SubmissionsHandler) OpenCycles(w http.ResponseWriter, r *http.Request) {
+	cycles, err := sh.submissionsService.OpenCycles(r.Context())
+	if err != nil {
+		log.Println("[CYCLES]", err)
+		http.Error(w, "failed to load application cycles", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, cycles)
+}
+
+// CycleForm returns the questions new drafts of a cycle have to answer
+func (sh *SubmissionsHandler) CycleForm(w http.ResponseWriter, r *http.Request) {
+	form, err := sh.submissionsService.CycleForm(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, repository.ErrFormNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("[FORMS]", err)
+		http.Error(w, "failed to load application form", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, form)
+}
+
 func (sh *SubmissionsHandler) GetByUserID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -151,6 +204,9 @@ func (sh *SubmissionsHandler) GetByUserID(w http.ResponseWriter, r *http.Request
 			Status:       s.Status,
 			FilePath:     filePath,
 			CreatedAt:    s.CreatedAt,
+			Role:         s.Role,
+			CycleID:      s.CycleID,
+			CycleName:    s.CycleName,
 		})
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -160,14 +216,18 @@ func (sh *SubmissionsHandler) GetByUserID(w http.ResponseWriter, r *http.Request
 func (sh *SubmissionsHandler) GetBySubmissionID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	_, ok := middleware.GetUserFromContext(ctx)
+	user, ok := middleware.GetUserFromContext(ctx)
 	if !ok {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 	submissions := chi.URLParam(r, "submission_id")
 
-	submission, err := sh.submissionsService.GetBySubmissionID(ctx, submissions)
+	submission, err := sh.submissionsService.GetBySubmissionID(ctx, submissions, user.UserID)
+	if errors.Is(err, repository.ErrSubmissionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, "failed to get submission", http.StatusInternalServerError)
 		return
@@ -218,12 +278,9 @@ func (sh *SubmissionsHandler) SubmitSubmission(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	err := sh.submissionsService.Submit(ctx, submissionID, userID, user.Email)
+	err := sh.submissionsService.Submit(ctx, submissionID, user)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": err.Error(),
-		})
+		writeWorkflowError(w, err)
 		return
 	}
 
@@ -233,105 +290,233 @@ func (sh *SubmissionsHandler) SubmitSubmission(w http.ResponseWriter, r *http.Re
 	})
 }
 
-func (sh *SubmissionsHandler) UploadSubmissionFile(w http.ResponseWriter, r *http.Request) {
-	log.Println("UPLOAD route hit")
-	ctx := r.Context()
+// Reopen makes a submission that needs improvement editable again
+func (sh *SubmissionsHandler) Reopen(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
 
-	user, ok := middleware.GetUserFromContext(ctx)
+	err := sh.submissionsService.Reopen(r.Context(), chi.URLParam(r, "submission_id"), user)
+	if err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+	writeJSON(w, map[string]string{"status": model.SubmissionRevising})
+}
+
+// Resubmit sends a revised submission back to its reviewers with the
+// student's response to their feedback
+func (sh *SubmissionsHandler) Resubmit(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
-	if user.UserID == "" {
-		log.Println("UPLOAD: user ID missing in context")
-		http.Error(w, "unauthorized: user ID missing", http.StatusUnauthorized)
+
+	var req ResubmitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid Request", http.StatusBadRequest)
 		return
 	}
-	log.Println("UPLOAD: userID =", user.UserID)
 
-	submissionID := chi.URLParam(r, "submission_id")
-	log.Println("UPLOAD: submissionID =", submissionID)
+	err := sh.submissionsService.Resubmit(r.Context(), chi.URLParam(r, "submission_id"), user, req.Response)
+	if err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+	writeJSON(w, map[string]string{"status": model.SubmissionAdminApproved})
+}
 
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		http.Error(w, "invalid multipart form", http.StatusBadRequest)
+// Timeline returns the status history of one of the student's submissions
+func (sh *SubmissionsHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	file, header, err := r.FormFile("file")
+	history, err := sh.submissionsService.Timeline(r.Context(), chi.URLParam(r, "submission_id"), user.UserID)
 	if err != nil {
-		log.Println("UPLOAD: form file missing:", err)
-		http.Error(w, "file missing", http.StatusBadRequest)
+		writeWorkflowError(w, err)
 		return
 	}
-	defer file.Close()
-	log.Println("UPLOAD: received file:", header.Filename)
+	writeJSON(w, history)
+}
 
-	os.MkdirAll("./uploads", os.ModePerm)
-	path := fmt.Sprintf("./uploads/%s_%s", submissionID, header.Filename)
+// Revisions lists the saved versions of one of the student's submissions
+func (sh *SubmissionsHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
 
-	dst, err := os.Create(path)
+	revisions, err := sh.submissionsService.Revisions(r.Context(), chi.URLParam(r, "submission_id"), user.UserID)
 	if err != nil {
-		log.Println("UPLOAD: failed to create file:", err)
-		http.Error(w, "failed to save file", http.StatusInternalServerError)
+		writeRevisionError(w, err)
+		return
+	}
+	writeJSON(w, revisions)
+}
+
+func (sh *SubmissionsHandler) Revision(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	number, ok := revisionNumber(r)
+	if !ok {
+		http.Error(w, "invalid revision number", http.StatusBadRequest)
 		return
 	}
 
-	defer dst.Close()
-	_, err = io.Copy(dst, file)
+	revision, err := sh.submissionsService.Revision(r.Context(), chi.URLParam(r, "submission_id"), user.UserID, number)
 	if err != nil {
-		log.Println("UPLOAD: failed to save file:", err)
-		http.Error(w, "failed to save file", http.StatusInternalServerError)
+		writeRevisionError(w, err)
 		return
 	}
-	log.Println("UPLOAD: file saved to:", path)
+	writeJSON(w, revision)
+}
 
-	err = sh.submissionsService.AttachFile(ctx, submissionID, user.UserID, path)
+// RevisionDiff compares two revisions given as ?from=&to=
+func (sh *SubmissionsHandler) RevisionDiff(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	from, to, ok := revisionRange(r)
+	if !ok {
+		http.Error(w, service.ErrInvalidRevisionRange.Error(), http.StatusBadRequest)
+		return
+	}
+
+	diff, err := sh.submissionsService.RevisionDiff(r.Context(), chi.URLParam(r, "submission_id"), user.UserID, from, to)
 	if err != nil {
-		log.Println("UPLOAD: failed to attach file to submission:", err)
-		http.Error(w, "failed to attach file", http.StatusInternalServerError)
+		writeRevisionError(w, err)
 		return
 	}
+	writeJSON(w, diff)
+}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"file_path": path,
-	})
+// FileSlots lists the kinds of attachment submissions of ?cycle_id= carry
+func (sh *SubmissionsHandler) FileSlots(w http.ResponseWriter, r *http.Request) {
+	cycleID, ok := cycleFilter(r)
+	if !ok {
+		http.Error(w, "invalid cycle_id", http.StatusBadRequest)
+		return
+	}
+	slots, err := sh.submissionsService.FileSlots(r.Context(), cycleID)
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+	writeJSON(w, slots)
 }
-func (sh *SubmissionsHandler) DownloadSubmissionFile(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
 
-	log.Println("DOWNLOAD route hit")
-	user, ok := middleware.GetUserFromContext(ctx)
+// SubmissionFileSlots lists the slots of one of the student's submissions
+func (sh *SubmissionsHandler) SubmissionFileSlots(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
 	if !ok {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	submissionID := chi.URLParam(r, "submission_id")
-	if submissionID == "" {
-		http.Error(w, "submission_id missing", http.StatusBadRequest)
+	slots, err := sh.submissionsService.SubmissionFileSlots(r.Context(), chi.URLParam(r, "submission_id"), user.UserID)
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+	writeJSON(w, slots)
+}
+
+// Files lists the attachments of one of the student's submissions
+func (sh *SubmissionsHandler) Files(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	files, err := sh.submissionsService.Files(r.Context(), chi.URLParam(r, "submission_id"), user.UserID)
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+	writeJSON(w, files)
+}
+
+// UploadFile stores the multipart "file" field in the {slot} slot, replacing
+// any file already there
+func (sh *SubmissionsHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize+1<<20)
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		http.Error(w, "invalid multipart form or file too large", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file missing", http.StatusBadRequest)
 		return
 	}
+	defer file.Close()
 
-	submission, err := sh.submissionsService.GetBySubmissionID(ctx, submissionID)
+	f, err := sh.submissionsService.UploadFile(
+		r.Context(),
+		chi.URLParam(r, "submission_id"),
+		chi.URLParam(r, "slot"),
+		user,
+		header.Filename,
+		file,
+	)
 	if err != nil {
-		http.Error(w, "submission not found", http.StatusNotFound)
+		writeFileError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(f)
+}
+
+func (sh *SubmissionsHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	// ownership check
-	if submission.UserID != user.UserID && user.Role != "FACULTY" && user.Role != "ADMIN" {
-		http.Error(w, "forbidden", http.StatusForbidden)
+	f, body, err := sh.submissionsService.OpenFile(r.Context(), chi.URLParam(r, "submission_id"), user.UserID, chi.URLParam(r, "file_id"))
+	if err != nil {
+		writeFileError(w, err)
 		return
 	}
+	serveSubmissionFile(w, r, f, body)
+}
 
-	if submission.FilePath == nil {
-		http.Error(w, "no file attached", http.StatusNotFound)
+func (sh *SubmissionsHandler) DeleteFile(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	http.ServeFile(w, r, *submission.FilePath)
+	err := sh.submissionsService.DeleteFile(r.Context(), chi.URLParam(r, "submission_id"), chi.URLParam(r, "file_id"), user)
+	if err != nil {
+		writeFileError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (sh *SubmissionsHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
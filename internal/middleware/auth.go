@@ -2,9 +2,14 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"slices"
 	"strings"
 
+	chimw "github.com/go-chi/chi/v5/middleware"
 	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
 )
 
@@ -12,20 +17,97 @@ type contextKey string
 
 const userContextKey contextKey = "authenticatedUser"
 
-func AuthMiddleware(next http.Handler) http.Handler {
+// SessionStore lets the middleware check that the session behind an access
+// token has not been revoked, and record when it was last used.
+type SessionStore interface {
+	IsActive(ctx context.Context, sessionID string) (bool, error)
+	Touch(ctx context.Context, sessionID, ip string) error
+}
+
+// MFAPolicyStore tells RequireMFA which roles must have a second factor.
+type MFAPolicyStore interface {
+	IsRequired(ctx context.Context, role string) (bool, error)
+}
+
+// PermissionStore tells RequirePermission what the user's roles grant.
+type PermissionStore interface {
+	HasPermission(ctx context.Context, roles []string, permission string) (bool, error)
+}
+
+// AccessTokenStore resolves personal access tokens. It returns
+// auth.ErrInvalidAccessToken for unknown, expired or revoked tokens.
+type AccessTokenStore interface {
+	Authenticate(ctx context.Context, token string) (*auth.Claims, error)
+}
+
+// ImpersonationStore checks that an impersonation is still running and
+// records the requests made under it.
+type ImpersonationStore interface {
+	IsActive(ctx context.Context, id string) (bool, error)
+	LogRequest(ctx context.Context, id, method, path string, status int) error
+}
+
+type Auth struct {
+	sessions       SessionStore
+	mfaPolicies    MFAPolicyStore
+	permissions    PermissionStore
+	accessTokens   AccessTokenStore
+	impersonations ImpersonationStore
+}
+
+func NewAuth(sessions SessionStore, mfaPolicies MFAPolicyStore, permissions PermissionStore, accessTokens AccessTokenStore, impersonations ImpersonationStore) *Auth {
+	return &Auth{
+		sessions:       sessions,
+		mfaPolicies:    mfaPolicies,
+		permissions:    permissions,
+		accessTokens:   accessTokens,
+		impersonations: impersonations,
+	}
+}
+
+func (a *Auth) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
+		ctx := r.Context()
+		var tokenString string
+		if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+			parts := strings.Fields(authHeader)
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				http.Error(w, "invalid", http.StatusUnauthorized)
+				return
+			}
+			tokenString = parts[1]
+		} else if c, err := r.Cookie(AccessCookieName); err == nil && c.Value != "" {
+			// Personal access tokens are never put in cookies
+			if strings.HasPrefix(c.Value, auth.AccessTokenPrefix) {
+				http.Error(w, "invalid", http.StatusUnauthorized)
+				return
+			}
+			if !validCSRF(r) {
+				writeForbidden(w, "CSRF_FAILED", "missing or invalid CSRF token")
+				return
+			}
+			tokenString = c.Value
+			ctx = withCookieAuth(ctx)
+		} else {
 			http.Error(w, "missing authorization header", http.StatusUnauthorized)
 			return
 		}
-		parts := strings.Fields(authHeader)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "invalid", http.StatusUnauthorized)
+
+		if strings.HasPrefix(tokenString, auth.AccessTokenPrefix) {
+			claims, err := a.accessTokens.Authenticate(r.Context(), tokenString)
+			if errors.Is(err, auth.ErrInvalidAccessToken) {
+				http.Error(w, "invalid or expired", http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				log.Println("[AUTH] access token lookup failed:", err)
+				http.Error(w, "failed to verify access token", http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(SetUserInContext(r.Context(), claims)))
 			return
 		}
-		tokenString := parts[1]
 
 		claims := &auth.Claims{}
 		parsedClaims, err := auth.ParseJWT(tokenString, claims)
@@ -34,7 +116,160 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), userContextKey, parsedClaims)
+		// MFA challenge tokens only unlock the second login step
+		if parsedClaims.SessionID == "" || parsedClaims.Purpose != "" {
+			http.Error(w, "invalid or expired", http.StatusUnauthorized)
+			return
+		}
+		active, err := a.sessions.IsActive(r.Context(), parsedClaims.SessionID)
+		if err != nil {
+			log.Println("[AUTH] session lookup failed:", err)
+			http.Error(w, "failed to verify session", http.StatusInternalServerError)
+			return
+		}
+		if !active {
+			http.Error(w, "session revoked", http.StatusUnauthorized)
+			return
+		}
+		if err := a.sessions.Touch(r.Context(), parsedClaims.SessionID, ClientIP(r)); err != nil {
+			log.Println("[AUTH] failed to record session activity:", err)
+		}
+
+		ctx = context.WithValue(ctx, userContextKey, parsedClaims)
+		if parsedClaims.IsImpersonating() {
+			a.serveImpersonated(w, r.WithContext(ctx), parsedClaims, next)
+			return
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// serveImpersonated runs a request made with an impersonation token,
+// refusing writes when the token is read-only, and writes it to the audit
+// log whatever the outcome.
+func (a *Auth) serveImpersonated(w http.ResponseWriter, r *http.Request, claims *auth.Claims, next http.Handler) {
+	active, err := a.impersonations.IsActive(r.Context(), claims.ImpersonationID)
+	if err != nil {
+		log.Println("[AUTH] impersonation lookup failed:", err)
+		http.Error(w, "failed to verify impersonation", http.StatusInternalServerError)
+		return
+	}
+	if !active {
+		http.Error(w, "impersonation ended", http.StatusUnauthorized)
+		return
+	}
+
+	ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+	defer func() {
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		err := a.impersonations.LogRequest(context.WithoutCancel(r.Context()), claims.ImpersonationID, r.Method, r.URL.RequestURI(), status)
+		if err != nil {
+			log.Println("[AUTH] failed to record impersonated request:", err)
+		}
+	}()
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		if claims.ReadOnly {
+			writeForbidden(ww, "IMPERSONATION_READ_ONLY", "this impersonation is read-only")
+			return
+		}
+	}
+
+	next.ServeHTTP(ww, r)
+}
+
+// RequireMFA blocks tokens without the mfa claim when the MFA policy makes a
+// second factor mandatory for any of the user's roles. Must run after AuthMiddleware.
+func (a *Auth) RequireMFA(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := GetUser(r)
+		if err != nil {
+			http.Error(w, "Not Authorized", http.StatusForbidden)
+			return
+		}
+
+		if !user.MFA {
+			required := false
+			for _, role := range user.RoleSet() {
+				required, err = a.mfaPolicies.IsRequired(r.Context(), role)
+				if err != nil {
+					log.Println("[AUTH] mfa policy lookup failed:", err)
+					http.Error(w, "failed to check two-factor policy", http.StatusInternalServerError)
+					return
+				}
+				if required {
+					break
+				}
+			}
+			if required {
+				writeForbidden(w, "MFA_REQUIRED", "two-factor authentication is required for this action")
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission lets the request through when one of the user's roles
+// grants permission. Must run after AuthMiddleware.
+func (a *Auth) RequirePermission(permission string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := GetUser(r)
+			if err != nil {
+				http.Error(w, "Not Authorized", http.StatusForbidden)
+				return
+			}
+
+			allowed, err := a.permissions.HasPermission(r.Context(), user.RoleSet(), permission)
+			if err != nil {
+				log.Println("[AUTH] permission lookup failed:", err)
+				http.Error(w, "failed to check permissions", http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				writeForbidden(w, "PERMISSION_DENIED", "you do not have permission to do this")
+				return
+			}
+			if user.IsPersonalAccessToken() && !slices.Contains(user.Scopes, permission) {
+				writeForbidden(w, "INSUFFICIENT_SCOPE", "this access token's scopes do not include "+permission)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession refuses personal access tokens and impersonation tokens,
+// for routes that manage the account itself such as sessions, 2FA and the
+// tokens themselves. Must run after AuthMiddleware.
+func (a *Auth) RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := GetUser(r)
+		if err != nil {
+			http.Error(w, "Not Authorized", http.StatusForbidden)
+			return
+		}
+		if user.IsPersonalAccessToken() || user.IsImpersonating() {
+			writeForbidden(w, "SESSION_REQUIRED", "this action needs your own signed-in session")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeForbidden(w http.ResponseWriter, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": msg,
+		"code":  code,
+	})
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter allows a fixed number of requests per client IP within a
+// window. Counters live in memory and reset when the process restarts.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*rateWindow),
+	}
+}
+
+// Allow records a request for key and reports whether it is within the limit,
+// along with how long to wait if it is not.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.clients) > 10000 {
+		for k, w := range l.clients {
+			if now.Sub(w.start) > l.window {
+				delete(l.clients, k)
+			}
+		}
+	}
+
+	w, ok := l.clients[key]
+	if !ok || now.Sub(w.start) > l.window {
+		l.clients[key] = &rateWindow{start: now, count: 1}
+		return true, 0
+	}
+
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.admit(w, ClientIP(r)) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitUser is Limit keyed on the signed-in user, so guessing codes for one
+// account cannot be spread across addresses. It must run after AuthMiddleware.
+func (l *RateLimiter) LimitUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := "ip:" + ClientIP(r)
+		if claims, ok := GetUserFromContext(r.Context()); ok {
+			key = "user:" + claims.UserID
+		}
+		if !l.admit(w, key) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l *RateLimiter) admit(w http.ResponseWriter, key string) bool {
+	ok, retryAfter := l.Allow(key)
+	if !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		http.Error(w, "too many requests, try again later", http.StatusTooManyRequests)
+	}
+	return ok
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP returns the address of the client that sent the request, without
+// the port.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
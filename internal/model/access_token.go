@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// PersonalAccessToken is a token as its owner sees it. MFAVerified records
+// that it was created from a session that had passed 2FA.
+type PersonalAccessToken struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"-"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	MFAVerified bool       `json:"mfa_verified"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+}
+
+// AccessTokenOwner is the token row joined with the user it acts for.
+type AccessTokenOwner struct {
+	TokenID     string
+	Scopes      []string
+	MFAVerified bool
+	User
+}
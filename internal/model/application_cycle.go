@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// ApplicationCycle is an intake students submit ideas to. Submitting is only
+// possible between OpensAt and ClosesAt; ReviewDeadline is when reviewers
+// are expected to have decided.
+type ApplicationCycle struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	OpensAt        time.Time  `json:"opens_at"`
+	ClosesAt       time.Time  `json:"closes_at"`
+	ReviewDeadline *time.Time `json:"review_deadline"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// IsOpen reports whether the cycle accepts submissions at t.
+func (c *ApplicationCycle) IsOpen(t time.Time) bool {
+	return !t.Before(c.OpensAt) && t.Before(c.ClosesAt)
+}
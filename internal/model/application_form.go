@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Form field types.
+const (
+	FieldText        = "text"
+	FieldTextarea    = "textarea"
+	FieldNumber      = "number"
+	FieldSelect      = "select"
+	FieldMultiSelect = "multiselect"
+	FieldCheckbox    = "checkbox"
+	FieldDate        = "date"
+	FieldURL         = "url"
+	FieldEmail       = "email"
+)
+
+// FormField is one question of an application form. Min and Max bound the
+// value of a number, the length of text and the number of choices of a
+// multiselect.
+type FormField struct {
+	Key       string         `json:"key"`
+	Label     string         `json:"label"`
+	Type      string         `json:"type"`
+	Help      string         `json:"help,omitempty"`
+	Required  bool           `json:"required"`
+	Options   []string       `json:"options,omitempty"`
+	Min       *float64       `json:"min,omitempty"`
+	Max       *float64       `json:"max,omitempty"`
+	Pattern   string         `json:"pattern,omitempty"`
+	VisibleIf *FormCondition `json:"visible_if,omitempty"`
+}
+
+// FormCondition shows a field only when the answer to an earlier field is
+// one of Values. Checkbox answers compare as "true" or "false", and a
+// multiselect matches when any of its choices does.
+type FormCondition struct {
+	Field  string   `json:"field"`
+	Values []string `json:"values"`
+}
+
+// ApplicationForm is one published version of a cycle's questions.
+type ApplicationForm struct {
+	ID        string      `json:"id"`
+	CycleID   string      `json:"cycle_id"`
+	Version   int         `json:"version"`
+	Fields    []FormField `json:"fields"`
+	CreatedBy *string     `json:"created_by"`
+	CreatedAt time.Time   `json:"created_at"`
+}
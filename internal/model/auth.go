@@ -1,9 +1,15 @@
 package model
 
+import "time"
+
 type User struct {
 	Email          string
 	HashedPassword string
-	Role           string
-	UserID         string
-	Name           string
+	// Role is the default role a new session acts as. Roles holds every
+	// role the user may act as, Role included.
+	Role            string
+	Roles           []string
+	UserID          string
+	Name            string
+	EmailVerifiedAt *time.Time
 }
@@ -1,9 +0,0 @@
-package model
-
-type Faculty struct {
-	ID           string
-	Name         string
-	Email        string
-	PasswordHash string
-	Role         string
-}
@@ -18,6 +18,10 @@ type Feedback struct {
 	Rating float32 `json:"rating"`
 	Status string  `json:"status"`
 
+	// RevisionID is the submitted revision the feedback was written against.
+	RevisionID     *string `json:"revision_id"`
+	RevisionNumber *int    `json:"revision_number"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
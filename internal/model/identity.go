@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// OIDCLoginState is an authorization request waiting for its callback.
+type OIDCLoginState struct {
+	Nonce        string
+	CodeVerifier string
+	RedirectTo   string
+	ExpiresAt    time.Time
+}
+
+// UserIdentity links a local account to a subject at an identity provider.
+type UserIdentity struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Issuer      string    `json:"issuer"`
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
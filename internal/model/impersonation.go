@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+type Impersonation struct {
+	ID           string     `json:"id"`
+	AdminID      string     `json:"admin_id"`
+	AdminEmail   string     `json:"admin_email,omitempty"`
+	UserID       string     `json:"user_id"`
+	UserEmail    string     `json:"user_email,omitempty"`
+	SessionID    string     `json:"-"`
+	Reason       string     `json:"reason"`
+	ReadOnly     bool       `json:"read_only"`
+	StartedAt    time.Time  `json:"started_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	EndedAt      *time.Time `json:"ended_at"`
+	RequestCount int        `json:"request_count"`
+}
+
+type ImpersonationRequest struct {
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type UserMFA struct {
+	UserID       string
+	TOTPSecret   string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+}
+
+type MFAPolicy struct {
+	Role      string    `json:"role"`
+	Required  bool      `json:"required"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
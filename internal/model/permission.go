@@ -0,0 +1,13 @@
+package model
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// RolePermissions is a role together with the permissions it grants.
+type RolePermissions struct {
+	Role        string   `json:"role"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
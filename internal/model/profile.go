@@ -8,6 +8,7 @@ type Profile struct {
 	Email     string    `json:"email"`
 	Phone     *string   `json:"phone,omitempty"`
 	PhotoURL  *string   `json:"photo_url,omitempty"`
+	PhotoSize int64     `json:"-"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Bio       string    `json:"bio"`
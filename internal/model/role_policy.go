@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// RoleDomainRule assigns a role to new accounts whose email is at Domain.
+type RoleDomainRule struct {
+	Domain    string    `json:"domain"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RoleEmailOverride assigns a role to one email address, ahead of any rule.
+type RoleEmailOverride struct {
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RoleInvite struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	InvitedBy  *string    `json:"invited_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
+
+// RoleAuditEntry records one change to a user's role.
+type RoleAuditEntry struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	PreviousRole string    `json:"previous_role"`
+	NewRole      string    `json:"new_role"`
+	ChangedBy    *string   `json:"changed_by,omitempty"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+type Session struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	UserAgent   string     `json:"user_agent"`
+	IPAddress   string     `json:"ip_address"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RefreshedAt time.Time  `json:"refreshed_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	MFAVerified bool       `json:"mfa_verified"`
+	// ActiveRole is the role the session acts as, chosen with a role switch.
+	ActiveRole string    `json:"active_role"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	LastSeenIP string    `json:"last_seen_ip"`
+}
+
+// ActiveSession is a session as shown to its owner in settings.
+type ActiveSession struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	LastSeenIP string    `json:"last_seen_ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Current marks the session the request was made with.
+	Current bool `json:"current"`
+}
+
+// SessionMeta describes the client a session is created for.
+type SessionMeta struct {
+	UserAgent string
+	IPAddress string
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// SubmissionFileSlot is a kind of attachment a submission can carry, such as
+// a pitch deck. Required slots must be filled before submitting. Uploads must
+// have one of AllowedTypes, or any type when it is empty. Slots without a
+// CycleID are the default set, used by cycles that have no slots of their own.
+type SubmissionFileSlot struct {
+	Key          string    `json:"key"`
+	CycleID      *string   `json:"cycle_id"`
+	Label        string    `json:"label"`
+	Description  string    `json:"description"`
+	Required     bool      `json:"required"`
+	Position     int       `json:"position"`
+	AllowedTypes []string  `json:"allowed_types"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SubmissionFile is the file held in one slot of a submission.
+type SubmissionFile struct {
+	ID           string    `json:"id"`
+	SubmissionID string    `json:"submission_id"`
+	SlotKey      string    `json:"slot_key"`
+	FileName     string    `json:"file_name"`
+	StoragePath  string    `json:"-"`
+	SizeBytes    int64     `json:"size_bytes"`
+	MimeType     string    `json:"mime_type"`
+	Checksum     *string   `json:"checksum"`
+	UploadedBy   *string   `json:"uploaded_by"`
+	UploaderName *string   `json:"uploader_name"`
+	CreatedAt    time.Time `json:"created_at"`
+	// Text is what search reads from the file. It is only set on upload.
+	Text string `json:"-"`
+}
+
+// RevisionFile is a file as recorded in a submission revision.
+type RevisionFile struct {
+	SlotKey   string `json:"slot_key"`
+	FileID    string `json:"file_id"`
+	FileName  string `json:"file_name"`
+	SizeBytes int64  `json:"size_bytes"`
+	Checksum  string `json:"checksum"`
+}
+
+// FileDiff is a slot whose file differs between two revisions. Change is
+// "added", "removed" or "replaced".
+type FileDiff struct {
+	SlotKey string        `json:"slot_key"`
+	Change  string        `json:"change"`
+	From    *RevisionFile `json:"from"`
+	To      *RevisionFile `json:"to"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Team roles on a submission. The owner created it and manages the team,
+// editors can change it while it is editable and viewers can only read it.
+const (
+	MemberOwner  = "owner"
+	MemberEditor = "editor"
+	MemberViewer = "viewer"
+)
+
+// SubmissionMember is one person on a submission's team.
+type SubmissionMember struct {
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy *string   `json:"invited_by,omitempty"`
+	JoinedAt  time.Time `json:"joined_at,omitzero"`
+}
+
+// SubmissionInvite asks someone by email to join a submission's team.
+type SubmissionInvite struct {
+	ID              string     `json:"id"`
+	SubmissionID    string     `json:"submission_id"`
+	SubmissionTitle string     `json:"submission_title,omitempty"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	InvitedBy       *string    `json:"invited_by,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	AcceptedAt      *time.Time `json:"accepted_at,omitempty"`
+}
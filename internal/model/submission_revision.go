@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Kinds of submission revision.
+const (
+	RevisionSave   = "save"
+	RevisionSubmit = "submit"
+)
+
+// SubmissionRevision is an immutable snapshot of a submission's content,
+// taken whenever it is saved or submitted, including which files it had.
+type SubmissionRevision struct {
+	ID           string         `json:"id"`
+	SubmissionID string         `json:"submission_id"`
+	Number       int            `json:"number"`
+	Kind         string         `json:"kind"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	Files        []RevisionFile `json:"files"`
+	AuthorID     *string        `json:"author_id"`
+	AuthorName   *string        `json:"author_name"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// DiffSegment is a run of words that is the same in both versions, or was
+// inserted or deleted. Op is "equal", "insert" or "delete".
+type DiffSegment struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// RevisionDiff compares two revisions of a submission field by field.
+type RevisionDiff struct {
+	SubmissionID string        `json:"submission_id"`
+	From         int           `json:"from"`
+	To           int           `json:"to"`
+	Title        []DiffSegment `json:"title"`
+	Description  []DiffSegment `json:"description"`
+	FileChanged  bool          `json:"file_changed"`
+	Files        []FileDiff    `json:"files"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// SubmissionSearch is a full-text query over submissions and the filters
+// that narrow it. Zero values do not filter.
+type SubmissionSearch struct {
+	// Query uses web search syntax: quoted phrases, "or" and -excluded
+	// words. Empty lists everything, newest first.
+	Query    string
+	Statuses []string
+	// Tags must all be on a submission.
+	Tags   []string
+	Domain string
+	// From and To bound the creation time; To is exclusive.
+	From      *time.Time
+	To        *time.Time
+	FacultyID *string
+	CycleID   *string
+	Limit     int
+	Offset    int
+}
+
+// SubmissionSearchHit is one search result. TitleHTML and Snippet are
+// escaped HTML with the matched words in <mark>.
+type SubmissionSearchHit struct {
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+	TitleHTML       string    `json:"title_html"`
+	Snippet         string    `json:"snippet"`
+	Student         string    `json:"student"`
+	Status          string    `json:"status"`
+	Tags            []string  `json:"tags"`
+	Domain          *string   `json:"domain"`
+	CycleName       *string   `json:"cycle_name"`
+	AssignedFaculty []string  `json:"assigned_faculty"`
+	CreatedAt       time.Time `json:"submitted_on"`
+	Rank            float32   `json:"rank"`
+}
+
+// SubmissionSearchResults is one page of hits. Partial is set when no
+// submission matched every word and the hits match only some of them.
+type SubmissionSearchResults struct {
+	Total   int                   `json:"total"`
+	Partial bool                  `json:"partial"`
+	Results []SubmissionSearchHit `json:"results"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// Submission statuses. Which moves between them are allowed is defined by
+// the submission workflow in the service package.
+const (
+	SubmissionDraft            = "draft"
+	SubmissionSubmitted        = "submitted"
+	SubmissionAdminApproved    = "admin_approved"
+	SubmissionAdminRejected    = "admin_rejected"
+	SubmissionApproved         = "approved"
+	SubmissionRejected         = "rejected"
+	SubmissionNeedsImprovement = "needs_improvement"
+	SubmissionRevising         = "revising"
+)
+
+// SubmissionState is what the workflow needs to know about a submission to
+// decide on a transition and notify its owner.
+type SubmissionState struct {
+	SubmissionID string `json:"submission_id"`
+	UserID       string `json:"user_id"`
+	OwnerEmail   string `json:"-"`
+	Title        string `json:"title"`
+	Status       string `json:"status"`
+	// RevisionRound counts how many times the submission has been reopened
+	// after review.
+	RevisionRound int `json:"revision_round"`
+	// CycleID is the application cycle the submission belongs to, if any.
+	CycleID *string `json:"cycle_id"`
+	// CycleExempt is set on submissions made before cycles existed, which
+	// may be submitted without one.
+	CycleExempt bool `json:"-"`
+}
+
+// StatusChange is one entry in a submission's timeline.
+type StatusChange struct {
+	ID            string    `json:"id"`
+	SubmissionID  string    `json:"submission_id"`
+	FromStatus    *string   `json:"from_status"`
+	ToStatus      string    `json:"to_status"`
+	ActorID       *string   `json:"actor_id"`
+	ActorName     *string   `json:"actor_name"`
+	ActorRole     *string   `json:"actor_role"`
+	Reason        *string   `json:"reason"`
+	RevisionRound int       `json:"revision_round"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// SubmissionSettings are the workflow limits admins can change.
+type SubmissionSettings struct {
+	MaxRevisionRounds int       `json:"max_revision_rounds"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -17,4 +17,22 @@ type Submission struct {
 	CompanyLogo  *string   `json:"company_logo"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// RevisionRound counts how many times the submission has been reopened
+	// after review.
+	RevisionRound int `json:"revision_round"`
+
+	// CycleID is the application cycle the submission is made to. Older
+	// submissions have none.
+	CycleID   *string `json:"cycle_id"`
+	CycleName *string `json:"cycle_name,omitempty"`
+
+	// Answers holds the answers to the cycle's form by field key. FormID is
+	// the form version they were last saved against.
+	Answers map[string]any   `json:"answers"`
+	FormID  *string          `json:"form_id"`
+	Form    *ApplicationForm `json:"form,omitempty"`
+
+	// Role is the requesting user's role on the submission's team.
+	Role string `json:"role,omitempty"`
 }
@@ -0,0 +1,7 @@
+package model
+
+// Purposes of the single-use tokens stored in user_tokens.
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
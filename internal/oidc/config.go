@@ -0,0 +1,60 @@
+package oidc
+
+import (
+	"os"
+	"strings"
+)
+
+// Config describes the relying party registration with an OpenID Connect
+// provider.
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// RoleClaim names the ID token claim that carries the user's groups or
+	// roles. RoleMap turns its values into application roles.
+	RoleClaim string
+	RoleMap   map[string]string
+}
+
+// ConfigFromEnv reads OIDC_* variables. SSO is disabled when OIDC_ISSUER_URL
+// is empty.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		IssuerURL:    strings.TrimRight(os.Getenv("OIDC_ISSUER_URL"), "/"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+		RoleClaim:    os.Getenv("OIDC_ROLE_CLAIM"),
+		RoleMap:      parseRoleMap(os.Getenv("OIDC_ROLE_MAP")),
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = "groups"
+	}
+	return cfg
+}
+
+// Enabled reports whether an issuer has been configured.
+func (c Config) Enabled() bool {
+	return c.IssuerURL != ""
+}
+
+// parseRoleMap reads "claim-value=ROLE,other-value=ROLE".
+func parseRoleMap(s string) map[string]string {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		value, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || value == "" || role == "" {
+			continue
+		}
+		m[value] = strings.ToUpper(strings.TrimSpace(role))
+	}
+	return m
+}
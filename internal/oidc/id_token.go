@@ -0,0 +1,62 @@
+package oidc
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IDToken is the subset of verified ID token claims used for login.
+type IDToken struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	// Groups holds the values of the configured role claim, which may be a
+	// single string or a list in the token.
+	Groups []string
+}
+
+func newIDToken(claims jwt.MapClaims, issuer string, roleClaim string) (*IDToken, error) {
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, errors.New("id token has no subject")
+	}
+
+	t := &IDToken{Issuer: issuer, Subject: sub}
+	t.Email, _ = claims["email"].(string)
+	t.Name, _ = claims["name"].(string)
+
+	// Some providers send email_verified as the string "true"
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		t.EmailVerified = v
+	case string:
+		t.EmailVerified = v == "true"
+	}
+
+	switch v := claims[roleClaim].(type) {
+	case string:
+		t.Groups = []string{v}
+	case []any:
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				t.Groups = append(t.Groups, s)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// MapRole returns the first application role that one of the token's groups
+// maps to, or "" if none does.
+func (c Config) MapRole(groups []string) string {
+	for _, g := range groups {
+		if role, ok := c.RoleMap[g]; ok {
+			return role
+		}
+	}
+	return ""
+}
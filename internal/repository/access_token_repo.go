@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var ErrAccessTokenNotFound = errors.New("access token not found")
+
+type AccessTokenRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAccessTokenRepo(db *pgxpool.Pool) *AccessTokenRepo {
+	return &AccessTokenRepo{db: db}
+}
+
+// Create stores a new token and fills in its ID and created_at
+func (r *AccessTokenRepo) Create(ctx context.Context, t *model.PersonalAccessToken, tokenHash string) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO personal_access_tokens (user_id, name, token_hash, prefix, scopes, expires_at, mfa_verified)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, t.UserID, t.Name, tokenHash, t.Prefix, t.Scopes, t.ExpiresAt, t.MFAVerified).Scan(&t.ID, &t.CreatedAt)
+}
+
+// ListForUser returns the user's tokens that are neither revoked nor expired
+func (r *AccessTokenRepo) ListForUser(ctx context.Context, userID string) ([]model.PersonalAccessToken, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, user_id, name, prefix, scopes, mfa_verified, created_at, expires_at, last_used_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > now())
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []model.PersonalAccessToken{}
+	for rows.Next() {
+		var t model.PersonalAccessToken
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, &t.Scopes, &t.MFAVerified, &t.CreatedAt, &t.ExpiresAt, &t.LastUsedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// FindActive looks up a usable token by the hash of its value, together with
+// the user it belongs to. MFAVerified is only set while the owner still has
+// 2FA turned on.
+func (r *AccessTokenRepo) FindActive(ctx context.Context, tokenHash string) (*model.AccessTokenOwner, error) {
+	var o model.AccessTokenOwner
+	err := r.db.QueryRow(ctx, `
+		SELECT t.id, t.scopes,
+		       t.mfa_verified AND EXISTS (
+		           SELECT 1 FROM user_mfa m WHERE m.user_id = users.id AND m.enabled_at IS NOT NULL
+		       ),
+		       users.id, users.email, users.role, COALESCE(users.name, ''),
+		       `+userRolesColumn+`
+		FROM personal_access_tokens t
+		JOIN users ON users.id = t.user_id
+		WHERE t.token_hash = $1
+		  AND t.revoked_at IS NULL
+		  AND (t.expires_at IS NULL OR t.expires_at > now())
+	`, tokenHash).Scan(&o.TokenID, &o.Scopes, &o.MFAVerified, &o.UserID, &o.Email, &o.Role, &o.Name, &o.Roles)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAccessTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// TouchLastUsed records that a token was used. Writes are skipped when the
+// stored time is less than a minute old, so busy scripts do not write on
+// every request.
+func (r *AccessTokenRepo) TouchLastUsed(ctx context.Context, id string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE personal_access_tokens
+		SET last_used_at = now()
+		WHERE id = $1
+		  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`, id)
+	return err
+}
+
+// Revoke disables one of the user's tokens
+func (r *AccessTokenRepo) Revoke(ctx context.Context, id, userID string) error {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE personal_access_tokens
+		SET revoked_at = now()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// RevokeAllForUser disables every token the user has, e.g. after their
+// password is reset or their roles change
+func (r *AccessTokenRepo) RevokeAllForUser(ctx context.Context, userID string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE personal_access_tokens
+		SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	return err
+}
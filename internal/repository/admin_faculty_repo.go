@@ -21,13 +21,14 @@ func NewAdminFacultyRepository(db *pgxpool.Pool) *AdminFacultyRepository {
 	return &AdminFacultyRepository{db: db}
 }
 
-// GetAllByRole fetches all users with a specific role
+// GetAllByRole fetches all users holding a specific role
 func (r *AdminFacultyRepository) GetAllByRole(ctx context.Context, role string) ([]AdminFacultyUser, error) {
 	query := `
-		SELECT id, name, email
-		FROM users
-		WHERE role = $1
-		ORDER BY name ASC
+		SELECT u.id, u.name, u.email
+		FROM users u
+		JOIN user_roles ur ON ur.user_id = u.id
+		WHERE ur.role = $1
+		ORDER BY u.name ASC
 	`
 
 	rows, err := r.db.Query(ctx, query, role)
@@ -48,11 +49,12 @@ func (r *AdminFacultyRepository) GetAllByRole(ctx context.Context, role string)
 	return users, nil
 }
 
-// Create adds a new user with the specified role
+// Create adds a new user with the specified role. Accounts created by an
+// admin are considered verified.
 func (r *AdminFacultyRepository) Create(ctx context.Context, name, email, hashedPassword, role string) error {
 	query := `
-		INSERT INTO users (name, email, password_hash, role)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (name, email, password_hash, role, email_verified_at)
+		VALUES ($1, $2, $3, $4, now())
 	`
 
 	_, err := r.db.Exec(ctx, query, name, email, hashedPassword, role)
@@ -2,23 +2,26 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
 )
 
 type AdminSubmission struct {
-	ID              string    `json:"id"`
-	Title           string    `json:"title"`
-	Description     string    `json:"description"`
-	Student         string    `json:"student"`
-	FilePath        *string   `json:"file_path"`
-	CreatedAt       time.Time `json:"submitted_on"`
-	Status          string    `json:"status"`
-	Tags            []string  `json:"tags"`
-	Domain          *string   `json:"domain"`
-	AssignedFaculty []string  `json:"assigned_faculty"`
+	ID              string                   `json:"id"`
+	Title           string                   `json:"title"`
+	Description     string                   `json:"description"`
+	Student         string                   `json:"student"`
+	Team            []model.SubmissionMember `json:"team"`
+	FilePath        *string                  `json:"file_path"`
+	CreatedAt       time.Time                `json:"submitted_on"`
+	Status          string                   `json:"status"`
+	Tags            []string                 `json:"tags"`
+	Domain          *string                  `json:"domain"`
+	AssignedFaculty []string                 `json:"assigned_faculty"`
+	CycleID         *string                  `json:"cycle_id"`
+	CycleName       *string                  `json:"cycle_name"`
 }
 
 type FacultyAssignment struct {
@@ -38,24 +41,30 @@ func NewAdminSubmissionRepo(db *pgxpool.Pool) *AdminSubmissionRepo {
 }
 
 // GetPendingSubmissions returns submissions that students have submitted (status='submitted')
-// These are waiting for admin review before going to faculty
-func (r *AdminSubmissionRepo) GetPendingSubmissions(ctx context.Context) ([]AdminSubmission, error) {
+// These are waiting for admin review before going to faculty. A non-nil
+// cycleID limits them to that application cycle.
+func (r *AdminSubmissionRepo) GetPendingSubmissions(ctx context.Context, cycleID *string) ([]AdminSubmission, error) {
 	query := `
 		SELECT
 			s.submission_id,
 			s.title,
 			s.description,
 			u.name,
+			` + teamJSON + `,
 			s.file_path,
 			s.created_at,
-			s.status
+			s.status,
+			s.cycle_id,
+			c.name
 		FROM submissions s
 		JOIN users u ON s.user_id = u.id
+		LEFT JOIN application_cycles c ON c.id = s.cycle_id
 		WHERE s.status = 'submitted'
+		  AND ($1::uuid IS NULL OR s.cycle_id = $1)
 		ORDER BY s.created_at DESC
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, cycleID)
 	if err != nil {
 		return nil, err
 	}
@@ -70,9 +79,12 @@ func (r *AdminSubmissionRepo) GetPendingSubmissions(ctx context.Context) ([]Admi
 			&s.Title,
 			&s.Description,
 			&s.Student,
+			&s.Team,
 			&s.FilePath,
 			&s.CreatedAt,
 			&s.Status,
+			&s.CycleID,
+			&s.CycleName,
 		)
 		if err != nil {
 			return nil, err
@@ -83,69 +95,32 @@ func (r *AdminSubmissionRepo) GetPendingSubmissions(ctx context.Context) ([]Admi
 	return res, nil
 }
 
-// ApproveForFaculty moves the submission to 'admin_approved' status
-// so faculty can now review it
-func (r *AdminSubmissionRepo) ApproveForFaculty(ctx context.Context, submissionID string) error {
-	cmd, err := r.db.Exec(ctx, `
-		UPDATE submissions
-		SET status = 'admin_approved',
-		    updated_at = now()
-		WHERE submission_id = $1
-		  AND status = 'submitted'
-	`, submissionID)
-
-	if err != nil {
-		return err
-	}
-
-	if cmd.RowsAffected() == 0 {
-		return errors.New("submission not found or already processed")
-	}
-
-	return nil
-}
-
-// RejectSubmission marks submission as rejected by admin
-func (r *AdminSubmissionRepo) RejectSubmission(ctx context.Context, submissionID string, reason string) error {
-	cmd, err := r.db.Exec(ctx, `
-		UPDATE submissions
-		SET status = 'admin_rejected',
-		    updated_at = now()
-		WHERE submission_id = $1
-		  AND status = 'submitted'
-	`, submissionID)
-
-	if err != nil {
-		return err
-	}
-
-	if cmd.RowsAffected() == 0 {
-		return errors.New("submission not found or already processed")
-	}
-
-	return nil
-}
-
-// GetAllSubmissions returns ALL submissions for admin view (not just pending)
-func (r *AdminSubmissionRepo) GetAllSubmissions(ctx context.Context) ([]AdminSubmission, error) {
+// GetAllSubmissions returns ALL submissions for admin view (not just pending),
+// optionally limited to one application cycle
+func (r *AdminSubmissionRepo) GetAllSubmissions(ctx context.Context, cycleID *string) ([]AdminSubmission, error) {
 	query := `
 		SELECT
 			s.submission_id,
 			s.title,
 			s.description,
 			u.name,
+			` + teamJSON + `,
 			s.file_path,
 			s.created_at,
 			s.status,
 			COALESCE(s.tags, '{}'),
-			s.domain
+			s.domain,
+			s.cycle_id,
+			c.name
 		FROM submissions s
 		JOIN users u ON s.user_id = u.id
+		LEFT JOIN application_cycles c ON c.id = s.cycle_id
 		WHERE s.status != 'draft'
+		  AND ($1::uuid IS NULL OR s.cycle_id = $1)
 		ORDER BY s.created_at DESC
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, cycleID)
 	if err != nil {
 		return nil, err
 	}
@@ -160,11 +135,14 @@ func (r *AdminSubmissionRepo) GetAllSubmissions(ctx context.Context) ([]AdminSub
 			&s.Title,
 			&s.Description,
 			&s.Student,
+			&s.Team,
 			&s.FilePath,
 			&s.CreatedAt,
 			&s.Status,
 			&s.Tags,
 			&s.Domain,
+			&s.CycleID,
+			&s.CycleName,
 		)
 		if err != nil {
 			return nil, err
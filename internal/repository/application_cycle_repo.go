@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var (
+	ErrCycleNotFound  = errors.New("application cycle not found")
+	ErrCycleInUse     = errors.New("application cycle has submissions and cannot be deleted")
+	ErrCycleNameTaken = errors.New("an application cycle with this name already exists")
+)
+
+type ApplicationCycleRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewApplicationCycleRepo(db *pgxpool.Pool) *ApplicationCycleRepo {
+	return &ApplicationCycleRepo{db: db}
+}
+
+const cycleColumns = `id, name, description, opens_at, closes_at, review_deadline, created_at, updated_at`
+
+func scanCycle(row pgx.Row) (*model.ApplicationCycle, error) {
+	var c model.ApplicationCycle
+	err := row.Scan(&c.ID, &c.Name, &c.Description, &c.OpensAt, &c.ClosesAt, &c.ReviewDeadline, &c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCycleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *ApplicationCycleRepo) list(ctx context.Context, query string) ([]model.ApplicationCycle, error) {
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cycles := []model.ApplicationCycle{}
+	for rows.Next() {
+		c, err := scanCycle(rows)
+		if err != nil {
+			return nil, err
+		}
+		cycles = append(cycles, *c)
+	}
+	return cycles, rows.Err()
+}
+
+// List returns every cycle, newest first.
+func (r *ApplicationCycleRepo) List(ctx context.Context) ([]model.ApplicationCycle, error) {
+	return r.list(ctx, `
+		SELECT `+cycleColumns+`
+		FROM application_cycles
+		ORDER BY opens_at DESC
+	`)
+}
+
+// ListOpen returns the cycles accepting submissions now, closing soonest
+// first.
+func (r *ApplicationCycleRepo) ListOpen(ctx context.Context) ([]model.ApplicationCycle, error) {
+	return r.list(ctx, `
+		SELECT `+cycleColumns+`
+		FROM application_cycles
+		WHERE opens_at <= now() AND closes_at > now()
+		ORDER BY closes_at
+	`)
+}
+
+func (r *ApplicationCycleRepo) Get(ctx context.Context, id string) (*model.ApplicationCycle, error) {
+	return scanCycle(r.db.QueryRow(ctx, `
+		SELECT `+cycleColumns+`
+		FROM application_cycles
+		WHERE id = $1
+	`, id))
+}
+
+// Create stores a new cycle and fills in its ID and timestamps.
+func (r *ApplicationCycleRepo) Create(ctx context.Context, c *model.ApplicationCycle, createdBy string) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO application_cycles (name, description, opens_at, closes_at, review_deadline, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, c.Name, c.Description, c.OpensAt, c.ClosesAt, c.ReviewDeadline, createdBy).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	return cycleWriteError(err)
+}
+
+func (r *ApplicationCycleRepo) Update(ctx context.Context, c *model.ApplicationCycle, updatedBy string) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE application_cycles
+		SET name = $2,
+		    description = $3,
+		    opens_at = $4,
+		    closes_at = $5,
+		    review_deadline = $6,
+		    updated_by = $7,
+		    updated_at = now()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, c.ID, c.Name, c.Description, c.OpensAt, c.ClosesAt, c.ReviewDeadline, updatedBy).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrCycleNotFound
+	}
+	return cycleWriteError(err)
+}
+
+func cycleWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrCycleNameTaken
+	}
+	return err
+}
+
+// Delete removes a cycle. Cycles that submissions refer to cannot be
+// removed.
+func (r *ApplicationCycleRepo) Delete(ctx context.Context, id string) error {
+	cmd, err := r.db.Exec(ctx, `DELETE FROM application_cycles WHERE id = $1`, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrCycleInUse
+	}
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrCycleNotFound
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var ErrFormNotFound = errors.New("application form not found")
+
+// ApplicationFormRepo stores the versions of each cycle's application form
+// and reads the answers given to them.
+type ApplicationFormRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewApplicationFormRepo(db *pgxpool.Pool) *ApplicationFormRepo {
+	return &ApplicationFormRepo{db: db}
+}
+
+// submissionForm joins the form of submissions s as sf: the version its
+// answers were saved against while it belongs to that version's cycle, and
+// otherwise the newest version of its cycle.
+const submissionForm = `LEFT JOIN LATERAL (
+	SELECT f.id, f.version, f.fields
+	FROM application_forms f
+	WHERE f.cycle_id = s.cycle_id
+	ORDER BY f.id IS NOT DISTINCT FROM s.form_id DESC, f.version DESC
+	LIMIT 1
+) sf ON true`
+
+const formColumns = `f.id, f.cycle_id, f.version, f.fields, f.created_by, f.created_at`
+
+func scanForm(row pgx.Row) (*model.ApplicationForm, error) {
+	var f model.ApplicationForm
+	err := row.Scan(&f.ID, &f.CycleID, &f.Version, &f.Fields, &f.CreatedBy, &f.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrFormNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// List returns every version of a cycle's form, newest first.
+func (r *ApplicationFormRepo) List(ctx context.Context, cycleID string) ([]model.ApplicationForm, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+formColumns+`
+		FROM application_forms f
+		WHERE f.cycle_id = $1
+		ORDER BY f.version DESC
+	`, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	forms := []model.ApplicationForm{}
+	for rows.Next() {
+		f, err := scanForm(rows)
+		if err != nil {
+			return nil, err
+		}
+		forms = append(forms, *f)
+	}
+	return forms, rows.Err()
+}
+
+// Latest returns the newest version of a cycle's form.
+func (r *ApplicationFormRepo) Latest(ctx context.Context, cycleID string) (*model.ApplicationForm, error) {
+	return scanForm(r.db.QueryRow(ctx, `
+		SELECT `+formColumns+`
+		FROM application_forms f
+		WHERE f.cycle_id = $1
+		ORDER BY f.version DESC
+		LIMIT 1
+	`, cycleID))
+}
+
+// Create publishes f as the next version of its cycle's form and fills in
+// its ID, version and created_at.
+func (r *ApplicationFormRepo) Create(ctx context.Context, f *model.ApplicationForm) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO application_forms (cycle_id, version, fields, created_by)
+		SELECT $1::uuid, COALESCE(MAX(version), 0) + 1, $2::jsonb, $3::uuid
+		FROM application_forms
+		WHERE cycle_id = $1
+		RETURNING id, version, created_at
+	`, f.CycleID, f.Fields, f.CreatedBy).Scan(&f.ID, &f.Version, &f.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrCycleNotFound
+	}
+	return err
+}
+
+// ForSubmission returns the form a submission's answers are checked
+// against, as it would be after moving to cycleID if that is given.
+func (r *ApplicationFormRepo) ForSubmission(ctx context.Context, submissionID string, cycleID *string) (*model.ApplicationForm, error) {
+	return scanForm(r.db.QueryRow(ctx, `
+		SELECT `+formColumns+`
+		FROM submissions s
+		JOIN application_forms f ON f.cycle_id = COALESCE($2::uuid, s.cycle_id)
+		WHERE s.submission_id = $1
+		ORDER BY f.id IS NOT DISTINCT FROM s.form_id DESC, f.version DESC
+		LIMIT 1
+	`, submissionID, cycleID))
+}
+
+func (r *ApplicationFormRepo) Answers(ctx context.Context, submissionID string) (map[string]any, error) {
+	var answers map[string]any
+	err := r.db.QueryRow(ctx, `
+		SELECT answers FROM submissions WHERE submission_id = $1
+	`, submissionID).Scan(&answers)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSubmissionNotFound
+	}
+	return answers, err
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/rudraa2005/mic-website-main/backend/internal/model"
 )
 
+// userRolesColumn selects every role held by the user in the users row
+const userRolesColumn = `ARRAY(SELECT ur.role FROM user_roles ur WHERE ur.user_id = users.id ORDER BY ur.role)`
+
 type AuthRepository struct {
 	db *pgxpool.Pool
 }
@@ -38,14 +41,15 @@ func (r *AuthRepository) Create(ctx context.Context, user *model.User) (string,
 
 func (r *AuthRepository) FindByEmail(email string) (*model.User, error) {
 	query := `
-		SELECT id, email, password_hash, role, name
+		SELECT id, email, password_hash, role, name, email_verified_at,
+		       ` + userRolesColumn + `
 		FROM users
 		WHERE email = $1
 	`
 
 	var user model.User
 	err := r.db.QueryRow(context.Background(), query, email).
-		Scan(&user.UserID, &user.Email, &user.HashedPassword, &user.Role, &user.Name)
+		Scan(&user.UserID, &user.Email, &user.HashedPassword, &user.Role, &user.Name, &user.EmailVerifiedAt, &user.Roles)
 
 	if err != nil {
 		return nil, errors.New("user not found")
@@ -81,7 +85,10 @@ func (r *AuthRepository) GetByID(
 			id,
 			email,
 			password_hash,
-			role
+			role,
+			COALESCE(name, ''),
+			email_verified_at,
+			` + userRolesColumn + `
 		FROM users
 		WHERE id = $1
 	`
@@ -93,6 +100,9 @@ func (r *AuthRepository) GetByID(
 			&user.Email,
 			&user.HashedPassword,
 			&user.Role,
+			&user.Name,
+			&user.EmailVerifiedAt,
+			&user.Roles,
 		)
 
 	if err != nil {
@@ -101,3 +111,20 @@ func (r *AuthRepository) GetByID(
 
 	return &user, nil
 }
+
+func (r *AuthRepository) MarkEmailVerified(ctx context.Context, userID string) error {
+	query := `
+	UPDATE users
+	SET email_verified_at = COALESCE(email_verified_at, now()), updated_at = now()
+	WHERE id = $1
+	`
+	cmdTag, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
@@ -34,10 +34,13 @@ func (r *FeedbackRepo) GetByUserID(
 			f.recommendations,
 			f.rating,
 			f.status,
+			f.revision_id,
+			rv.number,
 			f.created_at,
 			f.updated_at
 		FROM feedbacks f
 		JOIN submissions s ON s.submission_id = f.submission_id
+		LEFT JOIN submission_revisions rv ON rv.id = f.revision_id
 		WHERE s.user_id = $1
 		ORDER BY f.updated_at DESC
 	`
@@ -64,6 +67,8 @@ func (r *FeedbackRepo) GetByUserID(
 			&f.Recommendations,
 			&f.Rating,
 			&f.Status,
+			&f.RevisionID,
+			&f.RevisionNumber,
 			&f.CreatedAt,
 			&f.UpdatedAt,
 		)
@@ -75,6 +80,8 @@ func (r *FeedbackRepo) GetByUserID(
 
 	return feedbacks, nil
 }
+
+// Create stores feedback against the submission's latest submitted revision.
 func (r *FeedbackRepo) Create(ctx context.Context, f *model.Feedback) error {
 	if f.FeedbackID == "" {
 		f.FeedbackID = uuid.NewString()
@@ -99,10 +106,21 @@ func (r *FeedbackRepo) Create(ctx context.Context, f *model.Feedback) error {
 			recommendations,
 			rating,
 			status,
+			revision_id,
 			created_at,
 			updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
-		RETURNING feedback_id
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11,
+			(
+				SELECT id
+				FROM submission_revisions
+				WHERE submission_id = $2
+				ORDER BY kind = 'submit' DESC, number DESC
+				LIMIT 1
+			),
+			NOW(), NOW()
+		)
+		RETURNING feedback_id, revision_id, (SELECT number FROM submission_revisions WHERE id = revision_id)
 	`
 
 	return r.db.QueryRow(ctx, query,
@@ -117,5 +135,5 @@ func (r *FeedbackRepo) Create(ctx context.Context, f *model.Feedback) error {
 		f.Recommendations,
 		f.Rating,
 		f.Status,
-	).Scan(&f.FeedbackID)
+	).Scan(&f.FeedbackID, &f.RevisionID, &f.RevisionNumber)
 }
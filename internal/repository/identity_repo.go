@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var (
+	ErrLoginStateInvalid = errors.New("login state is invalid or has expired")
+	ErrIdentityNotFound  = errors.New("identity not linked")
+)
+
+type IdentityRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityRepo(db *pgxpool.Pool) *IdentityRepo {
+	return &IdentityRepo{db: db}
+}
+
+// CreateLoginState stores an authorization request until its callback arrives
+func (r *IdentityRepo) CreateLoginState(ctx context.Context, stateHash string, s model.OIDCLoginState) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO oidc_login_states (state_hash, nonce, code_verifier, redirect_to, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, stateHash, s.Nonce, s.CodeVerifier, s.RedirectTo, s.ExpiresAt)
+	return err
+}
+
+// ConsumeLoginState deletes and returns an unexpired login state, so each
+// state value can complete at most one login
+func (r *IdentityRepo) ConsumeLoginState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error) {
+	var s model.OIDCLoginState
+	err := r.db.QueryRow(ctx, `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1
+		RETURNING nonce, code_verifier, redirect_to, expires_at
+	`, stateHash).Scan(&s.Nonce, &s.CodeVerifier, &s.RedirectTo, &s.ExpiresAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLoginStateInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(s.ExpiresAt) {
+		return nil, ErrLoginStateInvalid
+	}
+
+	// Opportunistically drop abandoned states
+	_, _ = r.db.Exec(ctx, `DELETE FROM oidc_login_states WHERE expires_at < now()`)
+
+	return &s, nil
+}
+
+// FindUserID returns the account linked to an issuer and subject
+func (r *IdentityRepo) FindUserID(ctx context.Context, issuer, subject string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(ctx, `
+		UPDATE user_identities
+		SET last_login_at = now()
+		WHERE issuer = $1 AND subject = $2
+		RETURNING user_id
+	`, issuer, subject).Scan(&userID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrIdentityNotFound
+	}
+	return userID, err
+}
+
+// Link attaches an identity provider subject to an account
+func (r *IdentityRepo) Link(ctx context.Context, userID, issuer, subject, email string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO user_identities (user_id, issuer, subject, email)
+		VALUES ($1, $2, $3, $4)
+	`, userID, issuer, subject, email)
+	return err
+}
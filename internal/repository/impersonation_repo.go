@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var ErrImpersonationNotFound = errors.New("impersonation not found or already ended")
+
+type ImpersonationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewImpersonationRepo(db *pgxpool.Pool) *ImpersonationRepo {
+	return &ImpersonationRepo{db: db}
+}
+
+// Create stores a new impersonation and fills in its ID and started_at
+func (r *ImpersonationRepo) Create(ctx context.Context, imp *model.Impersonation) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO impersonations (admin_id, user_id, session_id, reason, read_only, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, started_at
+	`, imp.AdminID, imp.UserID, imp.SessionID, imp.Reason, imp.ReadOnly, imp.ExpiresAt).Scan(&imp.ID, &imp.StartedAt)
+}
+
+// IsActive reports whether an impersonation has neither ended nor expired
+func (r *ImpersonationRepo) IsActive(ctx context.Context, id string) (bool, error) {
+	var active bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM impersonations
+			WHERE id = $1 AND ended_at IS NULL AND expires_at > now()
+		)
+	`, id).Scan(&active)
+	return active, err
+}
+
+// End stops an impersonation the admin started
+func (r *ImpersonationRepo) End(ctx context.Context, id, adminID string) error {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE impersonations
+		SET ended_at = now()
+		WHERE id = $1 AND admin_id = $2 AND ended_at IS NULL
+	`, id, adminID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrImpersonationNotFound
+	}
+	return nil
+}
+
+// List returns the most recent impersonations with how many requests each
+// made
+func (r *ImpersonationRepo) List(ctx context.Context, limit int) ([]model.Impersonation, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT i.id, i.admin_id, a.email, i.user_id, u.email, i.reason, i.read_only,
+		       i.started_at, i.expires_at, i.ended_at,
+		       (SELECT COUNT(*) FROM impersonation_requests ir WHERE ir.impersonation_id = i.id)
+		FROM impersonations i
+		JOIN users a ON a.id = i.admin_id
+		JOIN users u ON u.id = i.user_id
+		ORDER BY i.started_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []model.Impersonation{}
+	for rows.Next() {
+		var imp model.Impersonation
+		if err := rows.Scan(&imp.ID, &imp.AdminID, &imp.AdminEmail, &imp.UserID, &imp.UserEmail, &imp.Reason, &imp.ReadOnly,
+			&imp.StartedAt, &imp.ExpiresAt, &imp.EndedAt, &imp.RequestCount); err != nil {
+			return nil, err
+		}
+		res = append(res, imp)
+	}
+	return res, nil
+}
+
+// LogRequest records one request made while impersonating
+func (r *ImpersonationRepo) LogRequest(ctx context.Context, id, method, path string, status int) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO impersonation_requests (impersonation_id, method, path, status)
+		VALUES ($1, $2, $3, $4)
+	`, id, method, path, status)
+	return err
+}
+
+// ListRequests returns every request made during an impersonation, oldest
+// first
+func (r *ImpersonationRepo) ListRequests(ctx context.Context, id string) ([]model.ImpersonationRequest, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT method, path, status, created_at
+		FROM impersonation_requests
+		WHERE impersonation_id = $1
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []model.ImpersonationRequest{}
+	for rows.Next() {
+		var req model.ImpersonationRequest
+		if err := rows.Scan(&req.Method, &req.Path, &req.Status, &req.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, req)
+	}
+	return res, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+type LoginAttemptRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewLoginAttemptRepo(db *pgxpool.Pool) *LoginAttemptRepo {
+	return &LoginAttemptRepo{db: db}
+}
+
+// LockedUntil returns when the lock on key expires, or nil if it is not locked
+func (r *LoginAttemptRepo) LockedUntil(ctx context.Context, key string) (*time.Time, error) {
+	var until *time.Time
+	err := r.db.QueryRow(ctx, `
+		SELECT locked_until
+		FROM login_attempts
+		WHERE key = $1
+		  AND locked_until > now()
+	`, key).Scan(&until)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return until, err
+}
+
+// RecordFailure bumps the failure count for key and returns the new count.
+// Counters that have been quiet for longer than resetAfter start again at 1.
+func (r *LoginAttemptRepo) RecordFailure(ctx context.Context, key string, resetAfter time.Duration) (int, error) {
+	var failures int
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, now())
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+		        WHEN login_attempts.last_failure_at < now() - make_interval(secs => $2)
+		        THEN 1
+		        ELSE login_attempts.failures + 1
+		    END,
+		    last_failure_at = now()
+		RETURNING failures
+	`, key, resetAfter.Seconds()).Scan(&failures)
+	return failures, err
+}
+
+// Lock blocks logins for key until the given time
+func (r *LoginAttemptRepo) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE login_attempts
+		SET locked_until = $2
+		WHERE key = $1
+	`, key, until)
+	return err
+}
+
+// Clear forgets every failed attempt recorded for key
+func (r *LoginAttemptRepo) Clear(ctx context.Context, key string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM login_attempts WHERE key = $1`, key)
+	return err
+}
+
+// ListLocked returns every key that is currently locked out
+func (r *LoginAttemptRepo) ListLocked(ctx context.Context) ([]model.LoginAttempt, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT key, failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE locked_until > now()
+		ORDER BY locked_until DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []model.LoginAttempt
+	for rows.Next() {
+		var a model.LoginAttempt
+		if err := rows.Scan(&a.Key, &a.Failures, &a.LastFailureAt, &a.LockedUntil); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var ErrMFANotEnrolled = errors.New("two-factor authentication is not set up")
+
+type MFARepo struct {
+	db *pgxpool.Pool
+}
+
+func NewMFARepo(db *pgxpool.Pool) *MFARepo {
+	return &MFARepo{db: db}
+}
+
+// GetByUserID returns the user's TOTP enrollment, confirmed or not
+func (r *MFARepo) GetByUserID(ctx context.Context, userID string) (*model.UserMFA, error) {
+	var m model.UserMFA
+	err := r.db.QueryRow(ctx, `
+		SELECT user_id, totp_secret, enabled_at, last_used_step
+		FROM user_mfa
+		WHERE user_id = $1
+	`, userID).Scan(&m.UserID, &m.TOTPSecret, &m.EnabledAt, &m.LastUsedStep)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SavePending stores a new, unconfirmed secret. An already enabled
+// enrollment is left untouched.
+func (r *MFARepo) SavePending(ctx context.Context, userID, secret string) error {
+	cmd, err := r.db.Exec(ctx, `
+		INSERT INTO user_mfa (user_id, totp_secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET totp_secret = EXCLUDED.totp_secret,
+		    last_used_step = 0,
+		    created_at = now()
+		WHERE user_mfa.enabled_at IS NULL
+	`, userID, secret)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New("two-factor authentication is already enabled")
+	}
+	return nil
+}
+
+// Enable confirms a pending enrollment and stores its recovery codes
+func (r *MFARepo) Enable(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(ctx, `
+		UPDATE user_mfa
+		SET enabled_at = now(), last_used_step = $2
+		WHERE user_id = $1
+		  AND enabled_at IS NULL
+	`, userID, step)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrMFANotEnrolled
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Disable removes the user's enrollment and recovery codes
+func (r *MFARepo) Disable(ctx context.Context, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseStep records a TOTP step as used. It returns false if that step (or a
+// later one) was already accepted, which blocks replaying a code.
+func (r *MFARepo) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE user_mfa
+		SET last_used_step = $2
+		WHERE user_id = $1
+		  AND last_used_step < $2
+	`, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+// ReplaceRecoveryCodes swaps all of a user's recovery codes for new ones
+func (r *MFARepo) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, hashes); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string, hashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO mfa_recovery_codes (user_id, code_hash)
+			VALUES ($1, $2)
+		`, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConsumeRecoveryCode burns an unused recovery code. It returns false if the
+// code does not exist or was already used.
+func (r *MFARepo) ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE mfa_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1
+		  AND code_hash = $2
+		  AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+// CountUnusedRecoveryCodes returns how many recovery codes the user has left
+func (r *MFARepo) CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM mfa_recovery_codes
+		WHERE user_id = $1 AND used_at IS NULL
+	`, userID).Scan(&count)
+	return count, err
+}
+
+// IsRequired reports whether the policy makes MFA mandatory for a role
+func (r *MFARepo) IsRequired(ctx context.Context, role string) (bool, error) {
+	var required bool
+	err := r.db.QueryRow(ctx, `
+		SELECT required FROM mfa_policies WHERE role = $1
+	`, role).Scan(&required)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return required, err
+}
+
+// GetPolicies lists every role that has an MFA policy row
+func (r *MFARepo) GetPolicies(ctx context.Context) ([]model.MFAPolicy, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT role, required, updated_at
+		FROM mfa_policies
+		ORDER BY role
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []model.MFAPolicy
+	for rows.Next() {
+		var p model.MFAPolicy
+		if err := rows.Scan(&p.Role, &p.Required, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// SetPolicy creates or updates the MFA policy for a role
+func (r *MFARepo) SetPolicy(ctx context.Context, role string, required bool, updatedBy string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO mfa_policies (role, required, updated_by, updated_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (role) DO UPDATE
+		SET required = EXCLUDED.required,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = now()
+	`, role, required, updatedBy)
+	return err
+}
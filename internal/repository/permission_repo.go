@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var ErrRoleNotFound = errors.New("role not found")
+
+type PermissionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPermissionRepo(db *pgxpool.Pool) *PermissionRepo {
+	return &PermissionRepo{db: db}
+}
+
+func (r *PermissionRepo) ListPermissions(ctx context.Context) ([]model.Permission, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT name, description
+		FROM permissions
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []model.Permission{}
+	for rows.Next() {
+		var p model.Permission
+		if err := rows.Scan(&p.Name, &p.Description); err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// ListRoles returns every role with the permissions it grants
+func (r *PermissionRepo) ListRoles(ctx context.Context) ([]model.RolePermissions, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT ro.name, ro.description,
+		       COALESCE(ARRAY_AGG(rp.permission ORDER BY rp.permission)
+		                FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM roles ro
+		LEFT JOIN role_permissions rp ON rp.role = ro.name
+		GROUP BY ro.name, ro.description
+		ORDER BY ro.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []model.RolePermissions{}
+	for rows.Next() {
+		var rp model.RolePermissions
+		if err := rows.Scan(&rp.Role, &rp.Description, &rp.Permissions); err != nil {
+			return nil, err
+		}
+		res = append(res, rp)
+	}
+	return res, nil
+}
+
+// RolePermissions returns the permission names granted to a role
+func (r *PermissionRepo) RolePermissions(ctx context.Context, role string) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT permission FROM role_permissions WHERE role = $1
+	`, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
+
+// SetRolePermissions replaces the permissions granted to a role
+func (r *PermissionRepo) SetRolePermissions(ctx context.Context, role string, permissions []string, updatedBy string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var name string
+	err = tx.QueryRow(ctx, `SELECT name FROM roles WHERE name = $1 FOR UPDATE`, role).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrRoleNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM role_permissions
+		WHERE role = $1 AND NOT (permission = ANY($2))
+	`, role, permissions); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO role_permissions (role, permission, granted_by)
+		SELECT $1, p, $3 FROM unnest($2::text[]) AS p
+		ON CONFLICT DO NOTHING
+	`, role, permissions, updatedBy); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
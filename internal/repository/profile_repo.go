@@ -26,6 +26,7 @@ func (r *ProfileRepository) GetUserByID(ctx context.Context, userID string) (*mo
 		COALESCE(p.email, u.email) as email,
 		p.phone,
 		p.photo_url,
+		COALESCE(p.photo_size_bytes, 0) as photo_size_bytes,
 		COALESCE(p.bio, '') as bio,
 		COALESCE(p.created_at, u.created_at) as created_at,
 		COALESCE(p.updated_at, u.updated_at) as updated_at
@@ -36,7 +37,7 @@ func (r *ProfileRepository) GetUserByID(ctx context.Context, userID string) (*mo
 
 	var p model.Profile
 	err := r.db.QueryRow(ctx, query, userID).
-		Scan(&p.UserID, &p.Name, &p.Email, &p.Phone, &p.PhotoURL, &p.Bio, &p.CreatedAt, &p.UpdatedAt)
+		Scan(&p.UserID, &p.Name, &p.Email, &p.Phone, &p.PhotoURL, &p.PhotoSize, &p.Bio, &p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errors.New("user not found")
@@ -59,14 +60,14 @@ func (r *ProfileRepository) StoreUser(ctx context.Context, p model.Profile) erro
 	return err
 }
 
-func (r *ProfileRepository) UpdatePhotoURL(ctx context.Context, userID string, photoURL string) error {
+func (r *ProfileRepository) UpdatePhoto(ctx context.Context, userID string, photoURL string, size int64) error {
 	query := `
 	UPDATE profiles
-	SET photo_url = $2
+	SET photo_url = $2, photo_size_bytes = $3
 	WHERE user_id = $1
 	`
 
-	_, err := r.db.Exec(ctx, query, userID, photoURL)
+	_, err := r.db.Exec(ctx, query, userID, photoURL, size)
 
 	return err
 }
@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var (
+	ErrRoleRuleNotFound = errors.New("no role rule matches")
+	ErrRoleNotGranted   = errors.New("user does not hold that role")
+	ErrDefaultRole      = errors.New("a user's default role cannot be revoked, change it first")
+	ErrInviteNotFound   = errors.New("invite not found or no longer valid")
+	ErrUserNotFound     = errors.New("user not found")
+)
+
+type RolePolicyRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRolePolicyRepo(db *pgxpool.Pool) *RolePolicyRepo {
+	return &RolePolicyRepo{db: db}
+}
+
+// FindOverride returns the role set for a single email address
+func (r *RolePolicyRepo) FindOverride(ctx context.Context, email string) (string, error) {
+	var role string
+	err := r.db.QueryRow(ctx, `
+		SELECT role FROM role_email_overrides WHERE email = $1
+	`, email).Scan(&role)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrRoleRuleNotFound
+	}
+	return role, err
+}
+
+// FindDomainRule returns the role for an email domain
+func (r *RolePolicyRepo) FindDomainRule(ctx context.Context, domain string) (string, error) {
+	var role string
+	err := r.db.QueryRow(ctx, `
+		SELECT role FROM role_domain_rules WHERE domain = $1
+	`, domain).Scan(&role)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrRoleRuleNotFound
+	}
+	return role, err
+}
+
+func (r *RolePolicyRepo) ListDomainRules(ctx context.Context) ([]model.RoleDomainRule, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT domain, role, created_at
+		FROM role_domain_rules
+		ORDER BY domain
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []model.RoleDomainRule{}
+	for rows.Next() {
+		var d model.RoleDomainRule
+		if err := rows.Scan(&d.Domain, &d.Role, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, nil
+}
+
+// SetDomainRule creates or replaces the rule for a domain
+func (r *RolePolicyRepo) SetDomainRule(ctx context.Context, domain, role, createdBy string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO role_domain_rules (domain, role, created_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (domain) DO UPDATE
+		SET role = EXCLUDED.role,
+		    created_by = EXCLUDED.created_by,
+		    created_at = now()
+	`, domain, role, createdBy)
+	return err
+}
+
+func (r *RolePolicyRepo) DeleteDomainRule(ctx context.Context, domain string) error {
+	cmd, err := r.db.Exec(ctx, `DELETE FROM role_domain_rules WHERE domain = $1`, domain)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrRoleRuleNotFound
+	}
+	return nil
+}
+
+func (r *RolePolicyRepo) ListOverrides(ctx context.Context) ([]model.RoleEmailOverride, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT email, role, note, created_at
+		FROM role_email_overrides
+		ORDER BY email
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []model.RoleEmailOverride{}
+	for rows.Next() {
+		var o model.RoleEmailOverride
+		if err := rows.Scan(&o.Email, &o.Role, &o.Note, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, o)
+	}
+	return res, nil
+}
+
+// SetOverride creates or replaces the role override for an email address
+func (r *RolePolicyRepo) SetOverride(ctx context.Context, email, role, note, createdBy string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO role_email_overrides (email, role, note, created_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (email) DO UPDATE
+		SET role = EXCLUDED.role,
+		    note = EXCLUDED.note,
+		    created_by = EXCLUDED.created_by,
+		    created_at = now()
+	`, email, role, note, createdBy)
+	return err
+}
+
+func (r *RolePolicyRepo) DeleteOverride(ctx context.Context, email string) error {
+	cmd, err := r.db.Exec(ctx, `DELETE FROM role_email_overrides WHERE email = $1`, email)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrRoleRuleNotFound
+	}
+	return nil
+}
+
+// CreateInvite stores a new invite and fills in its ID and created_at
+func (r *RolePolicyRepo) CreateInvite(ctx context.Context, inv *model.RoleInvite, tokenHash string) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO role_invites (email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, inv.Email, inv.Role, tokenHash, inv.InvitedBy, inv.ExpiresAt).Scan(&inv.ID, &inv.CreatedAt)
+}
+
+const inviteColumns = `id, email, role, invited_by, created_at, expires_at, accepted_at`
+
+func scanInvite(row pgx.Row) (*model.RoleInvite, error) {
+	var inv model.RoleInvite
+	err := row.Scan(&inv.ID, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.CreatedAt, &inv.ExpiresAt, &inv.AcceptedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// FindPendingInvite returns the newest unaccepted, unexpired invite for email
+func (r *RolePolicyRepo) FindPendingInvite(ctx context.Context, email string) (*model.RoleInvite, error) {
+	return scanInvite(r.db.QueryRow(ctx, `
+		SELECT `+inviteColumns+`
+		FROM role_invites
+		WHERE email = $1
+		  AND accepted_at IS NULL
+		  AND expires_at > now()
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, email))
+}
+
+// FindInviteByToken returns a pending invite by the hash of its token
+func (r *RolePolicyRepo) FindInviteByToken(ctx context.Context, tokenHash string) (*model.RoleInvite, error) {
+	return scanInvite(r.db.QueryRow(ctx, `
+		SELECT `+inviteColumns+`
+		FROM role_invites
+		WHERE token_hash = $1
+		  AND accepted_at IS NULL
+		  AND expires_at > now()
+	`, tokenHash))
+}
+
+// AcceptInvites closes every pending invite for email once an account exists
+func (r *RolePolicyRepo) AcceptInvites(ctx context.Context, email string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE role_invites
+		SET accepted_at = now()
+		WHERE email = $1
+		  AND accepted_at IS NULL
+	`, email)
+	return err
+}
+
+func (r *RolePolicyRepo) ListInvites(ctx context.Context) ([]model.RoleInvite, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+inviteColumns+`
+		FROM role_invites
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []model.RoleInvite{}
+	for rows.Next() {
+		inv, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *inv)
+	}
+	return res, nil
+}
+
+// RevokeInvite deletes an invite that has not been accepted yet
+func (r *RolePolicyRepo) RevokeInvite(ctx context.Context, id string) error {
+	cmd, err := r.db.Exec(ctx, `
+		DELETE FROM role_invites
+		WHERE id = $1 AND accepted_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
+
+// ChangeUserRole replaces a user's default role, swapping it in user_roles
+// too, and writes the audit entry in the same transaction. It returns the
+// previous default role.
+func (r *RolePolicyRepo) ChangeUserRole(ctx context.Context, userID, role, changedBy, reason string) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var previous string
+	err = tx.QueryRow(ctx, `
+		SELECT role FROM users WHERE id = $1 FOR UPDATE
+	`, userID).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE users SET role = $2, updated_at = now() WHERE id = $1
+	`, userID, role); err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM user_roles WHERE user_id = $1 AND role = $2
+	`, userID, previous); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_roles (user_id, role, granted_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, userID, role, changedBy); err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO role_audit_log (user_id, previous_role, new_role, changed_by, reason)
+		VALUES ($1, $2, $3, $4, $5)
+	`, userID, previous, role, changedBy, reason); err != nil {
+		return "", err
+	}
+
+	return previous, tx.Commit(ctx)
+}
+
+// GrantRole adds a role to a user alongside the ones they hold. The audit
+// entry has an empty previous_role.
+func (r *RolePolicyRepo) GrantRole(ctx context.Context, userID, role, grantedBy, reason string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(ctx, `
+		INSERT INTO user_roles (user_id, role, granted_by)
+		SELECT id, $2, $3 FROM users WHERE id = $1
+		ON CONFLICT DO NOTHING
+	`, userID, role, grantedBy)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		var exists bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)
+		`, userID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrUserNotFound
+		}
+		// The user already holds the role
+		return tx.Commit(ctx)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO role_audit_log (user_id, previous_role, new_role, changed_by, reason)
+		VALUES ($1, '', $2, $3, $4)
+	`, userID, role, grantedBy, reason); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RevokeRole removes a role that is not the user's default. The audit entry
+// has an empty new_role.
+func (r *RolePolicyRepo) RevokeRole(ctx context.Context, userID, role, revokedBy, reason string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var defaultRole string
+	err = tx.QueryRow(ctx, `SELECT role FROM users WHERE id = $1`, userID).Scan(&defaultRole)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if defaultRole == role {
+		return ErrDefaultRole
+	}
+
+	cmd, err := tx.Exec(ctx, `
+		DELETE FROM user_roles WHERE user_id = $1 AND role = $2
+	`, userID, role)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrRoleNotGranted
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO role_audit_log (user_id, previous_role, new_role, changed_by, reason)
+		VALUES ($1, $2, '', $3, $4)
+	`, userID, role, revokedBy, reason); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListAudit returns role changes, newest first. An empty userID lists all.
+func (r *RolePolicyRepo) ListAudit(ctx context.Context, userID string) ([]model.RoleAuditEntry, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, user_id, previous_role, new_role, changed_by, reason, created_at
+		FROM role_audit_log
+		WHERE $1 = '' OR user_id::text = $1
+		ORDER BY created_at DESC
+		LIMIT 500
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []model.RoleAuditEntry{}
+	for rows.Next() {
+		var e model.RoleAuditEntry
+		if err := rows.Scan(&e.ID, &e.UserID, &e.PreviousRole, &e.NewRole, &e.ChangedBy, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
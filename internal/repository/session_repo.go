@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrRefreshTokenReused is returned when a refresh token that was already
+	// rotated is presented again, which means it has leaked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+type SessionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionRepo(db *pgxpool.Pool) *SessionRepo {
+	return &SessionRepo{db: db}
+}
+
+// Create stores a new session and fills in its ID and timestamps
+func (r *SessionRepo) Create(ctx context.Context, s *model.Session, refreshTokenHash string) error {
+	query := `
+		INSERT INTO sessions (user_id, refresh_token_hash, user_agent, ip_address, last_seen_ip, expires_at, mfa_verified, active_role)
+		VALUES ($1, $2, $3, $4, $4, $5, $6, $7)
+		RETURNING id, created_at, refreshed_at, last_seen_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		s.UserID,
+		refreshTokenHash,
+		s.UserAgent,
+		s.IPAddress,
+		s.ExpiresAt,
+		s.MFAVerified,
+		s.ActiveRole,
+	).Scan(&s.ID, &s.CreatedAt, &s.RefreshedAt, &s.LastSeenAt)
+}
+
+// Rotate swaps the refresh token of the session holding oldHash for newHash.
+// Presenting a token that was already rotated revokes the session.
+func (r *SessionRepo) Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*model.Session, error) {
+	query := `
+		UPDATE sessions
+		SET refresh_token_hash = $2,
+		    previous_token_hash = refresh_token_hash,
+		    refreshed_at = now(),
+		    expires_at = $3
+		WHERE refresh_token_hash = $1
+		  AND revoked_at IS NULL
+		  AND expires_at > now()
+		RETURNING id, user_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''), created_at, refreshed_at, expires_at, mfa_verified, COALESCE(active_role, '')
+	`
+
+	var s model.Session
+	err := r.db.QueryRow(ctx, query, oldHash, newHash, expiresAt).Scan(
+		&s.ID,
+		&s.UserID,
+		&s.UserAgent,
+		&s.IPAddress,
+		&s.CreatedAt,
+		&s.RefreshedAt,
+		&s.ExpiresAt,
+		&s.MFAVerified,
+		&s.ActiveRole,
+	)
+	if err == nil {
+		return &s, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = now()
+		WHERE previous_token_hash = $1
+		  AND revoked_at IS NULL
+	`, oldHash)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.RowsAffected() > 0 {
+		return nil, ErrRefreshTokenReused
+	}
+
+	return nil, ErrSessionNotFound
+}
+
+// IsActive reports whether the session exists, is unexpired and not revoked
+func (r *SessionRepo) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	var active bool
+	err := r.db.QueryRow(ctx, `
+		SELECT revoked_at IS NULL AND expires_at > now()
+		FROM sessions
+		WHERE id = $1
+	`, sessionID).Scan(&active)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return active, err
+}
+
+// Touch records that the session was used from ip. Writes are skipped when
+// the stored time is less than a minute old, so busy clients do not write
+// on every request.
+func (r *SessionRepo) Touch(ctx context.Context, sessionID, ip string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE sessions
+		SET last_seen_at = now(),
+		    last_seen_ip = $2
+		WHERE id = $1
+		  AND (last_seen_at < now() - interval '1 minute' OR last_seen_ip IS DISTINCT FROM $2)
+	`, sessionID, ip)
+	return err
+}
+
+// ListActive returns a user's live sessions, most recently used first
+func (r *SessionRepo) ListActive(ctx context.Context, userID string) ([]model.Session, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, user_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''), created_at, refreshed_at,
+		       expires_at, mfa_verified, COALESCE(active_role, ''), last_seen_at, COALESCE(last_seen_ip, '')
+		FROM sessions
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+		  AND expires_at > now()
+		ORDER BY last_seen_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		var s model.Session
+		if err := rows.Scan(
+			&s.ID,
+			&s.UserID,
+			&s.UserAgent,
+			&s.IPAddress,
+			&s.CreatedAt,
+			&s.RefreshedAt,
+			&s.ExpiresAt,
+			&s.MFAVerified,
+			&s.ActiveRole,
+			&s.LastSeenAt,
+			&s.LastSeenIP,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// MarkMFAVerified records that the session has completed a second factor
+func (r *SessionRepo) MarkMFAVerified(ctx context.Context, sessionID string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE sessions
+		SET mfa_verified = TRUE
+		WHERE id = $1
+	`, sessionID)
+	return err
+}
+
+// SetActiveRole records which role a session of userID is acting as
+func (r *SessionRepo) SetActiveRole(ctx context.Context, sessionID, userID, role string) error {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE sessions
+		SET active_role = $3
+		WHERE id = $1
+		  AND user_id = $2
+		  AND revoked_at IS NULL
+	`, sessionID, userID, role)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// Revoke ends a single session belonging to userID
+func (r *SessionRepo) Revoke(ctx context.Context, sessionID, userID string) error {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = now()
+		WHERE id = $1
+		  AND user_id = $2
+		  AND revoked_at IS NULL
+	`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOthers ends every live session of a user except keepID and returns
+// how many were ended
+func (r *SessionRepo) RevokeOthers(ctx context.Context, userID, keepID string) (int64, error) {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = now()
+		WHERE user_id = $1
+		  AND id <> $2
+		  AND revoked_at IS NULL
+	`, userID, keepID)
+	if err != nil {
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
+
+// RevokeAllForUser ends every live session of a user
+func (r *SessionRepo) RevokeAllForUser(ctx context.Context, userID string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = now()
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+	`, userID)
+	return err
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StorageReleaseRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewStorageReleaseRepo(db *pgxpool.Pool) *StorageReleaseRepo {
+	return &StorageReleaseRepo{db: db}
+}
+
+// Release marks a storage key as possibly unused so a later sweep can
+// remove it.
+func (r *StorageReleaseRepo) Release(ctx context.Context, key string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO storage_releases (key) VALUES ($1)
+		ON CONFLICT (key) DO UPDATE SET released_at = now()
+	`, key)
+	return err
+}
+
+// Claim takes a key back from the sweep before an upload reuses it. It waits
+// for a sweep that is removing the key, so afterwards the object either
+// still exists or is gone for good.
+func (r *StorageReleaseRepo) Claim(ctx context.Context, key string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM storage_releases WHERE key = $1`, key)
+	return err
+}
+
+// SweepOne takes one key released before cutoff and, unless a file record
+// or revision refers to it again, passes it to remove. The release is kept
+// locked until remove returns. It reports false when nothing is left to
+// sweep.
+func (r *StorageReleaseRepo) SweepOne(ctx context.Context, cutoff time.Time, remove func(ctx context.Context, key string) error) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var key string
+	var inUse bool
+	err = tx.QueryRow(ctx, `
+		SELECT rl.key,
+		       EXISTS (SELECT 1 FROM submission_files WHERE storage_path = rl.key)
+		    OR EXISTS (
+			SELECT 1 FROM submission_revisions
+			WHERE files @> jsonb_build_array(jsonb_build_object('storage_path', rl.key))
+		    )
+		FROM storage_releases rl
+		WHERE rl.released_at < $1
+		ORDER BY rl.released_at
+		LIMIT 1
+		FOR UPDATE OF rl SKIP LOCKED
+	`, cutoff).Scan(&key, &inUse)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !inUse {
+		if err := remove(ctx, key); err != nil {
+			return false, err
+		}
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM storage_releases WHERE key = $1`, key); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
@@ -2,25 +2,33 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
 )
 
 type FacultySubmission struct {
-	ID              string    `json:"id"`
-	Title           string    `json:"title"`
-	Description     string    `json:"description"`
-	Student         string    `json:"student"`
-	Email           string    `json:"email"`
-	FilePath        *string   `json:"file_path"`
-	CreatedAt       time.Time `json:"submitted_on"`
-	Status          string    `json:"status"`
-	Tags            []string  `json:"tags"`
-	Domain          *string   `json:"domain"`
-	Stage           *string   `json:"stage"`
-	ProgressPercent *int      `json:"progress_percent"`
+	ID              string                   `json:"id"`
+	Title           string                   `json:"title"`
+	Description     string                   `json:"description"`
+	Student         string                   `json:"student"`
+	Team            []model.SubmissionMember `json:"team"`
+	Email           string                   `json:"email"`
+	FilePath        *string                  `json:"file_path"`
+	CreatedAt       time.Time                `json:"submitted_on"`
+	Status          string                   `json:"status"`
+	Tags            []string                 `json:"tags"`
+	Domain          *string                  `json:"domain"`
+	Stage           *string                  `json:"stage"`
+	ProgressPercent *int                     `json:"progress_percent"`
+	RevisionRound   int                      `json:"revision_round"`
+
+	// Form and Answers are the cycle's questions and the student's answers
+	// to them. Only GetByID fills them in.
+	Form        []model.FormField `json:"form,omitempty"`
+	FormVersion *int              `json:"form_version,omitempty"`
+	Answers     map[string]any    `json:"answers,omitempty"`
 }
 
 type FacultySubmissionRepo struct {
@@ -38,15 +46,17 @@ func (r *FacultySubmissionRepo) GetSubmitted(ctx context.Context) ([]FacultySubm
 			s.title,
 			s.description,
 			u.name,
+			` + teamJSON + `,
 			u.email,
 			s.file_path,
 			s.created_at,
 			s.status,
 			COALESCE(s.tags, '{}'),
-			s.domain
+			s.domain,
+			s.revision_round
 		FROM submissions s
 		JOIN users u ON s.user_id = u.id
-		WHERE s.status IN ('admin_approved', 'approved', 'rejected')
+		WHERE s.status IN ('admin_approved', 'approved', 'rejected', 'needs_improvement', 'revising')
 		ORDER BY s.created_at DESC
 	`
 
@@ -65,12 +75,14 @@ func (r *FacultySubmissionRepo) GetSubmitted(ctx context.Context) ([]FacultySubm
 			&f.Title,
 			&f.Description,
 			&f.Student,
+			&f.Team,
 			&f.Email,
 			&f.FilePath,
 			&f.CreatedAt,
 			&f.Status,
 			&f.Tags,
 			&f.Domain,
+			&f.RevisionRound,
 		)
 		if err != nil {
 			return nil, err
@@ -88,6 +100,7 @@ func (r *FacultySubmissionRepo) GetByID(ctx context.Context, id string) (*Facult
 			s.title,
 			s.description,
 			u.name,
+			` + teamJSON + `,
 			u.email,
 			s.file_path,
 			s.created_at,
@@ -95,12 +108,17 @@ func (r *FacultySubmissionRepo) GetByID(ctx context.Context, id string) (*Facult
 			COALESCE(s.tags, '{}'),
 			s.domain,
 			w.stage,
-			w.progress_percent
+			w.progress_percent,
+			s.revision_round,
+			COALESCE(sf.fields, '[]'),
+			sf.version,
+			s.answers
 		FROM submissions s
 		JOIN users u ON s.user_id = u.id
 		LEFT JOIN work w ON w.submission_id = s.submission_id
+		` + submissionForm + `
 		WHERE s.submission_id = $1
-		  AND s.status IN ('admin_approved', 'approved', 'rejected')
+		  AND s.status IN ('admin_approved', 'approved', 'rejected', 'needs_improvement', 'revising')
 	`
 
 	var f FacultySubmission
@@ -109,6 +127,7 @@ func (r *FacultySubmissionRepo) GetByID(ctx context.Context, id string) (*Facult
 		&f.Title,
 		&f.Description,
 		&f.Student,
+		&f.Team,
 		&f.Email,
 		&f.FilePath,
 		&f.CreatedAt,
@@ -117,6 +136,10 @@ func (r *FacultySubmissionRepo) GetByID(ctx context.Context, id string) (*Facult
 		&f.Domain,
 		&f.Stage,
 		&f.ProgressPercent,
+		&f.RevisionRound,
+		&f.Form,
+		&f.FormVersion,
+		&f.Answers,
 	)
 	if err != nil {
 		return nil, err
@@ -124,141 +147,3 @@ func (r *FacultySubmissionRepo) GetByID(ctx context.Context, id string) (*Facult
 
 	return &f, nil
 }
-
-func (r *FacultySubmissionRepo) ApproveSubmission(
-	ctx context.Context,
-	submissionID string,
-	facultyID string,
-) (email string, title string, err error) {
-
-	tx, err := r.db.Begin(ctx)
-	if err != nil {
-		return "", "", err
-	}
-	defer tx.Rollback(ctx)
-
-	// Fetch details for email
-	var uEmail, sTitle, sDesc string
-	err = tx.QueryRow(ctx, `
-		SELECT u.email, s.title, s.description
-		FROM submissions s
-		JOIN users u ON s.user_id = u.id
-		WHERE s.submission_id = $1
-	`, submissionID).Scan(&uEmail, &sTitle, &sDesc)
-	if err != nil {
-		return "", "", err
-	}
-
-	// 1. Mark submission approved
-	cmd, err := tx.Exec(ctx, `
-		UPDATE submissions
-		SET status = 'approved'
-		WHERE submission_id = $1
-		  AND status = 'admin_approved'
-	`, submissionID)
-
-	if err != nil {
-		return "", "", err
-	}
-
-	if cmd.RowsAffected() == 0 {
-		return "", "", errors.New("submission not found or already processed")
-	}
-
-	// 2. Insert into work table
-	_, err = tx.Exec(ctx, `
-		INSERT INTO work (
-			submission_id,
-			title,
-			description,
-			stage,
-			progress_percent
-		)
-		VALUES ($1, $2, $3, 'under_incubation', 0)
-		ON CONFLICT (submission_id) DO NOTHING
-	`, submissionID, sTitle, sDesc)
-
-	if err != nil {
-		return "", "", err
-	}
-
-	err = tx.Commit(ctx)
-	if err != nil {
-		return "", "", err
-	}
-
-	return uEmail, sTitle, nil
-}
-
-func (r *FacultySubmissionRepo) RejectSubmission(
-	ctx context.Context,
-	submissionID string,
-	facultyID string,
-) (email string, title string, err error) {
-
-	// Fetch details for email
-	var uEmail, sTitle string
-	err = r.db.QueryRow(ctx, `
-		SELECT u.email, s.title
-		FROM submissions s
-		JOIN users u ON s.user_id = u.id
-		WHERE s.submission_id = $1
-	`, submissionID).Scan(&uEmail, &sTitle)
-	if err != nil {
-		return "", "", err
-	}
-
-	cmd, err := r.db.Exec(ctx, `
-		UPDATE submissions
-		SET status = 'rejected'
-		WHERE submission_id = $1
-		  AND status = 'admin_approved'
-	`, submissionID)
-
-	if err != nil {
-		return "", "", err
-	}
-
-	if cmd.RowsAffected() == 0 {
-		return "", "", errors.New("submission not found or already processed")
-	}
-
-	return uEmail, sTitle, nil
-}
-
-// NeedsImprovement marks a submission as needing improvement
-func (r *FacultySubmissionRepo) NeedsImprovement(
-	ctx context.Context,
-	submissionID string,
-	facultyID string,
-) (email string, title string, err error) {
-
-	// Fetch details for email
-	var uEmail, sTitle string
-	err = r.db.QueryRow(ctx, `
-		SELECT u.email, s.title
-		FROM submissions s
-		JOIN users u ON s.user_id = u.id
-		WHERE s.submission_id = $1
-	`, submissionID).Scan(&uEmail, &sTitle)
-	if err != nil {
-		return "", "", err
-	}
-
-	cmd, err := r.db.Exec(ctx, `
-		UPDATE submissions
-		SET status = 'needs_improvement'
-		WHERE submission_id = $1
-		  AND status = 'admin_approved'
-	`, submissionID)
-
-	if err != nil {
-		return "", "", err
-	}
-
-	if cmd.RowsAffected() == 0 {
-		return "", "", errors.New("submission not found or already processed")
-	}
-
-	return uEmail, sTitle, nil
-}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var (
+	ErrFileNotFound = errors.New("file not found")
+	ErrSlotNotFound = errors.New("file slot not found")
+	ErrSlotInUse    = errors.New("file slot still holds files")
+)
+
+type SubmissionFileRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSubmissionFileRepo(db *pgxpool.Pool) *SubmissionFileRepo {
+	return &SubmissionFileRepo{db: db}
+}
+
+// slotColumns are the columns scanSlot reads.
+const slotColumns = `sl.key, sl.cycle_id, sl.label, sl.description, sl.required, sl.position, sl.allowed_types, sl.updated_at`
+
+// slotSet is the key of a set of slots in idx_submission_file_slots_cycle_key.
+const slotSet = `COALESCE(cycle_id, '00000000-0000-0000-0000-000000000000'::uuid)`
+
+// submissionSlotCycle resolves the slot set of submission $1.
+const submissionSlotCycle = `file_slot_cycle((SELECT cycle_id FROM submissions WHERE submission_id = $1))`
+
+// ListSlots returns the slots of a cycle, or the default set if cycleID is
+// nil. A cycle without slots of its own gets none.
+func (r *SubmissionFileRepo) ListSlots(ctx context.Context, cycleID *string) ([]model.SubmissionFileSlot, error) {
+	return r.querySlots(ctx, `
+		SELECT `+slotColumns+`
+		FROM submission_file_slots sl
+		WHERE sl.cycle_id IS NOT DISTINCT FROM $1::uuid
+		ORDER BY sl.position, sl.key
+	`, cycleID)
+}
+
+// CycleSlots returns the slots submissions of a cycle fill: the cycle's own,
+// or the default set if it has none or cycleID is nil.
+func (r *SubmissionFileRepo) CycleSlots(ctx context.Context, cycleID *string) ([]model.SubmissionFileSlot, error) {
+	return r.querySlots(ctx, `
+		SELECT `+slotColumns+`
+		FROM submission_file_slots sl
+		WHERE sl.cycle_id IS NOT DISTINCT FROM file_slot_cycle($1)
+		ORDER BY sl.position, sl.key
+	`, cycleID)
+}
+
+// SubmissionSlots returns the slots of a submission's cycle.
+func (r *SubmissionFileRepo) SubmissionSlots(ctx context.Context, submissionID string) ([]model.SubmissionFileSlot, error) {
+	return r.querySlots(ctx, `
+		SELECT `+slotColumns+`
+		FROM submission_file_slots sl
+		WHERE sl.cycle_id IS NOT DISTINCT FROM `+submissionSlotCycle+`
+		ORDER BY sl.position, sl.key
+	`, submissionID)
+}
+
+func (r *SubmissionFileRepo) querySlots(ctx context.Context, query string, args ...any) ([]model.SubmissionFileSlot, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := []model.SubmissionFileSlot{}
+	for rows.Next() {
+		s, err := scanSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, *s)
+	}
+	return slots, rows.Err()
+}
+
+// GetSlot returns the {key} slot of a submission's cycle.
+func (r *SubmissionFileRepo) GetSlot(ctx context.Context, submissionID, key string) (*model.SubmissionFileSlot, error) {
+	s, err := scanSlot(r.db.QueryRow(ctx, `
+		SELECT `+slotColumns+`
+		FROM submission_file_slots sl
+		WHERE sl.cycle_id IS NOT DISTINCT FROM `+submissionSlotCycle+`
+		  AND sl.key = $2
+	`, submissionID, key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSlotNotFound
+	}
+	return s, err
+}
+
+// SetSlot creates or updates a slot of s.CycleID, or of the default set.
+func (r *SubmissionFileRepo) SetSlot(ctx context.Context, s *model.SubmissionFileSlot, updatedBy string) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO submission_file_slots (key, cycle_id, label, description, required, position, allowed_types, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now())
+		ON CONFLICT ((`+slotSet+`), key) DO UPDATE
+		SET label = EXCLUDED.label,
+		    description = EXCLUDED.description,
+		    required = EXCLUDED.required,
+		    position = EXCLUDED.position,
+		    allowed_types = EXCLUDED.allowed_types,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = now()
+		RETURNING updated_at
+	`, s.Key, s.CycleID, s.Label, s.Description, s.Required, s.Position, s.AllowedTypes, updatedBy).Scan(&s.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrCycleNotFound
+	}
+	return err
+}
+
+// DeleteSlot removes a slot of a cycle, or of the default set if cycleID is
+// nil. Slots that still hold files of submissions using them cannot be
+// removed.
+func (r *SubmissionFileRepo) DeleteSlot(ctx context.Context, cycleID *string, key string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var inUse bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM submission_files f
+			JOIN submissions s ON s.submission_id = f.submission_id
+			WHERE f.slot_key = $2
+			  AND file_slot_cycle(s.cycle_id) IS NOT DISTINCT FROM $1::uuid
+		)
+	`, cycleID, key).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrSlotInUse
+	}
+
+	cmd, err := tx.Exec(ctx, `
+		DELETE FROM submission_file_slots
+		WHERE cycle_id IS NOT DISTINCT FROM $1::uuid AND key = $2
+	`, cycleID, key)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrSlotNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+// MissingRequired returns the labels of required slots of the submission's
+// cycle it has no file for.
+func (r *SubmissionFileRepo) MissingRequired(ctx context.Context, submissionID string) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT sl.label
+		FROM submission_file_slots sl
+		WHERE sl.required
+		  AND sl.cycle_id IS NOT DISTINCT FROM `+submissionSlotCycle+`
+		  AND NOT EXISTS (
+			SELECT 1 FROM submission_files f
+			WHERE f.submission_id = $1 AND f.slot_key = sl.key
+		  )
+		ORDER BY sl.position, sl.key
+	`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var missing []string
+	for rows.Next() {
+		var label string
+		if err := rows.Scan(&label); err != nil {
+			return nil, err
+		}
+		missing = append(missing, label)
+	}
+	return missing, rows.Err()
+}
+
+func (r *SubmissionFileRepo) List(ctx context.Context, submissionID string) ([]model.SubmissionFile, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT f.id, f.submission_id, f.slot_key, f.file_name, f.storage_path, f.size_bytes, f.mime_type,
+		       f.checksum, f.uploaded_by, u.name, f.created_at
+		FROM submission_files f
+		LEFT JOIN submission_file_slots sl
+		       ON sl.key = f.slot_key AND sl.cycle_id IS NOT DISTINCT FROM `+submissionSlotCycle+`
+		LEFT JOIN users u ON u.id = f.uploaded_by
+		WHERE f.submission_id = $1
+		ORDER BY sl.position NULLS LAST, f.slot_key
+	`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := []model.SubmissionFile{}
+	for rows.Next() {
+		f, err := scanSubmissionFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *f)
+	}
+	return files, rows.Err()
+}
+
+func (r *SubmissionFileRepo) Get(ctx context.Context, submissionID, fileID string) (*model.SubmissionFile, error) {
+	row := r.db.QueryRow(ctx, `
+		SELECT f.id, f.submission_id, f.slot_key, f.file_name, f.storage_path, f.size_bytes, f.mime_type,
+		       f.checksum, f.uploaded_by, u.name, f.created_at
+		FROM submission_files f
+		LEFT JOIN users u ON u.id = f.uploaded_by
+		WHERE f.submission_id = $1
+		  AND f.id::text = $2
+	`, submissionID, fileID)
+	f, err := scanSubmissionFile(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrFileNotFound
+	}
+	return f, err
+}
+
+// Put stores f in its slot and returns the file it replaced, if any, so the
+// caller can remove its stored bytes.
+func (r *SubmissionFileRepo) Put(ctx context.Context, f *model.SubmissionFile) (*model.SubmissionFile, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var old model.SubmissionFile
+	err = tx.QueryRow(ctx, `
+		DELETE FROM submission_files
+		WHERE submission_id = $1 AND slot_key = $2
+		RETURNING id, storage_path
+	`, f.SubmissionID, f.SlotKey).Scan(&old.ID, &old.StoragePath)
+	replaced := &old
+	if errors.Is(err, pgx.ErrNoRows) {
+		replaced = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO submission_files (id, submission_id, slot_key, file_name, storage_path, size_bytes, mime_type, checksum, uploaded_by, text_content)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at
+	`, f.ID, f.SubmissionID, f.SlotKey, f.FileName, f.StoragePath, f.SizeBytes, f.MimeType, f.Checksum, f.UploadedBy, f.Text).Scan(&f.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
+
+// Delete removes a file record and returns it so the caller can remove the
+// stored bytes.
+func (r *SubmissionFileRepo) Delete(ctx context.Context, submissionID, fileID string) (*model.SubmissionFile, error) {
+	var f model.SubmissionFile
+	err := r.db.QueryRow(ctx, `
+		DELETE FROM submission_files
+		WHERE submission_id = $1
+		  AND id::text = $2
+		RETURNING id, storage_path
+	`, submissionID, fileID).Scan(&f.ID, &f.StoragePath)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrFileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func scanSlot(row pgx.Row) (*model.SubmissionFileSlot, error) {
+	var s model.SubmissionFileSlot
+	err := row.Scan(&s.Key, &s.CycleID, &s.Label, &s.Description, &s.Required, &s.Position, &s.AllowedTypes, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func scanSubmissionFile(row pgx.Row) (*model.SubmissionFile, error) {
+	var f model.SubmissionFile
+	err := row.Scan(
+		&f.ID,
+		&f.SubmissionID,
+		&f.SlotKey,
+		&f.FileName,
+		&f.StoragePath,
+		&f.SizeBytes,
+		&f.MimeType,
+		&f.Checksum,
+		&f.UploadedBy,
+		&f.UploaderName,
+		&f.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
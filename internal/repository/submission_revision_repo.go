@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+type SubmissionRevisionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSubmissionRevisionRepo(db *pgxpool.Pool) *SubmissionRevisionRepo {
+	return &SubmissionRevisionRepo{db: db}
+}
+
+// Record snapshots the submission's current content and files as its next
+// revision. The submission row is locked so concurrent saves get distinct
+// numbers.
+func (r *SubmissionRevisionRepo) Record(ctx context.Context, submissionID, kind string, authorID *string) (*model.SubmissionRevision, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rev := model.SubmissionRevision{SubmissionID: submissionID, Kind: kind, AuthorID: authorID}
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(title, ''), COALESCE(description, '')
+		FROM submissions
+		WHERE submission_id = $1
+		FOR UPDATE
+	`, submissionID).Scan(&rev.Title, &rev.Description)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO submission_revisions (submission_id, number, kind, title, description, files, author_id)
+		SELECT $1, COALESCE(MAX(number), 0) + 1, $2, $3, $4, (
+			SELECT COALESCE(jsonb_agg(jsonb_build_object(
+				'slot_key', f.slot_key,
+				'file_id', f.id,
+				'file_name', f.file_name,
+				'size_bytes', f.size_bytes,
+				'checksum', f.checksum,
+				'storage_path', f.storage_path
+			) ORDER BY f.slot_key), '[]'::jsonb)
+			FROM submission_files f
+			WHERE f.submission_id = $1
+		), $5
+		FROM submission_revisions
+		WHERE submission_id = $1
+		RETURNING id, number, files, created_at
+	`, submissionID, kind, rev.Title, rev.Description, authorID).Scan(&rev.ID, &rev.Number, &rev.Files, &rev.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// List returns a submission's revisions, newest first. A submission always
+// has at least one, so an empty result means it does not exist.
+func (r *SubmissionRevisionRepo) List(ctx context.Context, submissionID string) ([]model.SubmissionRevision, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT rv.id, rv.submission_id, rv.number, rv.kind, rv.title, rv.description, rv.files, rv.author_id, u.name, rv.created_at
+		FROM submission_revisions rv
+		LEFT JOIN users u ON u.id = rv.author_id
+		WHERE rv.submission_id = $1
+		ORDER BY rv.number DESC
+	`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []model.SubmissionRevision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, ErrSubmissionNotFound
+	}
+	return revisions, nil
+}
+
+// Get returns one revision of a submission by number.
+func (r *SubmissionRevisionRepo) Get(ctx context.Context, submissionID string, number int) (*model.SubmissionRevision, error) {
+	row := r.db.QueryRow(ctx, `
+		SELECT rv.id, rv.submission_id, rv.number, rv.kind, rv.title, rv.description, rv.files, rv.author_id, u.name, rv.created_at
+		FROM submission_revisions rv
+		LEFT JOIN users u ON u.id = rv.author_id
+		WHERE rv.submission_id = $1
+		  AND rv.number = $2
+	`, submissionID, number)
+	rev, err := scanRevision(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+func scanRevision(row pgx.Row) (*model.SubmissionRevision, error) {
+	var rev model.SubmissionRevision
+	err := row.Scan(
+		&rev.ID,
+		&rev.SubmissionID,
+		&rev.Number,
+		&rev.Kind,
+		&rev.Title,
+		&rev.Description,
+		&rev.Files,
+		&rev.AuthorID,
+		&rev.AuthorName,
+		&rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
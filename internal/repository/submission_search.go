@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+// Markers ts_headline puts around matched words. They are turned into
+// <mark> only after the text has been escaped.
+const (
+	highlightStart = "⟦"
+	highlightStop  = "⟧"
+)
+
+// searchQuery ranks submissions that are not drafts against the search
+// vector kept by the submissions_search triggers. With $11 set, any of the
+// query's words is enough to match.
+const searchQuery = `
+	WITH q AS (
+		SELECT CASE
+			WHEN numnode(t) = 0 THEN NULL
+			WHEN $11 THEN replace(t::text, '&', '|')::tsquery
+			ELSE t
+		END AS query
+		FROM websearch_to_tsquery('english', $1) t
+	)
+	SELECT
+		s.submission_id,
+		s.title,
+		CASE WHEN q.query IS NULL THEN s.title
+		     ELSE ts_headline('english', s.title, q.query, 'HighlightAll=true, StartSel="` + highlightStart + `", StopSel="` + highlightStop + `"')
+		END,
+		CASE WHEN q.query IS NULL THEN left(COALESCE(s.description, ''), 200)
+		     ELSE ts_headline('english',
+		         concat_ws(' … ', s.description, (SELECT string_agg(f.text_content, ' ') FROM submission_files f WHERE f.submission_id = s.submission_id)),
+		         q.query,
+		         'MaxFragments=2, MinWords=10, MaxWords=30, FragmentDelimiter=" … ", StartSel="` + highlightStart + `", StopSel="` + highlightStop + `"')
+		END,
+		u.name,
+		s.status,
+		COALESCE(s.tags, '{}'),
+		s.domain,
+		c.name,
+		COALESCE((
+			SELECT array_agg(fu.name ORDER BY fu.name)
+			FROM submission_faculty sf
+			JOIN users fu ON fu.id = sf.faculty_id
+			WHERE sf.submission_id = s.submission_id
+		), '{}'),
+		s.created_at,
+		COALESCE(ts_rank_cd(s.search_vector, q.query), 0)::real AS rank,
+		count(*) OVER ()
+	FROM submissions s
+	CROSS JOIN q
+	JOIN users u ON u.id = s.user_id
+	LEFT JOIN application_cycles c ON c.id = s.cycle_id
+	WHERE s.status <> 'draft'
+	  AND (q.query IS NULL OR s.search_vector @@ q.query)
+	  AND (cardinality($2::text[]) = 0 OR s.status = ANY($2))
+	  AND (cardinality($3::text[]) = 0 OR s.tags @> $3)
+	  AND ($4 = '' OR lower(s.domain) = lower($4))
+	  AND ($5::timestamp IS NULL OR s.created_at >= $5)
+	  AND ($6::timestamp IS NULL OR s.created_at < $6)
+	  AND ($7::uuid IS NULL OR EXISTS (
+		SELECT 1 FROM submission_faculty sf
+		WHERE sf.submission_id = s.submission_id AND sf.faculty_id = $7
+	  ))
+	  AND ($8::uuid IS NULL OR s.cycle_id = $8)
+	ORDER BY rank DESC, s.created_at DESC
+	LIMIT $9 OFFSET $10
+`
+
+// searchSubmissions runs a search limited to the allowed statuses, or to
+// any status but draft if allowed is nil. A query whose words never all
+// appear together is retried matching any of them.
+func searchSubmissions(ctx context.Context, db *pgxpool.Pool, f model.SubmissionSearch, allowed []string) (*model.SubmissionSearchResults, error) {
+	statuses := f.Statuses
+	if allowed != nil {
+		if len(statuses) == 0 {
+			statuses = allowed
+		} else {
+			statuses = slices.DeleteFunc(slices.Clone(statuses), func(s string) bool { return !slices.Contains(allowed, s) })
+			if len(statuses) == 0 {
+				return &model.SubmissionSearchResults{Results: []model.SubmissionSearchHit{}}, nil
+			}
+		}
+	}
+	if statuses == nil {
+		statuses = []string{}
+	}
+	tags := f.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	res, err := runSearch(ctx, db, f, statuses, tags, false)
+	if err != nil || res.Total > 0 || f.Offset > 0 || !loosenable(f.Query) {
+		return res, err
+	}
+	res, err = runSearch(ctx, db, f, statuses, tags, true)
+	if err != nil {
+		return nil, err
+	}
+	res.Partial = res.Total > 0
+	return res, nil
+}
+
+// loosenable reports whether a query can be retried matching any word.
+// Phrases and excluded words would change meaning.
+func loosenable(query string) bool {
+	return len(strings.Fields(query)) > 1 && !strings.ContainsAny(query, "-\"")
+}
+
+func runSearch(ctx context.Context, db *pgxpool.Pool, f model.SubmissionSearch, statuses, tags []string, anyWord bool) (*model.SubmissionSearchResults, error) {
+	rows, err := db.Query(ctx, searchQuery,
+		f.Query, statuses, tags, f.Domain, f.From, f.To, f.FacultyID, f.CycleID, f.Limit, f.Offset, anyWord)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := &model.SubmissionSearchResults{Results: []model.SubmissionSearchHit{}}
+	for rows.Next() {
+		var h model.SubmissionSearchHit
+		err := rows.Scan(
+			&h.ID,
+			&h.Title,
+			&h.TitleHTML,
+			&h.Snippet,
+			&h.Student,
+			&h.Status,
+			&h.Tags,
+			&h.Domain,
+			&h.CycleName,
+			&h.AssignedFaculty,
+			&h.CreatedAt,
+			&h.Rank,
+			&res.Total,
+		)
+		if err != nil {
+			return nil, err
+		}
+		h.TitleHTML = highlight(h.TitleHTML)
+		h.Snippet = highlight(h.Snippet)
+		res.Results = append(res.Results, h)
+	}
+	return res, rows.Err()
+}
+
+var highlighter = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// highlight escapes a ts_headline result and marks its matches.
+func highlight(s string) string {
+	return highlighter.Replace(html.EscapeString(s))
+}
+
+// Search finds submissions for admins across every status but draft.
+func (r *AdminSubmissionRepo) Search(ctx context.Context, f model.SubmissionSearch) (*model.SubmissionSearchResults, error) {
+	return searchSubmissions(ctx, r.db, f, nil)
+}
+
+// Search finds submissions in one of the visible statuses reviewers can see.
+func (r *FacultySubmissionRepo) Search(ctx context.Context, f model.SubmissionSearch, visible []string) (*model.SubmissionSearchResults, error) {
+	return searchSubmissions(ctx, r.db, f, visible)
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+type SubmissionSettingsRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSubmissionSettingsRepo(db *pgxpool.Pool) *SubmissionSettingsRepo {
+	return &SubmissionSettingsRepo{db: db}
+}
+
+func (r *SubmissionSettingsRepo) Get(ctx context.Context) (*model.SubmissionSettings, error) {
+	var s model.SubmissionSettings
+	err := r.db.QueryRow(ctx, `
+		SELECT max_revision_rounds, updated_at
+		FROM submission_settings
+		WHERE id
+	`).Scan(&s.MaxRevisionRounds, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SubmissionSettingsRepo) SetMaxRevisionRounds(ctx context.Context, rounds int, updatedBy string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO submission_settings (id, max_revision_rounds, updated_by, updated_at)
+		VALUES (TRUE, $1, $2, now())
+		ON CONFLICT (id) DO UPDATE
+		SET max_revision_rounds = EXCLUDED.max_revision_rounds,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = now()
+	`, rounds, updatedBy)
+	return err
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var (
+	ErrMemberNotFound     = errors.New("team member not found")
+	ErrTeamInviteNotFound = errors.New("team invite not found or no longer valid")
+)
+
+// SubmissionTeamRepo stores who works on a submission and the invitations
+// to join it. The owner's membership is created with the submission and is
+// never changed here.
+type SubmissionTeamRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSubmissionTeamRepo(db *pgxpool.Pool) *SubmissionTeamRepo {
+	return &SubmissionTeamRepo{db: db}
+}
+
+// teamJSON selects the team of submissions s as a JSON array, owner first,
+// for listings that show it next to each submission.
+const teamJSON = `(
+	SELECT COALESCE(json_agg(json_build_object(
+		'user_id', m.user_id, 'name', COALESCE(tu.name, ''), 'email', tu.email, 'role', m.role
+	) ORDER BY m.role = 'owner' DESC, m.created_at), '[]')
+	FROM submission_members m
+	JOIN users tu ON tu.id = m.user_id
+	WHERE m.submission_id = s.submission_id
+)`
+
+// List returns a submission's team, owner first.
+func (r *SubmissionTeamRepo) List(ctx context.Context, submissionID string) ([]model.SubmissionMember, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT m.user_id, COALESCE(u.name, ''), u.email, m.role, m.invited_by, m.created_at
+		FROM submission_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.submission_id = $1
+		ORDER BY m.role = 'owner' DESC, m.created_at
+	`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []model.SubmissionMember{}
+	for rows.Next() {
+		var m model.SubmissionMember
+		if err := rows.Scan(&m.UserID, &m.Name, &m.Email, &m.Role, &m.InvitedBy, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// SetRole changes the role of a member other than the owner.
+func (r *SubmissionTeamRepo) SetRole(ctx context.Context, submissionID, userID, role string) error {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE submission_members
+		SET role = $3
+		WHERE submission_id = $1 AND user_id = $2 AND role <> 'owner'
+	`, submissionID, userID, role)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// Remove takes a member other than the owner off the team.
+func (r *SubmissionTeamRepo) Remove(ctx context.Context, submissionID, userID string) error {
+	cmd, err := r.db.Exec(ctx, `
+		DELETE FROM submission_members
+		WHERE submission_id = $1 AND user_id = $2 AND role <> 'owner'
+	`, submissionID, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// CreateInvite stores a new invite, revoking any pending one for the same
+// email, and fills in its ID, created_at and the submission's title.
+func (r *SubmissionTeamRepo) CreateInvite(ctx context.Context, inv *model.SubmissionInvite, tokenHash string) error {
+	return r.db.QueryRow(ctx, `
+		WITH revoked AS (
+			UPDATE submission_invites
+			SET revoked_at = now()
+			WHERE submission_id = $1 AND email = $2
+			  AND accepted_at IS NULL AND revoked_at IS NULL
+		)
+		INSERT INTO submission_invites (submission_id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, (SELECT title FROM submissions WHERE submission_id = $1)
+	`, inv.SubmissionID, inv.Email, inv.Role, tokenHash, inv.InvitedBy, inv.ExpiresAt).Scan(&inv.ID, &inv.CreatedAt, &inv.SubmissionTitle)
+}
+
+const teamInviteColumns = `i.id, i.submission_id, s.title, i.email, i.role, i.invited_by, i.created_at, i.expires_at, i.accepted_at`
+
+// pendingInvite limits a query on submission_invites i to invites that can
+// still be accepted.
+const pendingInvite = `i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > now()`
+
+func scanTeamInvite(row pgx.Row) (*model.SubmissionInvite, error) {
+	var inv model.SubmissionInvite
+	err := row.Scan(&inv.ID, &inv.SubmissionID, &inv.SubmissionTitle, &inv.Email, &inv.Role,
+		&inv.InvitedBy, &inv.CreatedAt, &inv.ExpiresAt, &inv.AcceptedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTeamInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ListInvites returns a submission's pending invites, newest first.
+func (r *SubmissionTeamRepo) ListInvites(ctx context.Context, submissionID string) ([]model.SubmissionInvite, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+teamInviteColumns+`
+		FROM submission_invites i
+		JOIN submissions s ON s.submission_id = i.submission_id
+		WHERE i.submission_id = $1 AND `+pendingInvite+`
+		ORDER BY i.created_at DESC
+	`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []model.SubmissionInvite{}
+	for rows.Next() {
+		inv, err := scanTeamInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, *inv)
+	}
+	return invites, rows.Err()
+}
+
+// FindInvite returns a pending invite by the hash of its token.
+func (r *SubmissionTeamRepo) FindInvite(ctx context.Context, tokenHash string) (*model.SubmissionInvite, error) {
+	return scanTeamInvite(r.db.QueryRow(ctx, `
+		SELECT `+teamInviteColumns+`
+		FROM submission_invites i
+		JOIN submissions s ON s.submission_id = i.submission_id
+		WHERE i.token_hash = $1 AND `+pendingInvite+`
+	`, tokenHash))
+}
+
+// RevokeInvite cancels a pending invite.
+func (r *SubmissionTeamRepo) RevokeInvite(ctx context.Context, submissionID, inviteID string) error {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE submission_invites i
+		SET revoked_at = now()
+		WHERE i.id = $2 AND i.submission_id = $1 AND `+pendingInvite+`
+	`, submissionID, inviteID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrTeamInviteNotFound
+	}
+	return nil
+}
+
+// AcceptInvite closes a pending invite and adds userID to the team with the
+// invited role. An existing member gets the new role unless they own the
+// submission.
+func (r *SubmissionTeamRepo) AcceptInvite(ctx context.Context, inviteID, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var submissionID, role string
+	var invitedBy *string
+	err = tx.QueryRow(ctx, `
+		UPDATE submission_invites i
+		SET accepted_at = now(), accepted_by = $2
+		WHERE i.id = $1 AND `+pendingInvite+`
+		RETURNING i.submission_id, i.role, i.invited_by
+	`, inviteID, userID).Scan(&submissionID, &role, &invitedBy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTeamInviteNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO submission_members (submission_id, user_id, role, invited_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (submission_id, user_id) DO UPDATE
+		SET role = EXCLUDED.role
+		WHERE submission_members.role <> 'owner'
+	`, submissionID, userID, role, invitedBy)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
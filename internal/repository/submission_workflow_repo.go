@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var (
+	ErrSubmissionNotFound = errors.New("submission not found")
+	// ErrStatusChanged is returned when a submission is no longer in the
+	// status a transition starts from, e.g. two reviewers decided at once.
+	ErrStatusChanged = errors.New("submission status has changed, reload and try again")
+)
+
+// SubmissionWorkflowRepo is the only place that writes submissions.status.
+type SubmissionWorkflowRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSubmissionWorkflowRepo(db *pgxpool.Pool) *SubmissionWorkflowRepo {
+	return &SubmissionWorkflowRepo{db: db}
+}
+
+func (r *SubmissionWorkflowRepo) GetState(ctx context.Context, submissionID string) (*model.SubmissionState, error) {
+	var s model.SubmissionState
+	err := r.db.QueryRow(ctx, `
+		SELECT s.submission_id, s.user_id, u.email, s.title, s.status, s.revision_round, s.cycle_id, s.cycle_exempt
+		FROM submissions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.submission_id = $1
+	`, submissionID).Scan(&s.SubmissionID, &s.UserID, &s.OwnerEmail, &s.Title, &s.Status, &s.RevisionRound, &s.CycleID, &s.CycleExempt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// MemberRole returns userID's role on a submission's team, or "" if they
+// are not on it.
+func (r *SubmissionWorkflowRepo) MemberRole(ctx context.Context, submissionID, userID string) (string, error) {
+	var role string
+	err := r.db.QueryRow(ctx, `
+		SELECT role FROM submission_members
+		WHERE submission_id = $1 AND user_id = $2
+	`, submissionID, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+// Transition moves a submission from one status to another and records the
+// change, or returns ErrStatusChanged if it is no longer in from. newRound
+// starts the next revision round.
+func (r *SubmissionWorkflowRepo) Transition(ctx context.Context, submissionID, from, to string, newRound bool, change *model.StatusChange) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE submissions
+		SET status = $3,
+		    revision_round = revision_round + CASE WHEN $4 THEN 1 ELSE 0 END,
+		    updated_at = now()
+		WHERE submission_id = $1
+		  AND status = $2
+		RETURNING revision_round
+	`, submissionID, from, to, newRound).Scan(&change.RevisionRound)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrStatusChanged
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := insertStatusChange(ctx, tx, submissionID, &from, to, change); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RecordCreated starts the timeline of a new submission.
+func (r *SubmissionWorkflowRepo) RecordCreated(ctx context.Context, submissionID, status string, change *model.StatusChange) error {
+	return insertStatusChange(ctx, r.db, submissionID, nil, status, change)
+}
+
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertStatusChange(ctx context.Context, q rowQuerier, submissionID string, from *string, to string, change *model.StatusChange) error {
+	change.SubmissionID = submissionID
+	change.FromStatus = from
+	change.ToStatus = to
+	return q.QueryRow(ctx, `
+		INSERT INTO submission_status_history (submission_id, from_status, to_status, actor_id, actor_role, reason, revision_round)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, submissionID, from, to, change.ActorID, change.ActorRole, change.Reason, change.RevisionRound).Scan(&change.ID, &change.CreatedAt)
+}
+
+// History returns a submission's status changes, oldest first
+func (r *SubmissionWorkflowRepo) History(ctx context.Context, submissionID string) ([]model.StatusChange, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT h.id, h.submission_id, h.from_status, h.to_status, h.actor_id, u.name, h.actor_role, h.reason, h.revision_round, h.created_at
+		FROM submission_status_history h
+		LEFT JOIN users u ON u.id = h.actor_id
+		WHERE h.submission_id = $1
+		ORDER BY h.created_at, h.id
+	`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []model.StatusChange{}
+	for rows.Next() {
+		var c model.StatusChange
+		if err := rows.Scan(
+			&c.ID,
+			&c.SubmissionID,
+			&c.FromStatus,
+			&c.ToStatus,
+			&c.ActorID,
+			&c.ActorName,
+			&c.ActorRole,
+			&c.Reason,
+			&c.RevisionRound,
+			&c.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
+
+// ReviewerEmails returns the email addresses of the faculty assigned to a
+// submission
+func (r *SubmissionWorkflowRepo) ReviewerEmails(ctx context.Context, submissionID string) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT u.email
+		FROM submission_faculty sf
+		JOIN users u ON u.id = sf.faculty_id
+		WHERE sf.submission_id = $1
+	`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var e string
+		if err := rows.Scan(&e); err != nil {
+			return nil, err
+		}
+		emails = append(emails, e)
+	}
+	return emails, rows.Err()
+}
+
+// StartIncubation creates the work record for an approved submission. It is
+// safe to call more than once.
+func (r *SubmissionWorkflowRepo) StartIncubation(ctx context.Context, submissionID string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO work (submission_id, title, description, stage, progress_percent)
+		SELECT submission_id, title, description, 'under_incubation', 0
+		FROM submissions
+		WHERE submission_id = $1
+		ON CONFLICT (submission_id) DO NOTHING
+	`, submissionID)
+	return err
+}
@@ -24,17 +24,23 @@ func (r *SubmissionsRepo) GetBySubmissionID(
 
 	query := `
 		SELECT 
-			title,
-			description,
-			file_path,
-			status,
-			submission_id,
-			stage,
-			user_id,
-			created_at,
-			updated_at
-		FROM submissions
-		WHERE submission_id = $1
+			s.title,
+			s.description,
+			s.file_path,
+			s.status,
+			s.submission_id,
+			s.stage,
+			s.revision_round,
+			s.user_id,
+			s.created_at,
+			s.updated_at,
+			s.cycle_id,
+			c.name,
+			s.answers,
+			s.form_id
+		FROM submissions s
+		LEFT JOIN application_cycles c ON c.id = s.cycle_id
+		WHERE s.submission_id = $1
 	`
 	row, err := r.db.Query(ctx, query, submissionID)
 	if err != nil {
@@ -51,9 +57,14 @@ func (r *SubmissionsRepo) GetBySubmissionID(
 			&s.Status,
 			&s.SubmissionID,
 			&s.Stage,
+			&s.RevisionRound,
 			&s.UserID,
 			&s.CreatedAt,
 			&s.UpdatedAt,
+			&s.CycleID,
+			&s.CycleName,
+			&s.Answers,
+			&s.FormID,
 		)
 		if err != nil {
 			return nil, err
@@ -70,18 +81,24 @@ func (r *SubmissionsRepo) GetByUserID(
 
 	query := `
 		SELECT
-			submission_id,
-			user_id,
-			title,
-			description,
-			file_path,
-			status,
-			stage,
-			created_at,
-			updated_at
-		FROM submissions
-		WHERE user_id = $1
-		ORDER BY created_at DESC
+			s.submission_id,
+			s.user_id,
+			s.title,
+			s.description,
+			s.file_path,
+			s.status,
+			s.stage,
+			s.revision_round,
+			s.created_at,
+			s.updated_at,
+			m.role,
+			s.cycle_id,
+			c.name
+		FROM submissions s
+		JOIN submission_members m
+		  ON m.submission_id = s.submission_id AND m.user_id = $1
+		LEFT JOIN application_cycles c ON c.id = s.cycle_id
+		ORDER BY s.created_at DESC
 	`
 
 	rows, err := r.db.Query(ctx, query, userID)
@@ -102,8 +119,12 @@ func (r *SubmissionsRepo) GetByUserID(
 			&s.FilePath,
 			&s.Status,
 			&s.Stage,
+			&s.RevisionRound,
 			&s.CreatedAt,
 			&s.UpdatedAt,
+			&s.Role,
+			&s.CycleID,
+			&s.CycleName,
 		)
 		if err != nil {
 			return nil, err
@@ -115,10 +136,16 @@ func (r *SubmissionsRepo) GetByUserID(
 	return submissions, nil
 }
 
+// Create inserts a new draft and makes its author the owner of its team.
 func (r *SubmissionsRepo) Create(
 	ctx context.Context,
 	s *model.Submission,
 ) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
 	query := `
 		INSERT INTO submissions (
@@ -128,12 +155,13 @@ func (r *SubmissionsRepo) Create(
 			description,
 			file_path,
 			status,
-			stage
+			stage,
+			cycle_id
 		)
-		VALUES ($1, $2, $3, $4, $5, 'draft',$6)
+		VALUES ($1, $2, $3, $4, $5, 'draft',$6, $7)
 	`
 
-	_, err := r.db.Exec(
+	_, err = tx.Exec(
 		ctx,
 		query,
 		s.SubmissionID,
@@ -142,11 +170,24 @@ func (r *SubmissionsRepo) Create(
 		s.Description,
 		s.FilePath,
 		s.Stage,
+		s.CycleID,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	_, err = tx.Exec(ctx, `
+		INSERT INTO submission_members (submission_id, user_id, role)
+		VALUES ($1, $2, 'owner')
+	`, s.SubmissionID, s.UserID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
+// UpdateDraft saves edits by the owner or an editor, s.UserID being the one
+// making them. Answers are only replaced when s.Answers is set.
 func (r *SubmissionsRepo) UpdateDraft(
 	ctx context.Context,
 	s *model.Submission,
@@ -159,12 +200,25 @@ func (r *SubmissionsRepo) UpdateDraft(
 			title = $1,
 			description = $2,
 			file_path = COALESCE($3, file_path),
+			cycle_id = COALESCE($6, cycle_id),
+			answers = COALESCE($7, answers),
+			form_id = CASE WHEN $7::jsonb IS NULL THEN form_id ELSE $8 END,
 			updated_at = now()
-		WHERE user_id = $4
-		  AND submission_id = $5
-		  AND status = 'draft'
+		WHERE submission_id = $5
+		  AND status IN ('draft', 'revising')
+		  AND EXISTS (
+			SELECT 1 FROM submission_members m
+			WHERE m.submission_id = submissions.submission_id
+			  AND m.user_id = $4
+			  AND m.role IN ('owner', 'editor')
+		  )
 	`
 
+	var answers any
+	if s.Answers != nil {
+		answers = s.Answers
+	}
+
 	cmdTag, err := r.db.Exec(
 		ctx,
 		query,
@@ -173,13 +227,16 @@ func (r *SubmissionsRepo) UpdateDraft(
 		s.FilePath,
 		s.UserID,
 		s.SubmissionID,
+		s.CycleID,
+		answers,
+		s.FormID,
 	)
 	if err != nil {
 		return err
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		return errors.New("startup cannot be updated (not owner or not draft)")
+		return errors.New("startup cannot be updated (not owner or not editable)")
 	}
 
 	return nil
@@ -209,79 +266,6 @@ func (r *SubmissionsRepo) Delete(
 	return nil
 }
 
-func (r *SubmissionsRepo) MarkSubmitted(
-	ctx context.Context,
-	submissionID string,
-	userID string,
-) error {
-
-	query := `
-        UPDATE submissions
-        SET status = 'submitted',
-            updated_at = now()
-        WHERE submission_id = $1
-          AND user_id = $2
-          AND status = 'draft'
-    `
-
-	cmd, err := r.db.Exec(ctx, query, submissionID, userID)
-	if err != nil {
-		return err
-	}
-
-	if cmd.RowsAffected() == 0 {
-		return errors.New("submission cannot be submitted")
-	}
-
-	return nil
-}
-
-func (r *SubmissionsRepo) AttachFile(
-	ctx context.Context,
-	submissionID string,
-	userID string,
-	filePath string,
-) error {
-
-	query := `
-		UPDATE submissions
-		SET file_path = $1,
-		    updated_at = now()
-		WHERE submission_id = $2
-			AND user_id = $3
-	`
-
-	_, err := r.db.Exec(ctx, query, filePath, submissionID, userID)
-	return err
-}
-
-func (r *SubmissionsRepo) UpdateStatus(
-	ctx context.Context,
-	submissionID string,
-	newStatus string,
-	newStage string,
-) error {
-
-	query := `
-		UPDATE submissions
-		SET status = $1,
-		    stage = $2,
-		    updated_at = now()
-		WHERE submission_id = $3
-	`
-
-	cmd, err := r.db.Exec(ctx, query, newStatus, newStage, submissionID)
-	if err != nil {
-		return err
-	}
-
-	if cmd.RowsAffected() == 0 {
-		return errors.New("submission not found")
-	}
-
-	return nil
-}
-
 func (r *SubmissionsRepo) GetIncubationPipeline(ctx context.Context) ([]model.Submission, error) {
 	query := `
 		SELECT 
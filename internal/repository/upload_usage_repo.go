@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UploadUsageRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewUploadUsageRepo(db *pgxpool.Pool) *UploadUsageRepo {
+	return &UploadUsageRepo{db: db}
+}
+
+// Usage adds up the submission files a user uploaded and their profile photo.
+func (r *UploadUsageRepo) Usage(ctx context.Context, userID string) (int64, error) {
+	var used int64
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE((SELECT SUM(size_bytes) FROM submission_files WHERE uploaded_by = $1), 0)
+		     + COALESCE((SELECT photo_size_bytes FROM profiles WHERE user_id = $1), 0)
+	`, userID).Scan(&used)
+	return used, err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrTokenInvalid = errors.New("token is invalid, expired or already used")
+
+type UserTokenRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewUserTokenRepo(db *pgxpool.Pool) *UserTokenRepo {
+	return &UserTokenRepo{db: db}
+}
+
+// Create stores the hash of a freshly issued token
+func (r *UserTokenRepo) Create(ctx context.Context, userID, purpose, tokenHash string, expiresAt time.Time, requestedIP string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, requested_ip)
+		VALUES ($1, $2, $3, $4, $5)
+	`, userID, purpose, tokenHash, expiresAt, requestedIP)
+	return err
+}
+
+// Consume marks a live token as used and returns the user it was issued to.
+// A token can only be consumed once.
+func (r *UserTokenRepo) Consume(ctx context.Context, purpose, tokenHash string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(ctx, `
+		UPDATE user_tokens
+		SET used_at = now()
+		WHERE token_hash = $1
+		  AND purpose = $2
+		  AND used_at IS NULL
+		  AND expires_at > now()
+		RETURNING user_id
+	`, tokenHash, purpose).Scan(&userID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrTokenInvalid
+	}
+	return userID, err
+}
+
+// Lookup returns the user a live token was issued to without using it up
+func (r *UserTokenRepo) Lookup(ctx context.Context, purpose, tokenHash string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(ctx, `
+		SELECT user_id
+		FROM user_tokens
+		WHERE token_hash = $1
+		  AND purpose = $2
+		  AND used_at IS NULL
+		  AND expires_at > now()
+	`, tokenHash, purpose).Scan(&userID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrTokenInvalid
+	}
+	return userID, err
+}
+
+// CountRecent returns how many tokens of a purpose were issued to a user since the given time
+func (r *UserTokenRepo) CountRecent(ctx context.Context, userID, purpose string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM user_tokens
+		WHERE user_id = $1
+		  AND purpose = $2
+		  AND created_at > $3
+	`, userID, purpose, since).Scan(&count)
+	return count, err
+}
+
+// InvalidateForUser burns every outstanding token of a purpose for a user
+func (r *UserTokenRepo) InvalidateForUser(ctx context.Context, userID, purpose string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE user_tokens
+		SET used_at = now()
+		WHERE user_id = $1
+		  AND purpose = $2
+		  AND used_at IS NULL
+	`, userID, purpose)
+	return err
+}
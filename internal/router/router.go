@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -13,7 +14,7 @@ import (
 	appmw "github.com/rudraa2005/mic-website-main/backend/internal/middleware"
 )
 
-func NewRouter(sh *handler.StartupHandler, ah *handler.AuthHandler, ph *handler.ProfileHandler, seh *handler.SettingsHandler, subh *handler.SubmissionsHandler, fh *handler.FeedbackHandler, qh *handler.QueryHandler, th *handler.TestEmailHandler, aih *handler.AIHandler, ch *handler.ContentHandler, frh *handler.FacultyReviewHandler, feh *handler.EventInvitationHandler, fph *handler.FacultyProgressHandler, afh *handler.AdminFacultyHandler, ash *handler.AdminSubmissionHandler, workh *handler.WorkHandler, fih *handler.FacultyIncubationHandler, awh *handler.AdminWorkHandler) http.Handler {
+func NewRouter(sh *handler.StartupHandler, ah *handler.AuthHandler, ph *handler.ProfileHandler, seh *handler.SettingsHandler, subh *handler.SubmissionsHandler, fh *handler.FeedbackHandler, qh *handler.QueryHandler, th *handler.TestEmailHandler, aih *handler.AIHandler, ch *handler.ContentHandler, frh *handler.FacultyReviewHandler, feh *handler.EventInvitationHandler, fph *handler.FacultyProgressHandler, afh *handler.AdminFacultyHandler, ash *handler.AdminSubmissionHandler, workh *handler.WorkHandler, fih *handler.FacultyIncubationHandler, awh *handler.AdminWorkHandler, prh *handler.PasswordResetHandler, evh *handler.EmailVerificationHandler, mh *handler.MFAHandler, lh *handler.LockoutHandler, ssoh *handler.SSOHandler, rah *handler.RoleAdminHandler, permh *handler.PermissionHandler, ath *handler.AccessTokenHandler, imph *handler.ImpersonationHandler, sessh *handler.SessionHandler, am *appmw.Auth) http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(middleware.Logger)
@@ -58,16 +59,36 @@ func NewRouter(sh *handler.StartupHandler, ah *handler.AuthHandler, ph *handler.
 
 		r.Post("/login", ah.Login)
 		r.Post("/signup", ah.Signup)
+		r.With(appmw.CSRF).Post("/token/refresh", ah.Refresh)
+
+		mfaLimiter := appmw.NewRateLimiter(10, 5*time.Minute)
+		r.With(mfaLimiter.Limit).Post("/login/mfa", ah.LoginMFA)
+
+		r.Get("/auth/oidc/login", ssoh.Login)
+		r.Get("/auth/oidc/callback", ssoh.Callback)
+
+		// Password reset is unauthenticated, so it is throttled per client IP
+		resetLimiter := appmw.NewRateLimiter(5, 15*time.Minute)
+		r.With(resetLimiter.Limit).Post("/password/forgot", prh.Forgot)
+		r.With(resetLimiter.Limit).Post("/password/reset", prh.Reset)
+
+		verifyLimiter := appmw.NewRateLimiter(10, 15*time.Minute)
+		r.With(verifyLimiter.Limit).Post("/verify-email", evh.Verify)
+		r.With(verifyLimiter.Limit).Post("/verify-email/resend", evh.Resend)
 		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte("OK"))
 		})
 
 		r.Get("/submissions/incubation", workh.GetIncubationPipeline)
+		r.Get("/cycles/open", subh.OpenCycles)
+		r.Get("/cycles/{id}/form", subh.CycleForm)
+		r.Get("/profile/photos/{sum}", ph.Photo)
 
-		// Content management routes (accessible by ADMIN role)
+		// Content management routes
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
-			r.Use(appmw.RequireRoles("ADMIN", "FACULTY"))
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequirePermission("content.write"))
+			r.Use(am.RequireMFA)
 
 			r.Get("/contents", ch.GetAllContent)
 			r.Post("/create-content", ch.CreateContent)
@@ -77,37 +98,56 @@ func NewRouter(sh *handler.StartupHandler, ah *handler.AuthHandler, ph *handler.
 
 		// Admin submission review routes
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
-			r.Use(appmw.RequireRole("ADMIN"))
-
-			r.Get("/admin/submissions", ash.GetPendingSubmissions)
-			r.Get("/admin/submissions/all", ash.GetAllSubmissions)
-			r.Post("/admin/submissions/{id}/decision", ash.DecideSubmission)
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequireMFA)
+
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions", ash.GetPendingSubmissions)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions/all", ash.GetAllSubmissions)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions/search", ash.SearchSubmissions)
+			r.With(am.RequirePermission("submissions.decide")).Post("/admin/submissions/{id}/decision", ash.DecideSubmission)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions/{id}/timeline", ash.Timeline)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions/{id}/revisions", ash.Revisions)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions/{id}/revisions/diff", ash.RevisionDiff)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions/{id}/revisions/{number}", ash.Revision)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions/{id}/files", ash.Files)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions/{id}/files/{file_id}", ash.DownloadFile)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submission-file-slots", ash.ListFileSlots)
+			r.With(am.RequirePermission("submissions.decide")).Put("/admin/submission-file-slots/{key}", ash.SetFileSlot)
+			r.With(am.RequirePermission("submissions.decide")).Delete("/admin/submission-file-slots/{key}", ash.DeleteFileSlot)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/cycles", ash.ListCycles)
+			r.With(am.RequirePermission("submissions.decide")).Post("/admin/cycles", ash.CreateCycle)
+			r.With(am.RequirePermission("submissions.decide")).Put("/admin/cycles/{id}", ash.UpdateCycle)
+			r.With(am.RequirePermission("submissions.decide")).Delete("/admin/cycles/{id}", ash.DeleteCycle)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/cycles/{id}/forms", ash.CycleForms)
+			r.With(am.RequirePermission("submissions.decide")).Post("/admin/cycles/{id}/forms", ash.PublishForm)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions/settings", ash.GetSettings)
+			r.With(am.RequirePermission("submissions.decide")).Put("/admin/submissions/settings", ash.UpdateSettings)
 
 			// Faculty assignment routes
-			r.Post("/admin/submissions/{id}/assign-faculty", ash.AssignFaculty)
-			r.Delete("/admin/submissions/{id}/assign-faculty/{faculty_id}", ash.RemoveFaculty)
-			r.Get("/admin/submissions/{id}/faculty", ash.GetAssignedFaculty)
+			r.With(am.RequirePermission("submissions.assign")).Post("/admin/submissions/{id}/assign-faculty", ash.AssignFaculty)
+			r.With(am.RequirePermission("submissions.assign")).Delete("/admin/submissions/{id}/assign-faculty/{faculty_id}", ash.RemoveFaculty)
+			r.With(am.RequirePermission("submissions.view")).Get("/admin/submissions/{id}/faculty", ash.GetAssignedFaculty)
 
 			// Tags management
-			r.Put("/admin/submissions/{id}/tags", ash.UpdateTags)
+			r.With(am.RequirePermission("submissions.decide")).Put("/admin/submissions/{id}/tags", ash.UpdateTags)
 		})
 
 		// Admin faculty management routes
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
-			r.Use(appmw.RequireRoles("ADMIN", "FACULTY"))
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequireMFA)
 
-			r.Get("/admin/faculty", afh.GetAllFaculty)
-			r.Post("/admin/faculty", afh.CreateFaculty)
-			r.Put("/admin/faculty/{id}", afh.UpdateFaculty)
-			r.Delete("/admin/faculty/{id}", afh.DeleteFaculty)
+			r.With(am.RequirePermission("faculty.view")).Get("/admin/faculty", afh.GetAllFaculty)
+			r.With(am.RequirePermission("faculty.manage")).Post("/admin/faculty", afh.CreateFaculty)
+			r.With(am.RequirePermission("faculty.manage")).Put("/admin/faculty/{id}", afh.UpdateFaculty)
+			r.With(am.RequirePermission("faculty.manage")).Delete("/admin/faculty/{id}", afh.DeleteFaculty)
 		})
 
 		// Admin work/pipeline management routes
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
-			r.Use(appmw.RequireRole("ADMIN"))
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequirePermission("work.manage"))
+			r.Use(am.RequireMFA)
 
 			r.Get("/admin/work", awh.GetAllWork)
 			r.Put("/admin/work/{id}", awh.UpdateWork)
@@ -119,19 +159,77 @@ func NewRouter(sh *handler.StartupHandler, ah *handler.AuthHandler, ph *handler.
 		})
 
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
-			r.Use(appmw.RequireRole("FACULTY"))
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequirePermission("security.manage"))
+			r.Use(am.RequireMFA)
+
+			r.Get("/admin/mfa/policies", mh.GetPolicies)
+			r.Put("/admin/mfa/policies/{role}", mh.SetPolicy)
+
+			r.Get("/admin/lockouts", lh.List)
+			r.Delete("/admin/lockouts", lh.Clear)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequirePermission("roles.manage"))
+			r.Use(am.RequireMFA)
+
+			// Role assignment policy
+			r.Get("/admin/roles/domain-rules", rah.ListDomainRules)
+			r.Put("/admin/roles/domain-rules/{domain}", rah.SetDomainRule)
+			r.Delete("/admin/roles/domain-rules/{domain}", rah.DeleteDomainRule)
+			r.Get("/admin/roles/overrides", rah.ListOverrides)
+			r.Put("/admin/roles/overrides/{email}", rah.SetOverride)
+			r.Delete("/admin/roles/overrides/{email}", rah.DeleteOverride)
+			r.Get("/admin/roles/invites", rah.ListInvites)
+			r.Post("/admin/roles/invites", rah.CreateInvite)
+			r.Delete("/admin/roles/invites/{id}", rah.RevokeInvite)
+			r.Get("/admin/roles/audit", rah.ListAudit)
+			r.Put("/admin/users/{id}/role", rah.ChangeUserRole)
+			r.Post("/admin/users/{id}/roles", rah.GrantRole)
+			r.Delete("/admin/users/{id}/roles/{role}", rah.RevokeRole)
+
+			// Role permissions
+			r.Get("/admin/permissions", permh.ListPermissions)
+			r.Get("/admin/roles", permh.ListRoles)
+			r.Put("/admin/roles/{role}/permissions", permh.SetRolePermissions)
+		})
+
+		// Impersonation needs the admin's own session, never a token
+		r.Group(func(r chi.Router) {
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequireSession)
+			r.Use(am.RequirePermission("users.impersonate"))
+			r.Use(am.RequireMFA)
+
+			r.Get("/admin/impersonations", imph.List)
+			r.Post("/admin/impersonations", imph.Start)
+			r.Delete("/admin/impersonations/{id}", imph.End)
+			r.Get("/admin/impersonations/{id}/requests", imph.ListRequests)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequirePermission("reviews.perform"))
+			r.Use(am.RequireMFA)
 
 			r.Get("/faculty/reviews", frh.GetSubmitted)
+			r.Get("/faculty/reviews/search", frh.Search)
 			r.Get("/faculty/reviews/{id}", frh.GetByID)
 			r.Post("/faculty/reviews/{id}/decision", frh.Decide)
+			r.Get("/faculty/reviews/{id}/timeline", frh.Timeline)
+			r.Get("/faculty/reviews/{id}/revisions", frh.Revisions)
+			r.Get("/faculty/reviews/{id}/revisions/diff", frh.RevisionDiff)
+			r.Get("/faculty/reviews/{id}/revisions/{number}", frh.Revision)
+			r.Get("/faculty/reviews/{id}/files", frh.Files)
+			r.Get("/faculty/reviews/{id}/files/{file_id}", frh.DownloadFile)
 
 			r.Get("/faculty/events/invitations", feh.GetMyInvitations)
 			r.Post("/faculty/events/invitations/{invitation_id}/rsvp", feh.UpdateRSVP)
 			r.Get("/faculty/progress", fph.GetMyProgress)
 			r.Get("/faculty/progress/{submission_id}", fph.GetProgressBySubmission)
 			r.Post("/faculty/feedback", fh.Create)
-			r.Get("/submissions/{submission_id}/file", subh.DownloadSubmissionFile)
 
 			// Incubation Portfolio
 			r.Get("/faculty/incubation", fih.GetPortfolio)
@@ -140,46 +238,92 @@ func NewRouter(sh *handler.StartupHandler, ah *handler.AuthHandler, ph *handler.
 		})
 
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
-			r.Use(appmw.RequireRole("STUDENT"))
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequirePermission("submissions.create"))
 
 			r.Post("/startups", sh.Create)
 			r.Get("/startups/mine", sh.GetMine)
 		})
 
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
+			r.Use(am.AuthMiddleware)
 
+			r.Get("/permissions/mine", permh.Mine)
 			r.Get("/profile/me", ph.Me)
+		})
+
+		// Account management is not available to personal access tokens
+		r.Group(func(r chi.Router) {
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequireSession)
+
+			r.Post("/logout", ah.Logout)
+			r.Post("/roles/switch", ah.SwitchRole)
+
+			r.Get("/settings/sessions", sessh.List)
+			r.Delete("/settings/sessions/{id}", sessh.Revoke)
+			r.Post("/settings/sessions/revoke-others", sessh.RevokeOthers)
+
+			// 2FA setup must stay reachable for users whose role requires it
+			r.Get("/mfa/status", mh.Status)
+			r.Post("/mfa/enroll", mh.Enroll)
+			r.Post("/mfa/enroll/confirm", mh.ConfirmEnrollment)
+			r.With(mfaLimiter.LimitUser).Post("/mfa/recovery-codes", mh.RegenerateRecoveryCodes)
+			r.With(mfaLimiter.LimitUser).Post("/mfa/disable", mh.Disable)
 			r.Post("/profile/photo", ph.UploadPhoto)
+
+			r.Group(func(r chi.Router) {
+				r.Use(am.RequireMFA)
+
+				r.Get("/settings/tokens", ath.List)
+				r.Post("/settings/tokens", ath.Create)
+				r.Delete("/settings/tokens/{id}", ath.Revoke)
+			})
 		})
 
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
-			r.Use(appmw.RequireRole("STUDENT"))
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequirePermission("settings.manage"))
 
 			r.Get("/settings", seh.GetSettings)
 			r.Post("/settings/update", seh.UpdateSettings)
-			r.Post("/settings/update-password", ah.ChangePassword)
+			r.With(am.RequireSession).Post("/settings/update-password", ah.ChangePassword)
 			r.Post("/settings/profile-photo", ph.UploadPhoto)
 		})
 
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
-			r.Use(appmw.RequireRole("STUDENT"))
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequirePermission("submissions.create"))
 
 			r.Post("/submissions/create", subh.CreateSubmission)
 			r.Post("/submissions/submit/{submission_id}", subh.SubmitSubmission)
 			r.Put("/submissions/{submission_id}", subh.UpdateSubmission)
 			r.Get("/submissions/mine", subh.GetByUserID)
 			r.Get("/submissions/{submission_id}", subh.GetBySubmissionID)
+			r.Get("/submissions/{submission_id}/timeline", subh.Timeline)
+			r.Get("/submissions/{submission_id}/revisions", subh.Revisions)
+			r.Get("/submissions/{submission_id}/revisions/diff", subh.RevisionDiff)
+			r.Get("/submissions/{submission_id}/revisions/{number}", subh.Revision)
+			r.Post("/submissions/{submission_id}/reopen", subh.Reopen)
+			r.Post("/submissions/{submission_id}/resubmit", subh.Resubmit)
 			r.Delete("/submissions/{submission_id}", subh.DeleteSubmission)
-			r.Post("/submissions/{submission_id}/attach-file", subh.UploadSubmissionFile)
-			r.Get("/submissions/{submission_id}/file", subh.DownloadSubmissionFile)
+			r.Get("/submissions/file-slots", subh.FileSlots)
+			r.Get("/submissions/{submission_id}/file-slots", subh.SubmissionFileSlots)
+			r.Get("/submissions/{submission_id}/files", subh.Files)
+			r.Put("/submissions/{submission_id}/files/{slot}", subh.UploadFile)
+			r.Get("/submissions/{submission_id}/files/{file_id}", subh.DownloadFile)
+			r.Delete("/submissions/{submission_id}/files/{file_id}", subh.DeleteFile)
+			r.Get("/submissions/{submission_id}/members", subh.Members)
+			r.Put("/submissions/{submission_id}/members/{user_id}", subh.SetMemberRole)
+			r.Delete("/submissions/{submission_id}/members/{user_id}", subh.RemoveMember)
+			r.Get("/submissions/{submission_id}/invites", subh.Invites)
+			r.Post("/submissions/{submission_id}/invites", subh.InviteMember)
+			r.Delete("/submissions/{submission_id}/invites/{invite_id}", subh.RevokeInvite)
+			r.Post("/submissions/invites/accept", subh.AcceptInvite)
 		})
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
-			r.Use(appmw.RequireRole("STUDENT"))
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequirePermission("submissions.create"))
 
 			r.Get("/feedbacks", fh.GetMyFeedbacks)
 
@@ -188,8 +332,8 @@ func NewRouter(sh *handler.StartupHandler, ah *handler.AuthHandler, ph *handler.
 		})
 
 		r.Group(func(r chi.Router) {
-			r.Use(appmw.AuthMiddleware)
-			r.Use(appmw.RequireRole("STUDENT"))
+			r.Use(am.AuthMiddleware)
+			r.Use(am.RequirePermission("submissions.create"))
 
 			r.Post("/ai/analyze", aih.AnalyzeDraft)
 			r.Get("/ai/insights/{submission_id}", aih.GetInsights)
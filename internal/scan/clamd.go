@@ -0,0 +1,98 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// chunkSize is the largest chunk sent to clamd at once. It must stay below
+// clamd's StreamMaxLength.
+const chunkSize = 64 << 10
+
+// Clamd scans files with a ClamAV daemon over its INSTREAM command.
+type Clamd struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamd connects to clamd at addr, either "unix:/path/to/clamd.sock" or
+// "host:port".
+func NewClamd(addr string, timeout time.Duration) *Clamd {
+	c := &Clamd{network: "tcp", address: addr, timeout: timeout}
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		c.network, c.address = "unix", path
+	}
+	return c
+}
+
+func (c *Clamd) Scan(ctx context.Context, body io.Reader) (*Result, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+
+	buf := make([]byte, chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, werr := conn.Write(append(size, buf[:n]...)); werr != nil {
+				return nil, fmt.Errorf("clamd: %w", werr)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+	return parseReply(string(bytes.TrimRight(reply, "\x00")))
+}
+
+// parseReply reads "stream: OK", "stream: <signature> FOUND" or
+// "<message> ERROR".
+func parseReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(reply)
+	switch {
+	case strings.HasSuffix(reply, " ERROR"):
+		return nil, fmt.Errorf("clamd: %s", reply)
+	case strings.HasSuffix(reply, " FOUND"):
+		sig := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return &Result{Infected: true, Signature: sig}, nil
+	case strings.HasSuffix(reply, "OK"):
+		return &Result{}, nil
+	default:
+		return nil, fmt.Errorf("clamd: unexpected reply %q", reply)
+	}
+}
@@ -0,0 +1,25 @@
+// Package scan checks uploaded files for malware.
+package scan
+
+import (
+	"context"
+	"io"
+)
+
+// Result is the verdict on one file. Signature names what was found in an
+// infected file.
+type Result struct {
+	Infected  bool
+	Signature string
+}
+
+type Scanner interface {
+	Scan(ctx context.Context, body io.Reader) (*Result, error)
+}
+
+// Noop passes every file. It is used when no scanner is configured.
+type Noop struct{}
+
+func (Noop) Scan(context.Context, io.Reader) (*Result, error) {
+	return &Result{}, nil
+}
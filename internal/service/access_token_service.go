@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+)
+
+const (
+	defaultAccessTokenTTL = 90 * 24 * time.Hour
+	maxAccessTokenTTL     = 365 * 24 * time.Hour
+	maxAccessTokens       = 20
+)
+
+type AccessTokenRepository interface {
+	Create(ctx context.Context, t *model.PersonalAccessToken, tokenHash string) error
+	ListForUser(ctx context.Context, userID string) ([]model.PersonalAccessToken, error)
+	FindActive(ctx context.Context, tokenHash string) (*model.AccessTokenOwner, error)
+	TouchLastUsed(ctx context.Context, id string) error
+	Revoke(ctx context.Context, id, userID string) error
+	RevokeAllForUser(ctx context.Context, userID string) error
+}
+
+// AccessTokenRevoker lets account changes that sign a user out also disable
+// their personal access tokens.
+type AccessTokenRevoker interface {
+	RevokeAllForUser(ctx context.Context, userID string) error
+}
+
+type UserPermissionLister interface {
+	UserPermissions(ctx context.Context, roles []string) ([]string, error)
+}
+
+// AccessTokenService issues and checks personal access tokens for scripts.
+type AccessTokenService struct {
+	repo        AccessTokenRepository
+	permissions UserPermissionLister
+}
+
+func NewAccessTokenService(repo AccessTokenRepository, permissions UserPermissionLister) *AccessTokenService {
+	return &AccessTokenService{repo: repo, permissions: permissions}
+}
+
+// CreateAccessTokenInput is what a user fills in to create a token.
+// ExpiresInDays of 0 means the default of 90 days.
+type CreateAccessTokenInput struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// Create issues a token for the user. mfa is whether the creating session
+// passed 2FA. The returned string is the only time the token's value is
+// available.
+func (s *AccessTokenService) Create(ctx context.Context, userID string, roles []string, mfa bool, in CreateAccessTokenInput) (*model.PersonalAccessToken, string, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len(name) > 100 {
+		return nil, "", errors.New("token name must be 1 to 100 characters")
+	}
+	if len(in.Scopes) == 0 {
+		return nil, "", errors.New("choose at least one scope")
+	}
+
+	ttl := defaultAccessTokenTTL
+	if in.ExpiresInDays != 0 {
+		ttl = time.Duration(in.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl <= 0 || ttl > maxAccessTokenTTL {
+		return nil, "", errors.New("tokens must expire within 365 days")
+	}
+
+	// A token can never do more than its owner
+	held, err := s.permissions.UserPermissions(ctx, roles)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, scope := range in.Scopes {
+		if !slices.Contains(held, scope) {
+			return nil, "", fmt.Errorf("you do not have the %q permission", scope)
+		}
+	}
+
+	existing, err := s.repo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(existing) >= maxAccessTokens {
+		return nil, "", fmt.Errorf("you already have %d tokens, revoke one first", maxAccessTokens)
+	}
+
+	secret, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	token := auth.AccessTokenPrefix + secret
+
+	expiresAt := time.Now().Add(ttl)
+	t := &model.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		Prefix:      token[:len(auth.AccessTokenPrefix)+4],
+		Scopes:      slices.Compact(slices.Sorted(slices.Values(in.Scopes))),
+		MFAVerified: mfa,
+		ExpiresAt:   &expiresAt,
+	}
+	if err := s.repo.Create(ctx, t, auth.HashToken(token)); err != nil {
+		return nil, "", err
+	}
+	return t, token, nil
+}
+
+func (s *AccessTokenService) List(ctx context.Context, userID string) ([]model.PersonalAccessToken, error) {
+	return s.repo.ListForUser(ctx, userID)
+}
+
+func (s *AccessTokenService) Revoke(ctx context.Context, id, userID string) error {
+	return s.repo.Revoke(ctx, id, userID)
+}
+
+// Authenticate turns a personal access token into claims for the request.
+// The claims count as MFA-verified only if the token was created after 2FA
+// and its owner still has 2FA turned on.
+func (s *AccessTokenService) Authenticate(ctx context.Context, token string) (*auth.Claims, error) {
+	o, err := s.repo.FindActive(ctx, auth.HashToken(token))
+	if errors.Is(err, repository.ErrAccessTokenNotFound) {
+		return nil, auth.ErrInvalidAccessToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, o.TokenID); err != nil {
+		log.Println("[AUTH] failed to record token use:", err)
+	}
+
+	return &auth.Claims{
+		UserID:  o.UserID,
+		Role:    o.Role,
+		Roles:   userRoles(&o.User),
+		Email:   o.Email,
+		MFA:     o.MFAVerified,
+		TokenID: o.TokenID,
+		Scopes:  o.Scopes,
+	}, nil
+}
@@ -9,11 +9,14 @@ import (
 )
 
 type AdminFacultyService struct {
-	repo *repository.AdminFacultyRepository
+	repo         *repository.AdminFacultyRepository
+	sessionRepo  SessionRepository
+	accessTokens AccessTokenRevoker
+	passwords    PasswordValidator
 }
 
-func NewAdminFacultyService(repo *repository.AdminFacultyRepository) *AdminFacultyService {
-	return &AdminFacultyService{repo: repo}
+func NewAdminFacultyService(repo *repository.AdminFacultyRepository, sessionRepo SessionRepository, accessTokens AccessTokenRevoker, passwords PasswordValidator) *AdminFacultyService {
+	return &AdminFacultyService{repo: repo, sessionRepo: sessionRepo, accessTokens: accessTokens, passwords: passwords}
 }
 
 type FacultyResponse struct {
@@ -44,6 +47,9 @@ func (s *AdminFacultyService) CreateFaculty(ctx context.Context, name, email, pa
 	if name == "" || email == "" || password == "" {
 		return errors.New("name, email and password are required")
 	}
+	if err := s.passwords.Validate(password, email, name); err != nil {
+		return err
+	}
 
 	hashedPassword, err := auth.HashPassword(password)
 	if err != nil {
@@ -60,6 +66,10 @@ func (s *AdminFacultyService) UpdateFaculty(ctx context.Context, id, name, email
 
 	var hashedPassword string
 	if password != "" {
+		if err := s.passwords.Validate(password, email, name); err != nil {
+			return err
+		}
+
 		var err error
 		hashedPassword, err = auth.HashPassword(password)
 		if err != nil {
@@ -74,5 +84,11 @@ func (s *AdminFacultyService) DeleteFaculty(ctx context.Context, id string) erro
 	if id == "" {
 		return errors.New("faculty id is required")
 	}
+	if err := s.accessTokens.RevokeAllForUser(ctx, id); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(ctx, id); err != nil {
+		return err
+	}
 	return s.repo.Delete(ctx, id)
 }
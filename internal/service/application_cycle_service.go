@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+)
+
+var (
+	ErrInvalidCycle  = errors.New("a cycle needs a name, must close after it opens, and cannot have a review deadline before it closes")
+	ErrCycleClosed   = errors.New("this application cycle is not open for submissions")
+	ErrCycleRequired = errors.New("choose an application cycle for this submission")
+)
+
+type ApplicationCycleRepository interface {
+	List(ctx context.Context) ([]model.ApplicationCycle, error)
+	ListOpen(ctx context.Context) ([]model.ApplicationCycle, error)
+	Get(ctx context.Context, id string) (*model.ApplicationCycle, error)
+	Create(ctx context.Context, c *model.ApplicationCycle, createdBy string) error
+	Update(ctx context.Context, c *model.ApplicationCycle, updatedBy string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ApplicationCycleService manages the intakes students submit ideas to.
+type ApplicationCycleService struct {
+	repo ApplicationCycleRepository
+}
+
+func NewApplicationCycleService(repo ApplicationCycleRepository) *ApplicationCycleService {
+	return &ApplicationCycleService{repo: repo}
+}
+
+func (s *ApplicationCycleService) List(ctx context.Context) ([]model.ApplicationCycle, error) {
+	return s.repo.List(ctx)
+}
+
+// Open lists the cycles accepting submissions now.
+func (s *ApplicationCycleService) Open(ctx context.Context) ([]model.ApplicationCycle, error) {
+	return s.repo.ListOpen(ctx)
+}
+
+func validCycle(c *model.ApplicationCycle) bool {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" || c.OpensAt.IsZero() || !c.ClosesAt.After(c.OpensAt) {
+		return false
+	}
+	return c.ReviewDeadline == nil || !c.ReviewDeadline.Before(c.ClosesAt)
+}
+
+func (s *ApplicationCycleService) Create(ctx context.Context, c *model.ApplicationCycle, adminID string) error {
+	if !validCycle(c) {
+		return ErrInvalidCycle
+	}
+	return s.repo.Create(ctx, c, adminID)
+}
+
+func (s *ApplicationCycleService) Update(ctx context.Context, c *model.ApplicationCycle, adminID string) error {
+	if !validCycle(c) {
+		return ErrInvalidCycle
+	}
+	return s.repo.Update(ctx, c, adminID)
+}
+
+func (s *ApplicationCycleService) Delete(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// CheckSelectable returns ErrCycleClosed unless a draft can still be
+// prepared for the cycle, i.e. it has not closed yet.
+func (s *ApplicationCycleService) CheckSelectable(ctx context.Context, cycleID string) error {
+	if _, err := uuid.Parse(cycleID); err != nil {
+		return repository.ErrCycleNotFound
+	}
+	c, err := s.repo.Get(ctx, cycleID)
+	if err != nil {
+		return err
+	}
+	if !time.Now().Before(c.ClosesAt) {
+		return ErrCycleClosed
+	}
+	return nil
+}
+
+// CheckOpen is a workflow guard that stops a submission from being submitted
+// outside its cycle's window. Only submissions made before cycles existed
+// may be submitted without one.
+func (s *ApplicationCycleService) CheckOpen(ctx context.Context, state *model.SubmissionState, _ *auth.Claims) error {
+	if state.CycleID == nil {
+		if state.CycleExempt {
+			return nil
+		}
+		return ErrCycleRequired
+	}
+	c, err := s.repo.Get(ctx, *state.CycleID)
+	if err != nil {
+		return err
+	}
+	if !c.IsOpen(time.Now()) {
+		return ErrCycleClosed
+	}
+	return nil
+}
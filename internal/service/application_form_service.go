@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+)
+
+const maxFormFields = 100
+
+var (
+	ErrInvalidForm   = errors.New("invalid application form")
+	formKeyPattern   = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	formFieldTypes   = []string{model.FieldText, model.FieldTextarea, model.FieldNumber, model.FieldSelect, model.FieldMultiSelect, model.FieldCheckbox, model.FieldDate, model.FieldURL, model.FieldEmail}
+	textFieldTypes   = []string{model.FieldText, model.FieldTextarea, model.FieldURL, model.FieldEmail}
+	choiceFieldTypes = []string{model.FieldSelect, model.FieldMultiSelect}
+)
+
+// InvalidAnswersError lists the answers that do not fit the form, by field
+// key.
+type InvalidAnswersError struct {
+	Fields map[string]string
+}
+
+func (e *InvalidAnswersError) Error() string {
+	return "invalid answers: " + strings.Join(slices.Sorted(maps.Keys(e.Fields)), ", ")
+}
+
+type ApplicationFormRepository interface {
+	List(ctx context.Context, cycleID string) ([]model.ApplicationForm, error)
+	Latest(ctx context.Context, cycleID string) (*model.ApplicationForm, error)
+	Create(ctx context.Context, f *model.ApplicationForm) error
+	ForSubmission(ctx context.Context, submissionID string, cycleID *string) (*model.ApplicationForm, error)
+	Answers(ctx context.Context, submissionID string) (map[string]any, error)
+}
+
+// ApplicationFormService keeps the versioned questions of each cycle and
+// checks students' answers against them.
+type ApplicationFormService struct {
+	repo ApplicationFormRepository
+}
+
+func NewApplicationFormService(repo ApplicationFormRepository) *ApplicationFormService {
+	return &ApplicationFormService{repo: repo}
+}
+
+// Versions lists every published version of a cycle's form, newest first.
+func (s *ApplicationFormService) Versions(ctx context.Context, cycleID string) ([]model.ApplicationForm, error) {
+	if _, err := uuid.Parse(cycleID); err != nil {
+		return nil, repository.ErrCycleNotFound
+	}
+	return s.repo.List(ctx, cycleID)
+}
+
+// Current returns the version of a cycle's form new drafts are given.
+func (s *ApplicationFormService) Current(ctx context.Context, cycleID string) (*model.ApplicationForm, error) {
+	if _, err := uuid.Parse(cycleID); err != nil {
+		return nil, repository.ErrFormNotFound
+	}
+	return s.repo.Latest(ctx, cycleID)
+}
+
+// Publish stores fields as the next version of a cycle's form. Drafts that
+// already have answers keep the version they were saved against.
+func (s *ApplicationFormService) Publish(ctx context.Context, cycleID string, fields []model.FormField, adminID string) (*model.ApplicationForm, error) {
+	if _, err := uuid.Parse(cycleID); err != nil {
+		return nil, repository.ErrCycleNotFound
+	}
+	if err := validForm(fields); err != nil {
+		return nil, err
+	}
+	f := &model.ApplicationForm{CycleID: cycleID, Fields: fields, CreatedBy: &adminID}
+	if err := s.repo.Create(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// ForSubmission returns the form a submission's answers are checked against,
+// or nil if its cycle has none.
+func (s *ApplicationFormService) ForSubmission(ctx context.Context, submissionID string) (*model.ApplicationForm, error) {
+	f, err := s.repo.ForSubmission(ctx, submissionID, nil)
+	if errors.Is(err, repository.ErrFormNotFound) {
+		return nil, nil
+	}
+	return f, err
+}
+
+// PrepareAnswers checks the answers of a draft being saved, leaving out
+// those to hidden fields, and records the form version they were checked
+// against. Required fields may still be empty.
+func (s *ApplicationFormService) PrepareAnswers(ctx context.Context, submission *model.Submission) error {
+	f, err := s.repo.ForSubmission(ctx, submission.SubmissionID, submission.CycleID)
+	if errors.Is(err, repository.ErrFormNotFound) {
+		f = &model.ApplicationForm{}
+	} else if err != nil {
+		return err
+	}
+
+	answers, err := checkAnswers(f.Fields, submission.Answers, false)
+	if err != nil {
+		return err
+	}
+	submission.Answers = answers
+	submission.FormID = nil
+	if f.ID != "" {
+		submission.FormID = &f.ID
+	}
+	return nil
+}
+
+// CheckAnswers is a workflow guard that stops a submission from being
+// submitted or resubmitted until every visible required field is answered
+// and all answers fit the form.
+func (s *ApplicationFormService) CheckAnswers(ctx context.Context, state *model.SubmissionState, _ *auth.Claims) error {
+	if !slices.Contains(editableStatuses, state.Status) {
+		return nil
+	}
+	f, err := s.ForSubmission(ctx, state.SubmissionID)
+	if err != nil || f == nil {
+		return err
+	}
+	answers, err := s.repo.Answers(ctx, state.SubmissionID)
+	if err != nil {
+		return err
+	}
+	_, err = checkAnswers(f.Fields, answers, true)
+	return err
+}
+
+func invalidForm(key, reason string) error {
+	return fmt.Errorf("%w: field %q %s", ErrInvalidForm, key, reason)
+}
+
+// validForm checks and tidies an admin's field definitions. Conditions may
+// only refer to earlier fields so answers can be checked in one pass.
+func validForm(fields []model.FormField) error {
+	if len(fields) > maxFormFields {
+		return fmt.Errorf("%w: at most %d fields", ErrInvalidForm, maxFormFields)
+	}
+
+	seen := map[string]bool{}
+	for i := range fields {
+		f := &fields[i]
+		f.Label = strings.TrimSpace(f.Label)
+		if !formKeyPattern.MatchString(f.Key) {
+			return invalidForm(f.Key, "needs a key of lowercase letters, digits and underscores")
+		}
+		if seen[f.Key] {
+			return invalidForm(f.Key, "is defined twice")
+		}
+		if f.Label == "" {
+			return invalidForm(f.Key, "needs a label")
+		}
+		if !slices.Contains(formFieldTypes, f.Type) {
+			return invalidForm(f.Key, "has an unknown type")
+		}
+
+		if slices.Contains(choiceFieldTypes, f.Type) {
+			options := []string{}
+			for _, o := range f.Options {
+				o = strings.TrimSpace(o)
+				if o == "" || slices.Contains(options, o) {
+					return invalidForm(f.Key, "has an empty or repeated option")
+				}
+				options = append(options, o)
+			}
+			if len(options) == 0 {
+				return invalidForm(f.Key, "needs options")
+			}
+			f.Options = options
+		} else {
+			f.Options = nil
+		}
+
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return invalidForm(f.Key, "has min above max")
+		}
+		if f.Pattern != "" {
+			if !slices.Contains(textFieldTypes, f.Type) {
+				return invalidForm(f.Key, "cannot have a pattern")
+			}
+			if _, err := regexp.Compile(f.Pattern); err != nil {
+				return invalidForm(f.Key, "has an invalid pattern")
+			}
+		}
+
+		if c := f.VisibleIf; c != nil {
+			if !seen[c.Field] {
+				return invalidForm(f.Key, "can only depend on an earlier field")
+			}
+			if len(c.Values) == 0 {
+				return invalidForm(f.Key, "needs values for its condition")
+			}
+		}
+		seen[f.Key] = true
+	}
+	return nil
+}
+
+// checkAnswers checks answers against fields and returns them cleaned up,
+// without answers to hidden fields. complete requires every visible
+// required field to be answered.
+func checkAnswers(fields []model.FormField, answers map[string]any, complete bool) (map[string]any, error) {
+	problems := map[string]string{}
+	for key := range answers {
+		if !slices.ContainsFunc(fields, func(f model.FormField) bool { return f.Key == key }) {
+			problems[key] = "is not a question on this form"
+		}
+	}
+
+	clean := map[string]any{}
+	for _, f := range fields {
+		if f.VisibleIf != nil && !conditionMet(f.VisibleIf, clean[f.VisibleIf.Field]) {
+			continue
+		}
+		value, problem := checkAnswer(f, answers[f.Key])
+		switch {
+		case problem != "":
+			problems[f.Key] = problem
+		case value != nil:
+			clean[f.Key] = value
+		case complete && f.Required:
+			problems[f.Key] = "is required"
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &InvalidAnswersError{Fields: problems}
+	}
+	return clean, nil
+}
+
+// checkAnswer returns the cleaned up answer to f, nil for no answer, or what
+// is wrong with it.
+func checkAnswer(f model.FormField, v any) (any, string) {
+	if v == nil {
+		return nil, ""
+	}
+
+	switch f.Type {
+	case model.FieldNumber:
+		n, ok := v.(float64)
+		if !ok {
+			return nil, "must be a number"
+		}
+		if f.Min != nil && n < *f.Min || f.Max != nil && n > *f.Max {
+			return nil, "is out of range"
+		}
+		return n, ""
+
+	case model.FieldCheckbox:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, "must be true or false"
+		}
+		if !b && f.Required {
+			return nil, ""
+		}
+		return b, ""
+
+	case model.FieldMultiSelect:
+		items, ok := v.([]any)
+		if !ok {
+			return nil, "must be a list of options"
+		}
+		choices := []string{}
+		for _, item := range items {
+			o, ok := item.(string)
+			if !ok || !slices.Contains(f.Options, o) {
+				return nil, "has an unknown option"
+			}
+			if !slices.Contains(choices, o) {
+				choices = append(choices, o)
+			}
+		}
+		if len(choices) == 0 {
+			return nil, ""
+		}
+		if outOfRange(f, len(choices)) {
+			return nil, "has too few or too many choices"
+		}
+		return choices, ""
+	}
+
+	str, ok := v.(string)
+	if !ok {
+		return nil, "must be text"
+	}
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return nil, ""
+	}
+
+	switch f.Type {
+	case model.FieldSelect:
+		if !slices.Contains(f.Options, str) {
+			return nil, "has an unknown option"
+		}
+	case model.FieldDate:
+		if _, err := time.Parse(time.DateOnly, str); err != nil {
+			return nil, "must be a date like 2026-01-31"
+		}
+	case model.FieldEmail:
+		if _, err := mail.ParseAddress(str); err != nil {
+			return nil, "must be an email address"
+		}
+	case model.FieldURL:
+		u, err := url.ParseRequestURI(str)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, "must be an http or https link"
+		}
+	}
+
+	if slices.Contains(textFieldTypes, f.Type) {
+		if outOfRange(f, utf8.RuneCountInString(str)) {
+			return nil, "is too short or too long"
+		}
+		if f.Pattern != "" {
+			if re, err := regexp.Compile(f.Pattern); err == nil && !re.MatchString(str) {
+				return nil, "is not in the expected format"
+			}
+		}
+	}
+	return str, ""
+}
+
+func outOfRange(f model.FormField, n int) bool {
+	return f.Min != nil && float64(n) < *f.Min || f.Max != nil && float64(n) > *f.Max
+}
+
+// conditionMet reports whether an answer satisfies c. Unanswered fields
+// never do.
+func conditionMet(c *model.FormCondition, v any) bool {
+	switch v := v.(type) {
+	case string:
+		return slices.Contains(c.Values, v)
+	case bool:
+		return slices.Contains(c.Values, strconv.FormatBool(v))
+	case float64:
+		return slices.Contains(c.Values, strconv.FormatFloat(v, 'f', -1, 64))
+	case []string:
+		return slices.ContainsFunc(v, func(o string) bool { return slices.Contains(c.Values, o) })
+	}
+	return false
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
 	"github.com/rudraa2005/mic-website-main/backend/internal/model"
@@ -14,8 +17,17 @@ var (
 	ErrInvalidEmail      = errors.New("invalid email domain")
 	ErrUserDoesNotExist  = errors.New("User does not exist, Signup first")
 	ErrIncorrectPassword = errors.New("Incorrect Password")
+
+	ErrInvalidCredentials    = errors.New("invalid email or password")
+	ErrInvalidRefreshToken   = errors.New("invalid or expired refresh token")
+	ErrEmailNotVerified      = errors.New("email address has not been verified")
+	ErrRoleNotHeld           = errors.New("you do not hold that role")
+	ErrPasswordLoginDisabled = errors.New("password login is disabled for your role, sign in with single sign-on")
 )
 
+// dummyPasswordHash is compared against when a login names an unknown email.
+var dummyPasswordHash, _ = auth.HashPassword("mic-website-dummy-password")
+
 type UserRepository interface {
 	Create(ctx context.Context, user *model.User) (string, error)
 	FindByEmail(email string) (*model.User, error)
@@ -23,8 +35,12 @@ type UserRepository interface {
 	GetByID(ctx context.Context, userID string) (*model.User, error)
 }
 
-type FacultyRepository interface {
-	FindByEmail(email string) (*model.Faculty, error)
+type SessionRepository interface {
+	Create(ctx context.Context, s *model.Session, refreshTokenHash string) error
+	Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*model.Session, error)
+	SetActiveRole(ctx context.Context, sessionID, userID, role string) error
+	Revoke(ctx context.Context, sessionID, userID string) error
+	RevokeAllForUser(ctx context.Context, userID string) error
 }
 
 type SettingsRepository interface {
@@ -34,35 +50,99 @@ type ProfileRepository interface {
 	StoreUser(ctx context.Context, p model.Profile) error
 }
 
+// LoginResult is the outcome of the password step of a login. Either Tokens
+// is set, or MFAToken is set and the client must call CompleteMFALogin.
+type LoginResult struct {
+	User     *model.User
+	Tokens   *TokenPair
+	MFAToken string
+	// MFAEnrollmentRequired tells the client the user's role requires 2FA
+	// but they have not set it up yet.
+	MFAEnrollmentRequired bool
+}
+
+// TokenPair is what a successful login or refresh hands back to the client.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int
+}
+
+// PasswordValidator checks a new password for an account. Policy failures
+// are *auth.PasswordPolicyError.
+type PasswordValidator interface {
+	Validate(password, email, name string) error
+}
+
 type AuthService struct {
 	userRepo     UserRepository
-	facultyRepo  FacultyRepository
 	profileRepo  ProfileRepository
 	settingsRepo SettingsRepository
+	sessionRepo  SessionRepository
+	accessTokens AccessTokenRevoker
+	verification *EmailVerificationService
+	mfa          *MFAService
+	throttle     *LoginThrottle
+	roles        *RoleService
+	passwords    PasswordValidator
+
+	// passwordLoginDisabled holds roles that may only sign in through SSO
+	passwordLoginDisabled map[string]bool
 }
 
 func NewAuthService(
 	userRepo UserRepository,
-	facultyRepo FacultyRepository,
 	profileRepo ProfileRepository,
 	settingsRepo SettingsRepository,
+	sessionRepo SessionRepository,
+	accessTokens AccessTokenRevoker,
+	verification *EmailVerificationService,
+	mfa *MFAService,
+	throttle *LoginThrottle,
+	roles *RoleService,
+	passwords PasswordValidator,
 ) *AuthService {
 	return &AuthService{
 		userRepo:     userRepo,
-		facultyRepo:  facultyRepo,
 		profileRepo:  profileRepo,
 		settingsRepo: settingsRepo,
+		sessionRepo:  sessionRepo,
+		accessTokens: accessTokens,
+		verification: verification,
+		mfa:          mfa,
+		throttle:     throttle,
+		roles:        roles,
+		passwords:    passwords,
 	}
 }
 
-func (s *AuthService) Signup(ctx context.Context, email string, password string, name string) error {
+// Signup creates an account whose role comes from the role policy. With a
+// valid inviteToken the invite's role is used and, since the invite link
+// was emailed, the address counts as verified.
+func (s *AuthService) Signup(ctx context.Context, email string, password string, name string, inviteToken string, requestedIP string) error {
 	_, err := s.userRepo.FindByEmail(email)
 	if err == nil {
 		return ErrUserAlreadyExists
 	}
 
-	role, err := defineRole(email)
-	if err != nil {
+	var role string
+	invited := inviteToken != ""
+	if invited {
+		inv, err := s.roles.CheckInvite(ctx, inviteToken, email)
+		if err != nil {
+			return err
+		}
+		role = inv.Role
+	} else {
+		role, err = s.roles.ResolveRole(ctx, email, "")
+		if err != nil {
+			return err
+		}
+	}
+	if s.passwordLoginDisabled[role] {
+		return ErrPasswordLoginDisabled
+	}
+	if err := s.passwords.Validate(password, email, name); err != nil {
 		return err
 	}
 
@@ -93,56 +173,258 @@ func (s *AuthService) Signup(ctx context.Context, email string, password string,
 	}
 	_ = s.settingsRepo.CreateDefaults(ctx, user.UserID)
 
-	return nil
+	if err := s.roles.AccountCreated(ctx, email); err != nil {
+		return err
+	}
+
+	if invited {
+		return s.verification.MarkVerified(ctx, user.UserID)
+	}
+	return s.verification.SendVerification(ctx, user, requestedIP)
 }
 
-func defineRole(email string) (string, error) {
-	if strings.HasSuffix(email, "@learner.manipal.edu") {
-		return "STUDENT", nil
+// DisablePasswordLogin stops users with the given roles from signing up or
+// logging in with a local password.
+func (s *AuthService) DisablePasswordLogin(roles []string) {
+	s.passwordLoginDisabled = make(map[string]bool)
+	for _, role := range roles {
+		s.passwordLoginDisabled[strings.ToUpper(strings.TrimSpace(role))] = true
 	}
-	if strings.HasSuffix(email, "@manipal.edu") {
-		return "FACULTY", nil
+}
+
+// Login checks a password and starts a session. Wrong passwords and unknown
+// emails fail with the same ErrInvalidCredentials, and both count towards the
+// lockout for the account and the client IP.
+func (s *AuthService) Login(ctx context.Context, email string, password string, meta model.SessionMeta) (*LoginResult, error) {
+	if err := s.throttle.Check(ctx, email, meta.IPAddress); err != nil {
+		return nil, err
 	}
 
-	return "", ErrInvalidEmail
-}
+	user, err := s.checkPassword(ctx, email, password)
+	if err != nil {
+		if err := s.throttle.RecordFailure(ctx, email, meta.IPAddress); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
 
-func (s *AuthService) Login(email string, password string) (*model.User, string, error) {
+	if err := s.throttle.RecordSuccess(ctx, email); err != nil {
+		return nil, err
+	}
+	for _, role := range userRoles(user) {
+		if s.passwordLoginDisabled[role] {
+			return nil, ErrPasswordLoginDisabled
+		}
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	return s.afterFirstFactor(ctx, user, meta)
+}
 
+// checkPassword looks the email up and compares the password. An unknown
+// email still costs a hash comparison so response times do not reveal
+// which accounts exist. Hashes in an older format are upgraded on success.
+func (s *AuthService) checkPassword(ctx context.Context, email string, password string) (*model.User, error) {
 	user, err := s.userRepo.FindByEmail(email)
-	if err == nil {
-		if !auth.CompareHashedPassword(user.HashedPassword, password) {
-			return nil, "", ErrIncorrectPassword
+	if err != nil {
+		auth.CompareHashedPassword(dummyPasswordHash, password)
+		return nil, ErrUserDoesNotExist
+	}
+
+	if !auth.CompareHashedPassword(user.HashedPassword, password) {
+		return nil, ErrIncorrectPassword
+	}
+
+	if auth.NeedsRehash(user.HashedPassword) {
+		if hashed, err := auth.HashPassword(password); err == nil {
+			if err := s.userRepo.UpdatePassword(ctx, user.UserID, hashed); err != nil {
+				log.Println("[AUTH] failed to upgrade password hash:", err)
+			}
 		}
+	}
+	return user, nil
+}
+
+// afterFirstFactor runs once a password or SSO login has identified the user.
+// It either starts a session or, if the user has 2FA enabled, hands back a
+// challenge token for the second step.
+func (s *AuthService) afterFirstFactor(ctx context.Context, user *model.User, meta model.SessionMeta) (*LoginResult, error) {
+	enabled, err := s.mfa.IsEnabled(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
 
-		token, err := auth.CreateToken(user.UserID, user.Role, email)
+	if enabled {
+		challenge, err := auth.CreateMFAChallengeToken(user.UserID)
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
+		return &LoginResult{User: user, MFAToken: challenge}, nil
+	}
+
+	required, err := s.mfa.IsRequired(ctx, userRoles(user))
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.startSession(ctx, user, meta, false)
+	if err != nil {
+		return nil, err
+	}
 
-		return user, token, nil
+	return &LoginResult{User: user, Tokens: tokens, MFAEnrollmentRequired: required}, nil
+}
+
+// CompleteMFALogin finishes a login that was paused for a second factor.
+func (s *AuthService) CompleteMFALogin(ctx context.Context, mfaToken string, code string, recoveryCode string, meta model.SessionMeta) (*LoginResult, error) {
+	userID, err := auth.ParseMFAChallengeToken(mfaToken)
+	if err != nil {
+		return nil, ErrInvalidMFACode
 	}
 
-	faculty, err := s.facultyRepo.FindByEmail(email)
+	user, err := s.findUserByID(ctx, userID)
 	if err != nil {
-		return nil, "", ErrUserDoesNotExist
+		return nil, err
 	}
 
-	if !auth.CompareHashedPassword(faculty.PasswordHash, password) {
-		return nil, "", ErrIncorrectPassword
+	if err := s.throttle.Check(ctx, user.Email, meta.IPAddress); err != nil {
+		return nil, err
+	}
+	if err := s.mfa.Verify(ctx, userID, code, recoveryCode); err != nil {
+		if err := s.throttle.RecordFailure(ctx, user.Email, meta.IPAddress); err != nil {
+			return nil, err
+		}
+		return nil, err
 	}
 
-	token, err := auth.CreateToken(faculty.ID, faculty.Role, faculty.Email)
+	tokens, err := s.startSession(ctx, user, meta, true)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	return &model.User{
-		UserID: faculty.ID,
-		Email:  faculty.Email,
-		Role:   faculty.Role,
-		Name:   faculty.Name,
-	}, token, nil
+	return &LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. The old refresh token stops working immediately.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	newRefresh, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.Rotate(
+		ctx,
+		auth.HashToken(refreshToken),
+		auth.HashToken(newRefresh),
+		time.Now().Add(auth.RefreshTTL()),
+	)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.findUserByID(ctx, session.UserID)
+	if err != nil {
+		_ = s.sessionRepo.Revoke(ctx, session.ID, session.UserID)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Fall back to the default role if the one the session was acting as
+	// has since been taken away
+	roles := userRoles(user)
+	role := session.ActiveRole
+	if !slices.Contains(roles, role) {
+		role = user.Role
+	}
+
+	access, err := auth.CreateToken(user.UserID, role, roles, user.Email, session.ID, session.MFAVerified)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: newRefresh,
+		ExpiresIn:    int(auth.AccessTTL().Seconds()),
+	}, nil
+}
+
+// SwitchRole makes the caller's session act as another role they hold and
+// returns an access token for it.
+func (s *AuthService) SwitchRole(ctx context.Context, userID string, sessionID string, role string, mfaVerified bool) (string, error) {
+	user, err := s.findUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	roles := userRoles(user)
+	if !slices.Contains(roles, role) {
+		return "", ErrRoleNotHeld
+	}
+
+	if err := s.sessionRepo.SetActiveRole(ctx, sessionID, userID, role); err != nil {
+		return "", err
+	}
+
+	return auth.CreateToken(user.UserID, role, roles, user.Email, sessionID, mfaVerified)
+}
+
+// Logout revokes the session the caller's access token belongs to.
+func (s *AuthService) Logout(ctx context.Context, userID string, sessionID string) error {
+	return s.sessionRepo.Revoke(ctx, sessionID, userID)
+}
+
+func (s *AuthService) startSession(ctx context.Context, user *model.User, meta model.SessionMeta, mfaVerified bool) (*TokenPair, error) {
+	refresh, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &model.Session{
+		UserID:      user.UserID,
+		UserAgent:   meta.UserAgent,
+		IPAddress:   meta.IPAddress,
+		ExpiresAt:   time.Now().Add(auth.RefreshTTL()),
+		MFAVerified: mfaVerified,
+		ActiveRole:  user.Role,
+	}
+	if err := s.sessionRepo.Create(ctx, session, auth.HashToken(refresh)); err != nil {
+		return nil, err
+	}
+
+	access, err := auth.CreateToken(user.UserID, user.Role, userRoles(user), user.Email, session.ID, mfaVerified)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(auth.AccessTTL().Seconds()),
+	}, nil
+}
+
+func (s *AuthService) findUserByID(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserDoesNotExist
+	}
+	return user, nil
+}
+
+// userRoles returns every role the user holds, which always includes their
+// default role.
+func userRoles(user *model.User) []string {
+	if len(user.Roles) == 0 {
+		return []string{user.Role}
+	}
+	return user.Roles
 }
 
 func (s *AuthService) ChangePassword(ctx context.Context, userID string, currentPassword string, newPassword string) error {
@@ -151,17 +433,24 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID string, current
 	if err != nil {
 		return ErrUserDoesNotExist
 	}
-	ok := auth.CompareHashedPassword(user.HashedPassword, currentPassword)
+	if !auth.CompareHashedPassword(user.HashedPassword, currentPassword) {
+		return ErrIncorrectPassword
+	}
+	if err := s.passwords.Validate(newPassword, user.Email, user.Name); err != nil {
+		return err
+	}
+
 	newHashedPassword, err := auth.HashPassword(newPassword)
 	if err != nil {
 		return errors.New("Unable to Hash Password")
 	}
 
-	if !ok {
-		return ErrIncorrectPassword
+	if err := s.userRepo.UpdatePassword(ctx, userID, newHashedPassword); err != nil {
+		return err
 	}
 
-	err = s.userRepo.UpdatePassword(ctx, userID, newHashedPassword)
-
-	return err
+	if err := s.accessTokens.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.sessionRepo.RevokeAllForUser(ctx, userID)
 }
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/email"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	// maxVerificationEmailsPerHour caps resends for a single account.
+	maxVerificationEmailsPerHour = 3
+)
+
+var ErrInvalidVerificationToken = errors.New("verification link is invalid or has expired")
+
+type EmailVerifier interface {
+	MarkEmailVerified(ctx context.Context, userID string) error
+}
+
+type EmailVerificationService struct {
+	userRepo     UserRepository
+	verifier     EmailVerifier
+	tokenRepo    UserTokenRepository
+	emailService email.Service
+	baseURL      string
+}
+
+func NewEmailVerificationService(
+	userRepo UserRepository,
+	verifier EmailVerifier,
+	tokenRepo UserTokenRepository,
+	emailService email.Service,
+	baseURL string,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:     userRepo,
+		verifier:     verifier,
+		tokenRepo:    tokenRepo,
+		emailService: emailService,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// SendVerification issues a new verification token for the user and emails
+// the link to them.
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *model.User, requestedIP string) error {
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.tokenRepo.Create(
+		ctx,
+		user.UserID,
+		model.TokenPurposeEmailVerification,
+		auth.HashToken(token),
+		time.Now().Add(emailVerificationTTL),
+		requestedIP,
+	)
+	if err != nil {
+		return err
+	}
+
+	link := s.baseURL + "/verify-email?token=" + token
+	body := "Dear " + user.Name + ",\n\nWelcome to the MAHE Innovation Centre portal.\n\n" +
+		"Please confirm your email address within 24 hours by opening the link below:\n" + link + "\n\n" +
+		"If you did not create an account, you can ignore this email.\n\nBest regards,\nMAHE Innovation Centre"
+
+	go func() {
+		if err := s.emailService.Send(user.Email, "Verify your email address", body); err != nil {
+			log.Println("[EMAIL FAILED]", err)
+		}
+	}()
+
+	return nil
+}
+
+// Verify marks the owner of the token as verified.
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) error {
+	if token == "" {
+		return ErrInvalidVerificationToken
+	}
+
+	userID, err := s.tokenRepo.Consume(ctx, model.TokenPurposeEmailVerification, auth.HashToken(token))
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	if err := s.verifier.MarkEmailVerified(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.tokenRepo.InvalidateForUser(ctx, userID, model.TokenPurposeEmailVerification)
+}
+
+// MarkVerified marks a user as verified without a token, for addresses proven
+// some other way such as an emailed invite.
+func (s *EmailVerificationService) MarkVerified(ctx context.Context, userID string) error {
+	return s.verifier.MarkEmailVerified(ctx, userID)
+}
+
+// Resend emails a fresh verification link to an unverified account. Like a
+// password reset request it reveals nothing about whether the account exists.
+func (s *EmailVerificationService) Resend(ctx context.Context, emailAddr string, requestedIP string) {
+	user, err := s.userRepo.FindByEmail(strings.TrimSpace(emailAddr))
+	if err != nil || user.EmailVerifiedAt != nil {
+		return
+	}
+
+	recent, err := s.tokenRepo.CountRecent(ctx, user.UserID, model.TokenPurposeEmailVerification, time.Now().Add(-time.Hour))
+	if err != nil {
+		log.Println("[EMAIL VERIFICATION] count failed:", err)
+		return
+	}
+	if recent >= maxVerificationEmailsPerHour {
+		log.Println("[EMAIL VERIFICATION] rate limit reached for user", user.UserID)
+		return
+	}
+
+	if err := s.SendVerification(ctx, user, requestedIP); err != nil {
+		log.Println("[EMAIL VERIFICATION] resend failed:", err)
+	}
+}
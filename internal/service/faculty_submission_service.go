@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"io"
 
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
 	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
 )
 
@@ -13,17 +16,23 @@ var (
 )
 
 type FacultyReviewService struct {
-	repo                *repository.FacultySubmissionRepo
-	notificationService *NotificationService
+	repo      *repository.FacultySubmissionRepo
+	workflow  *SubmissionWorkflow
+	revisions *SubmissionRevisionService
+	files     *SubmissionFileService
 }
 
 func NewFacultyReviewService(
 	repo *repository.FacultySubmissionRepo,
-	notificationService *NotificationService,
+	workflow *SubmissionWorkflow,
+	revisions *SubmissionRevisionService,
+	files *SubmissionFileService,
 ) *FacultyReviewService {
 	return &FacultyReviewService{
-		repo:                repo,
-		notificationService: notificationService,
+		repo:      repo,
+		workflow:  workflow,
+		revisions: revisions,
+		files:     files,
 	}
 }
 
@@ -33,6 +42,11 @@ func (s *FacultyReviewService) GetSubmitted(
 	return s.repo.GetSubmitted(ctx)
 }
 
+// Search finds submissions reviewers can see by their text and filters.
+func (s *FacultyReviewService) Search(ctx context.Context, f model.SubmissionSearch) (*model.SubmissionSearchResults, error) {
+	return s.repo.Search(ctx, f, facultyVisibleStatuses)
+}
+
 func (s *FacultyReviewService) GetByID(
 	ctx context.Context,
 	id string,
@@ -40,40 +54,70 @@ func (s *FacultyReviewService) GetByID(
 	return s.repo.GetByID(ctx, id)
 }
 
+// Decide records a reviewer's decision: approved, rejected or
+// needs_improvement.
 func (s *FacultyReviewService) Decide(
 	ctx context.Context,
 	submissionID string,
-	facultyID string,
+	actor *auth.Claims,
 	decision string,
+	reason string,
 ) error {
 
-	if submissionID == "" || facultyID == "" {
+	if submissionID == "" || actor == nil {
 		return ErrSubmissionNotProcessed
 	}
 
 	switch decision {
-	case "approved":
-		email, title, err := s.repo.ApproveSubmission(ctx, submissionID, facultyID)
-		if err == nil {
-			s.notificationService.SendSubmissionStatusUpdate(ctx, email, title, "approved (Under Incubation)")
-		}
+	case model.SubmissionApproved, model.SubmissionRejected, model.SubmissionNeedsImprovement:
+		_, err := s.workflow.Transition(ctx, submissionID, decision, actor, reason)
 		return err
+	default:
+		return ErrInvalidDecision
+	}
+}
 
-	case "rejected":
-		email, title, err := s.repo.RejectSubmission(ctx, submissionID, facultyID)
-		if err == nil {
-			s.notificationService.SendSubmissionStatusUpdate(ctx, email, title, "rejected")
-		}
-		return err
+// Timeline returns the status history of a submission under review.
+func (s *FacultyReviewService) Timeline(ctx context.Context, submissionID string) ([]model.StatusChange, error) {
+	return s.workflow.ReviewerTimeline(ctx, submissionID)
+}
 
-	case "needs_improvement":
-		email, title, err := s.repo.NeedsImprovement(ctx, submissionID, facultyID)
-		if err == nil {
-			s.notificationService.SendSubmissionStatusUpdate(ctx, email, title, "needs improvement - please revise your submission")
-		}
-		return err
+// Revisions lists the saved versions of a submission under review.
+func (s *FacultyReviewService) Revisions(ctx context.Context, submissionID string) ([]model.SubmissionRevision, error) {
+	if err := s.workflow.CheckReviewer(ctx, submissionID); err != nil {
+		return nil, err
+	}
+	return s.revisions.List(ctx, submissionID)
+}
 
-	default:
-		return ErrInvalidDecision
+func (s *FacultyReviewService) Revision(ctx context.Context, submissionID string, number int) (*model.SubmissionRevision, error) {
+	if err := s.workflow.CheckReviewer(ctx, submissionID); err != nil {
+		return nil, err
+	}
+	return s.revisions.Get(ctx, submissionID, number)
+}
+
+// RevisionDiff shows what changed between two versions of a submission
+// under review.
+func (s *FacultyReviewService) RevisionDiff(ctx context.Context, submissionID string, from, to int) (*model.RevisionDiff, error) {
+	if err := s.workflow.CheckReviewer(ctx, submissionID); err != nil {
+		return nil, err
+	}
+	return s.revisions.Diff(ctx, submissionID, from, to)
+}
+
+// Files lists the attachments of a submission under review.
+func (s *FacultyReviewService) Files(ctx context.Context, submissionID string) ([]model.SubmissionFile, error) {
+	if err := s.workflow.CheckReviewer(ctx, submissionID); err != nil {
+		return nil, err
+	}
+	return s.files.List(ctx, submissionID)
+}
+
+// OpenFile returns one attachment of a submission under review for download.
+func (s *FacultyReviewService) OpenFile(ctx context.Context, submissionID, fileID string) (*model.SubmissionFile, io.ReadCloser, error) {
+	if err := s.workflow.CheckReviewer(ctx, submissionID); err != nil {
+		return nil, nil, err
 	}
+	return s.files.Open(ctx, submissionID, fileID)
 }
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+const impersonationTTL = 15 * time.Minute
+
+var ErrCannotImpersonate = errors.New("you cannot impersonate yourself or another admin")
+
+type ImpersonationRepository interface {
+	Create(ctx context.Context, imp *model.Impersonation) error
+	IsActive(ctx context.Context, id string) (bool, error)
+	End(ctx context.Context, id, adminID string) error
+	List(ctx context.Context, limit int) ([]model.Impersonation, error)
+	LogRequest(ctx context.Context, id, method, path string, status int) error
+	ListRequests(ctx context.Context, id string) ([]model.ImpersonationRequest, error)
+}
+
+type UserLookup interface {
+	GetByID(ctx context.Context, userID string) (*model.User, error)
+}
+
+// ImpersonationService lets admins view the site as another user with a
+// short-lived token, and keeps the audit trail of what they did.
+type ImpersonationService struct {
+	repo  ImpersonationRepository
+	users UserLookup
+}
+
+func NewImpersonationService(repo ImpersonationRepository, users UserLookup) *ImpersonationService {
+	return &ImpersonationService{repo: repo, users: users}
+}
+
+// ImpersonationStart is the token handed to the admin together with the
+// record it is audited under.
+type ImpersonationStart struct {
+	Token         string               `json:"token"`
+	ExpiresIn     int                  `json:"expires_in"`
+	Impersonation *model.Impersonation `json:"impersonation"`
+}
+
+// Start issues a token for userID on behalf of admin. Tokens are read-only
+// unless allowWrites is set.
+func (s *ImpersonationService) Start(ctx context.Context, admin *auth.Claims, userID, reason string, allowWrites bool) (*ImpersonationStart, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, errors.New("a reason is required")
+	}
+	if userID == admin.UserID {
+		return nil, ErrCannotImpersonate
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles := userRoles(user)
+	if slices.Contains(roles, "ADMIN") {
+		return nil, ErrCannotImpersonate
+	}
+
+	imp := &model.Impersonation{
+		AdminID:   admin.UserID,
+		UserID:    user.UserID,
+		UserEmail: user.Email,
+		SessionID: admin.SessionID,
+		Reason:    reason,
+		ReadOnly:  !allowWrites,
+		ExpiresAt: time.Now().Add(impersonationTTL),
+	}
+	if err := s.repo.Create(ctx, imp); err != nil {
+		return nil, err
+	}
+
+	token, err := auth.CreateImpersonationToken(user.UserID, user.Role, roles, user.Email, admin.SessionID, admin.MFA, admin.UserID, imp.ID, imp.ReadOnly, impersonationTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImpersonationStart{
+		Token:         token,
+		ExpiresIn:     int(impersonationTTL.Seconds()),
+		Impersonation: imp,
+	}, nil
+}
+
+// End stops an impersonation early. Its token stops working at once.
+func (s *ImpersonationService) End(ctx context.Context, id, adminID string) error {
+	return s.repo.End(ctx, id, adminID)
+}
+
+func (s *ImpersonationService) IsActive(ctx context.Context, id string) (bool, error) {
+	return s.repo.IsActive(ctx, id)
+}
+
+func (s *ImpersonationService) LogRequest(ctx context.Context, id, method, path string, status int) error {
+	return s.repo.LogRequest(ctx, id, method, path, status)
+}
+
+func (s *ImpersonationService) List(ctx context.Context) ([]model.Impersonation, error) {
+	return s.repo.List(ctx, 100)
+}
+
+func (s *ImpersonationService) ListRequests(ctx context.Context, id string) ([]model.ImpersonationRequest, error) {
+	return s.repo.ListRequests(ctx, id)
+}
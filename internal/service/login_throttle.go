@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+// Failed logins are tracked per account and per client IP. Once a key passes
+// its threshold every further failure locks it for twice as long as the last,
+// starting at loginLockBase and capped at loginLockMax.
+const (
+	accountFailureThreshold = 5
+	ipFailureThreshold      = 20
+	loginLockBase           = 30 * time.Second
+	loginLockMax            = time.Hour
+	// loginFailureMemory is how long a quiet counter is kept before it resets.
+	loginFailureMemory = 24 * time.Hour
+)
+
+// LoginLockedError is returned while an account or IP is locked out.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+type LoginAttemptRepository interface {
+	LockedUntil(ctx context.Context, key string) (*time.Time, error)
+	RecordFailure(ctx context.Context, key string, resetAfter time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Clear(ctx context.Context, key string) error
+	ListLocked(ctx context.Context) ([]model.LoginAttempt, error)
+}
+
+type LoginThrottle struct {
+	repo LoginAttemptRepository
+}
+
+func NewLoginThrottle(repo LoginAttemptRepository) *LoginThrottle {
+	return &LoginThrottle{repo: repo}
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check returns a *LoginLockedError if either the account or the IP is
+// currently locked.
+func (t *LoginThrottle) Check(ctx context.Context, email string, ip string) error {
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		until, err := t.repo.LockedUntil(ctx, key)
+		if err != nil {
+			return err
+		}
+		if until != nil {
+			return &LoginLockedError{RetryAfter: time.Until(*until)}
+		}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed password or second-factor attempt against
+// both the account and the IP, locking whichever has passed its threshold.
+func (t *LoginThrottle) RecordFailure(ctx context.Context, email string, ip string) error {
+	if err := t.recordFailure(ctx, accountKey(email), accountFailureThreshold); err != nil {
+		return err
+	}
+	return t.recordFailure(ctx, ipKey(ip), ipFailureThreshold)
+}
+
+func (t *LoginThrottle) recordFailure(ctx context.Context, key string, threshold int) error {
+	failures, err := t.repo.RecordFailure(ctx, key, loginFailureMemory)
+	if err != nil {
+		return err
+	}
+	if failures < threshold {
+		return nil
+	}
+	return t.repo.Lock(ctx, key, time.Now().Add(lockDuration(failures-threshold)))
+}
+
+func lockDuration(excess int) time.Duration {
+	d := loginLockBase
+	for i := 0; i < excess && d < loginLockMax; i++ {
+		d *= 2
+	}
+	if d > loginLockMax {
+		d = loginLockMax
+	}
+	return d
+}
+
+// RecordSuccess resets the account counter. The IP counter is left alone so
+// one valid login cannot be used to keep guessing other accounts.
+func (t *LoginThrottle) RecordSuccess(ctx context.Context, email string) error {
+	return t.repo.Clear(ctx, accountKey(email))
+}
+
+// ListLockouts returns the accounts and IPs that are locked right now.
+func (t *LoginThrottle) ListLockouts(ctx context.Context) ([]model.LoginAttempt, error) {
+	return t.repo.ListLocked(ctx)
+}
+
+// ClearLockout lifts a lockout, given its key as returned by ListLockouts.
+func (t *LoginThrottle) ClearLockout(ctx context.Context, key string) error {
+	return t.repo.Clear(ctx, key)
+}
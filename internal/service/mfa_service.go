@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrInvalidMFACode    = errors.New("invalid two-factor code")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFARequiredByRole = errors.New("two-factor authentication is mandatory for your role")
+	ErrInvalidRole       = errors.New("invalid role")
+)
+
+type MFARepository interface {
+	GetByUserID(ctx context.Context, userID string) (*model.UserMFA, error)
+	SavePending(ctx context.Context, userID, secret string) error
+	Enable(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error
+	Disable(ctx context.Context, userID string) error
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error)
+	IsRequired(ctx context.Context, role string) (bool, error)
+	GetPolicies(ctx context.Context) ([]model.MFAPolicy, error)
+	SetPolicy(ctx context.Context, role string, required bool, updatedBy string) error
+}
+
+type MFASessionMarker interface {
+	MarkMFAVerified(ctx context.Context, sessionID string) error
+}
+
+type MFAService struct {
+	repo     MFARepository
+	sessions MFASessionMarker
+	issuer   string
+}
+
+func NewMFAService(repo MFARepository, sessions MFASessionMarker, issuer string) *MFAService {
+	return &MFAService{
+		repo:     repo,
+		sessions: sessions,
+		issuer:   issuer,
+	}
+}
+
+// MFAStatus is what the settings page shows about a user's second factor.
+type MFAStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// MFAEnrollment is returned when a user starts setting up an authenticator.
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+func (s *MFAService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	m, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMFANotEnrolled) {
+			return false, nil
+		}
+		return false, err
+	}
+	return m.EnabledAt != nil, nil
+}
+
+// IsRequired reports whether the policy for any of roles makes MFA mandatory.
+func (s *MFAService) IsRequired(ctx context.Context, roles []string) (bool, error) {
+	for _, role := range roles {
+		required, err := s.repo.IsRequired(ctx, role)
+		if err != nil || required {
+			return required, err
+		}
+	}
+	return false, nil
+}
+
+func (s *MFAService) Status(ctx context.Context, userID string, roles []string) (*MFAStatus, error) {
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.IsRequired(ctx, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MFAStatus{Enabled: enabled, Required: required}
+	if enabled {
+		status.RecoveryCodesRemaining, err = s.repo.CountUnusedRecoveryCodes(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// BeginEnrollment generates a new TOTP secret for the user. It is not active
+// until ConfirmEnrollment sees a valid code from it.
+func (s *MFAService) BeginEnrollment(ctx context.Context, userID string, email string) (*MFAEnrollment, error) {
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SavePending(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(secret, s.issuer, email),
+	}, nil
+}
+
+// ConfirmEnrollment enables MFA once the user proves their authenticator
+// works. The current session counts as MFA-verified from then on. The
+// returned recovery codes are shown once and never stored in plain text.
+func (s *MFAService) ConfirmEnrollment(ctx context.Context, userID string, sessionID string, code string) ([]string, error) {
+	m, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, ErrMFANotEnabled
+	}
+	if m.EnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, ok := auth.ValidateTOTP(m.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Enable(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	if sessionID != "" {
+		if err := s.sessions.MarkMFAVerified(ctx, sessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+// Verify checks a TOTP code or, failing that, a recovery code. Each TOTP step
+// and each recovery code is accepted only once.
+func (s *MFAService) Verify(ctx context.Context, userID string, code string, recoveryCode string) error {
+	m, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil || m.EnabledAt == nil {
+		return ErrMFANotEnabled
+	}
+
+	if code != "" {
+		step, ok := auth.ValidateTOTP(m.TOTPSecret, code, time.Now())
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		fresh, err := s.repo.UseStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	if recoveryCode != "" {
+		used, err := s.repo.ConsumeRecoveryCode(ctx, userID, auth.HashToken(auth.NormalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	return ErrInvalidMFACode
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking
+// a current TOTP code.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns MFA off, unless the policy for one of the user's roles
+// requires it.
+func (s *MFAService) Disable(ctx context.Context, userID string, roles []string, code string) error {
+	required, err := s.IsRequired(ctx, roles)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequiredByRole
+	}
+
+	if err := s.Verify(ctx, userID, code, ""); err != nil {
+		return err
+	}
+	return s.repo.Disable(ctx, userID)
+}
+
+func (s *MFAService) GetPolicies(ctx context.Context) ([]model.MFAPolicy, error) {
+	return s.repo.GetPolicies(ctx)
+}
+
+func (s *MFAService) SetPolicy(ctx context.Context, role string, required bool, adminID string) error {
+	if !IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	return s.repo.SetPolicy(ctx, role, required, adminID)
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := auth.NewRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = code
+		hashes[i] = auth.HashToken(code)
+	}
+	return codes, hashes, nil
+}
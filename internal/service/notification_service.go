@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"log"
+	"strconv"
 
 	"github.com/rudraa2005/mic-website-main/backend/internal/email"
 	"github.com/rudraa2005/mic-website-main/backend/internal/model"
@@ -95,6 +96,80 @@ func (ns *NotificationService) NotifyStatusChange(ctx context.Context, userID st
 	return nil
 
 }
+
+type TeamLister interface {
+	List(ctx context.Context, submissionID string) ([]model.SubmissionMember, error)
+}
+
+// SubmissionTransitionHook returns a workflow hook that tells everyone on a
+// submission's team about changes to it.
+func (ns *NotificationService) SubmissionTransitionHook(team TeamLister) TransitionHook {
+	return func(ctx context.Context, ev TransitionEvent) error {
+		var status string
+		switch ev.To {
+		case model.SubmissionSubmitted:
+		case model.SubmissionApproved:
+			status = "approved (Under Incubation)"
+		case model.SubmissionRejected:
+			status = "rejected"
+		case model.SubmissionNeedsImprovement:
+			status = "needs improvement - please revise your submission"
+		default:
+			return nil
+		}
+
+		sub := ev.Submission
+		members, err := team.List(ctx, sub.SubmissionID)
+		if err != nil {
+			return err
+		}
+		for _, m := range members {
+			if status == "" {
+				err = ns.NotifyStatusChange(ctx, m.UserID, m.Email, sub.SubmissionID, ev.From, ev.To)
+			} else {
+				err = ns.SendSubmissionStatusUpdate(ctx, m.Email, sub.Title, status)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+type ReviewerLister interface {
+	ReviewerEmails(ctx context.Context, submissionID string) ([]string, error)
+}
+
+// ResubmissionHook returns a workflow hook that tells the assigned faculty
+// when a student resubmits a revised submission.
+func (ns *NotificationService) ResubmissionHook(reviewers ReviewerLister) TransitionHook {
+	return func(ctx context.Context, ev TransitionEvent) error {
+		if ev.From != model.SubmissionRevising {
+			return nil
+		}
+		emails, err := reviewers.ReviewerEmails(ctx, ev.Submission.SubmissionID)
+		if err != nil {
+			return err
+		}
+
+		title := ev.Submission.Title
+		subject := "Revised Submission: " + title
+		body := "Dear Reviewer,\n\nThe idea '" + title + "' has been revised and resubmitted for your review (revision round " +
+			strconv.Itoa(ev.Submission.RevisionRound) + ").\n\nResponse to reviewers:\n" + ev.Reason +
+			"\n\nBest regards,\nMAHE Innovation Centre"
+
+		for _, addr := range emails {
+			go func(addr string) {
+				if err := ns.emailService.Send(addr, subject, body); err != nil {
+					log.Println("[EMAIL FAILED]", err)
+				}
+			}(addr)
+		}
+		return nil
+	}
+}
+
 func (ns *NotificationService) SendSubmissionStatusUpdate(ctx context.Context, email, title, status string) error {
 	subject := "Submission Update: " + title
 	body := "Dear User,\n\nYour idea '" + title + "' has been " + status + " by the faculty review committee.\n\nBest regards,\nMAHE Innovation Centre"
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/email"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+const (
+	passwordResetTTL = time.Hour
+	// maxResetRequestsPerHour caps how many reset emails one account can
+	// receive, so the endpoint cannot be used to flood a mailbox.
+	maxResetRequestsPerHour = 3
+)
+
+var ErrInvalidResetToken = errors.New("reset link is invalid or has expired")
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, userID, purpose, tokenHash string, expiresAt time.Time, requestedIP string) error
+	Consume(ctx context.Context, purpose, tokenHash string) (string, error)
+	Lookup(ctx context.Context, purpose, tokenHash string) (string, error)
+	CountRecent(ctx context.Context, userID, purpose string, since time.Time) (int, error)
+	InvalidateForUser(ctx context.Context, userID, purpose string) error
+}
+
+type PasswordResetService struct {
+	userRepo     UserRepository
+	tokenRepo    UserTokenRepository
+	sessionRepo  SessionRepository
+	accessTokens AccessTokenRevoker
+	emailService email.Service
+	passwords    PasswordValidator
+	baseURL      string
+}
+
+func NewPasswordResetService(
+	userRepo UserRepository,
+	tokenRepo UserTokenRepository,
+	sessionRepo SessionRepository,
+	accessTokens AccessTokenRevoker,
+	emailService email.Service,
+	passwords PasswordValidator,
+	baseURL string,
+) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		sessionRepo:  sessionRepo,
+		accessTokens: accessTokens,
+		emailService: emailService,
+		passwords:    passwords,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// RequestReset emails a reset link if the account exists. It never reports
+// whether it did, so callers cannot use it to probe for accounts.
+func (s *PasswordResetService) RequestReset(ctx context.Context, emailAddr string, requestedIP string) {
+	user, err := s.userRepo.FindByEmail(strings.TrimSpace(emailAddr))
+	if err != nil {
+		return
+	}
+
+	recent, err := s.tokenRepo.CountRecent(ctx, user.UserID, model.TokenPurposePasswordReset, time.Now().Add(-time.Hour))
+	if err != nil {
+		log.Println("[PASSWORD RESET] count failed:", err)
+		return
+	}
+	if recent >= maxResetRequestsPerHour {
+		log.Println("[PASSWORD RESET] rate limit reached for user", user.UserID)
+		return
+	}
+
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		log.Println("[PASSWORD RESET] token generation failed:", err)
+		return
+	}
+
+	err = s.tokenRepo.Create(
+		ctx,
+		user.UserID,
+		model.TokenPurposePasswordReset,
+		auth.HashToken(token),
+		time.Now().Add(passwordResetTTL),
+		requestedIP,
+	)
+	if err != nil {
+		log.Println("[PASSWORD RESET] store failed:", err)
+		return
+	}
+
+	link := s.baseURL + "/reset-password?token=" + token
+	body := "Dear User,\n\nWe received a request to reset the password for your MIC account.\n\n" +
+		"Use the link below within the next hour to choose a new password:\n" + link + "\n\n" +
+		"If you did not ask for this, you can ignore this email.\n\nBest regards,\nMAHE Innovation Centre"
+
+	go func() {
+		if err := s.emailService.Send(user.Email, "Reset your password", body); err != nil {
+			log.Println("[EMAIL FAILED]", err)
+		}
+	}()
+}
+
+// ResetPassword sets a new password using a token from a reset email. The
+// token is burnt and every existing session of the user is signed out.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+	if newPassword == "" {
+		return errors.New("new password is required")
+	}
+
+	// Check the new password before using up the link, so a rejected
+	// password can be retried
+	userID, err := s.tokenRepo.Lookup(ctx, model.TokenPurposePasswordReset, auth.HashToken(token))
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	if err := s.passwords.Validate(newPassword, user.Email, user.Name); err != nil {
+		return err
+	}
+
+	userID, err = s.tokenRepo.Consume(ctx, model.TokenPurposePasswordReset, auth.HashToken(token))
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	hashed, err := auth.HashPassword(newPassword)
+	if err != nil {
+		return errors.New("Unable to Hash Password")
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, hashed); err != nil {
+		return err
+	}
+
+	if err := s.tokenRepo.InvalidateForUser(ctx, userID, model.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	if err := s.accessTokens.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.sessionRepo.RevokeAllForUser(ctx, userID)
+}
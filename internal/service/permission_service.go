@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+// permissionCacheTTL bounds how long another instance may keep serving a
+// mapping after an admin changes it. Changes made through this instance
+// apply immediately.
+const permissionCacheTTL = time.Minute
+
+var ErrAdminLockout = errors.New("roles.manage cannot be removed from ADMIN")
+
+type PermissionRepository interface {
+	ListPermissions(ctx context.Context) ([]model.Permission, error)
+	ListRoles(ctx context.Context) ([]model.RolePermissions, error)
+	RolePermissions(ctx context.Context, role string) ([]string, error)
+	SetRolePermissions(ctx context.Context, role string, permissions []string, updatedBy string) error
+}
+
+type cachedPermissions struct {
+	permissions []string
+	loadedAt    time.Time
+}
+
+// PermissionService answers which permissions a role grants, caching the
+// lookups RequirePermission makes on every request, and lets admins edit
+// the role-to-permission mapping.
+type PermissionService struct {
+	repo PermissionRepository
+
+	mu    sync.Mutex
+	cache map[string]cachedPermissions
+}
+
+func NewPermissionService(repo PermissionRepository) *PermissionService {
+	return &PermissionService{
+		repo:  repo,
+		cache: make(map[string]cachedPermissions),
+	}
+}
+
+// RolePermissions returns the permissions granted to role.
+func (s *PermissionService) RolePermissions(ctx context.Context, role string) ([]string, error) {
+	s.mu.Lock()
+	c, ok := s.cache[role]
+	s.mu.Unlock()
+	if ok && time.Since(c.loadedAt) < permissionCacheTTL {
+		return c.permissions, nil
+	}
+
+	perms, err := s.repo.RolePermissions(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[role] = cachedPermissions{permissions: perms, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return perms, nil
+}
+
+// HasPermission reports whether any of roles grants permission.
+func (s *PermissionService) HasPermission(ctx context.Context, roles []string, permission string) (bool, error) {
+	for _, role := range roles {
+		perms, err := s.RolePermissions(ctx, role)
+		if err != nil {
+			return false, err
+		}
+		if slices.Contains(perms, permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// UserPermissions returns the union of the permissions granted by roles.
+func (s *PermissionService) UserPermissions(ctx context.Context, roles []string) ([]string, error) {
+	res := []string{}
+	for _, role := range roles {
+		perms, err := s.RolePermissions(ctx, role)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range perms {
+			if !slices.Contains(res, p) {
+				res = append(res, p)
+			}
+		}
+	}
+	slices.Sort(res)
+	return res, nil
+}
+
+func (s *PermissionService) ListPermissions(ctx context.Context) ([]model.Permission, error) {
+	return s.repo.ListPermissions(ctx)
+}
+
+func (s *PermissionService) ListRoles(ctx context.Context) ([]model.RolePermissions, error) {
+	return s.repo.ListRoles(ctx)
+}
+
+// SetRolePermissions replaces what role grants. Every name must be a known
+// permission, and ADMIN always keeps roles.manage so the mapping cannot be
+// locked.
+func (s *PermissionService) SetRolePermissions(ctx context.Context, role string, permissions []string, adminID string) error {
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	known, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range permissions {
+		if !slices.ContainsFunc(known, func(k model.Permission) bool { return k.Name == p }) {
+			return fmt.Errorf("unknown permission %q", p)
+		}
+	}
+	if role == "ADMIN" && !slices.Contains(permissions, "roles.manage") {
+		return ErrAdminLockout
+	}
+
+	if err := s.repo.SetRolePermissions(ctx, role, permissions, adminID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.cache, role)
+	s.mu.Unlock()
+	return nil
+}
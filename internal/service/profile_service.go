@@ -3,23 +3,40 @@ package service
 import (
 	"context"
 	"errors"
+	"io"
 
 	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/storage"
 )
 
 type ProfileRepo interface {
 	GetUserByID(ctx context.Context, userID string) (*model.Profile, error)
-	UpdatePhotoURL(ctx context.Context, userID string, photoURL string) error
+	UpdatePhoto(ctx context.Context, userID string, photoURL string, size int64) error
 	UpdateProfile(ctx context.Context, userID string, name string, phone *string, bio string) error
 }
 
+// ProfilePhotoPrefix is the key prefix of profile photos in the blob store.
+const ProfilePhotoPrefix = "photos"
+
+// ProfilePhotoURL is where the photo with the given SHA-256 is served.
+func ProfilePhotoURL(sum string) string {
+	return "/api/profile/photos/" + sum
+}
+
+// profilePhotoTypes are the image formats accepted as profile photos.
+var profilePhotoTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
 type ProfileService struct {
 	profileRepo ProfileRepo
+	uploads     *UploadInspector
+	blobs       storage.Blob
 }
 
-func NewProfileService(profileRepo ProfileRepo) *ProfileService {
+func NewProfileService(profileRepo ProfileRepo, uploads *UploadInspector, blobs storage.Blob) *ProfileService {
 	return &ProfileService{
 		profileRepo: profileRepo,
+		uploads:     uploads,
+		blobs:       blobs,
 	}
 }
 
@@ -42,6 +59,37 @@ func (ps *ProfileService) UpdateProfile(ctx context.Context, userID string, name
 	return err
 }
 
-func (ps *ProfileService) UpdatePhotoURL(ctx context.Context, userID string, photoURL string) error {
-	return ps.profileRepo.UpdatePhotoURL(ctx, userID, photoURL)
+// UploadPhoto checks and stores a new profile photo and points the profile
+// at it.
+func (ps *ProfileService) UploadPhoto(ctx context.Context, userID, fileName string, body io.Reader) (string, error) {
+	profile, err := ps.profileRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	stored, err := ps.uploads.Store(ctx, &Upload{
+		UserID:       userID,
+		FileName:     fileName,
+		Body:         body,
+		AllowedTypes: profilePhotoTypes,
+		Replaces:     profile.PhotoSize,
+		Prefix:       ProfilePhotoPrefix,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	photoURL := ProfilePhotoURL(stored.SHA256)
+	if err := ps.profileRepo.UpdatePhoto(ctx, userID, photoURL, stored.Size); err != nil {
+		return "", err
+	}
+	return photoURL, nil
+}
+
+// Photo returns the stored photo with the given SHA-256.
+func (ps *ProfileService) Photo(ctx context.Context, sum string) (io.ReadCloser, error) {
+	if !storage.IsSHA256(sum) {
+		return nil, storage.ErrNotFound
+	}
+	return ps.blobs.Get(ctx, storage.ContentKey(ProfilePhotoPrefix, sum))
 }
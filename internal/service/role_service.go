@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/email"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+)
+
+const roleInviteTTL = 14 * 24 * time.Hour
+
+var ErrInvalidInvite = errors.New("invite is invalid or has expired")
+
+// Roles lists every role an account can hold. What each role may do is
+// stored in role_permissions.
+var Roles = []string{"ADMIN", "FACULTY", "STUDENT"}
+
+// IsValidRole reports whether role is one of Roles.
+func IsValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type RolePolicyRepository interface {
+	FindOverride(ctx context.Context, email string) (string, error)
+	FindDomainRule(ctx context.Context, domain string) (string, error)
+	ListDomainRules(ctx context.Context) ([]model.RoleDomainRule, error)
+	SetDomainRule(ctx context.Context, domain, role, createdBy string) error
+	DeleteDomainRule(ctx context.Context, domain string) error
+	ListOverrides(ctx context.Context) ([]model.RoleEmailOverride, error)
+	SetOverride(ctx context.Context, email, role, note, createdBy string) error
+	DeleteOverride(ctx context.Context, email string) error
+	CreateInvite(ctx context.Context, inv *model.RoleInvite, tokenHash string) error
+	FindPendingInvite(ctx context.Context, email string) (*model.RoleInvite, error)
+	FindInviteByToken(ctx context.Context, tokenHash string) (*model.RoleInvite, error)
+	AcceptInvites(ctx context.Context, email string) error
+	ListInvites(ctx context.Context) ([]model.RoleInvite, error)
+	RevokeInvite(ctx context.Context, id string) error
+	ChangeUserRole(ctx context.Context, userID, role, changedBy, reason string) (string, error)
+	GrantRole(ctx context.Context, userID, role, grantedBy, reason string) error
+	RevokeRole(ctx context.Context, userID, role, revokedBy, reason string) error
+	ListAudit(ctx context.Context, userID string) ([]model.RoleAuditEntry, error)
+}
+
+// RoleService decides which role a new account gets and lets admins manage
+// that policy and change existing users' roles.
+type RoleService struct {
+	repo         RolePolicyRepository
+	sessions     SessionRepository
+	accessTokens AccessTokenRevoker
+	emailService email.Service
+	baseURL      string
+}
+
+func NewRoleService(repo RolePolicyRepository, sessions SessionRepository, accessTokens AccessTokenRevoker, emailService email.Service, baseURL string) *RoleService {
+	return &RoleService{
+		repo:         repo,
+		sessions:     sessions,
+		accessTokens: accessTokens,
+		emailService: emailService,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// ResolveRole picks the role for a new account: an email override first, then
+// a pending invite, then claimRole (from an SSO provider, may be empty), then
+// the rule for the email's domain.
+func (s *RoleService) ResolveRole(ctx context.Context, emailAddr string, claimRole string) (string, error) {
+	emailAddr = normalizeEmail(emailAddr)
+
+	role, err := s.repo.FindOverride(ctx, emailAddr)
+	if err == nil {
+		return role, nil
+	}
+	if !errors.Is(err, repository.ErrRoleRuleNotFound) {
+		return "", err
+	}
+
+	inv, err := s.repo.FindPendingInvite(ctx, emailAddr)
+	if err == nil {
+		return inv.Role, nil
+	}
+	if !errors.Is(err, repository.ErrInviteNotFound) {
+		return "", err
+	}
+
+	if IsValidRole(claimRole) {
+		return claimRole, nil
+	}
+
+	_, domain, ok := strings.Cut(emailAddr, "@")
+	if !ok {
+		return "", ErrInvalidEmail
+	}
+	role, err = s.repo.FindDomainRule(ctx, domain)
+	if errors.Is(err, repository.ErrRoleRuleNotFound) {
+		return "", ErrInvalidEmail
+	}
+	return role, err
+}
+
+// CheckInvite returns the pending invite for a token, which must have been
+// sent to emailAddr.
+func (s *RoleService) CheckInvite(ctx context.Context, token string, emailAddr string) (*model.RoleInvite, error) {
+	inv, err := s.repo.FindInviteByToken(ctx, auth.HashToken(token))
+	if err != nil {
+		return nil, ErrInvalidInvite
+	}
+	if inv.Email != normalizeEmail(emailAddr) {
+		return nil, ErrInvalidInvite
+	}
+	return inv, nil
+}
+
+// AccountCreated closes any invites for the new account's email.
+func (s *RoleService) AccountCreated(ctx context.Context, emailAddr string) error {
+	return s.repo.AcceptInvites(ctx, normalizeEmail(emailAddr))
+}
+
+func (s *RoleService) ListDomainRules(ctx context.Context) ([]model.RoleDomainRule, error) {
+	return s.repo.ListDomainRules(ctx)
+}
+
+func (s *RoleService) SetDomainRule(ctx context.Context, domain, role, adminID string) error {
+	domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+	if domain == "" || strings.Contains(domain, "@") {
+		return errors.New("invalid domain")
+	}
+	if !IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	return s.repo.SetDomainRule(ctx, domain, role, adminID)
+}
+
+func (s *RoleService) DeleteDomainRule(ctx context.Context, domain string) error {
+	return s.repo.DeleteDomainRule(ctx, strings.ToLower(strings.TrimSpace(domain)))
+}
+
+func (s *RoleService) ListOverrides(ctx context.Context) ([]model.RoleEmailOverride, error) {
+	return s.repo.ListOverrides(ctx)
+}
+
+func (s *RoleService) SetOverride(ctx context.Context, emailAddr, role, note, adminID string) error {
+	emailAddr = normalizeEmail(emailAddr)
+	if !strings.Contains(emailAddr, "@") {
+		return ErrInvalidEmail
+	}
+	if !IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	return s.repo.SetOverride(ctx, emailAddr, role, note, adminID)
+}
+
+func (s *RoleService) DeleteOverride(ctx context.Context, emailAddr string) error {
+	return s.repo.DeleteOverride(ctx, normalizeEmail(emailAddr))
+}
+
+// Invite emails a signup link that grants role to emailAddr.
+func (s *RoleService) Invite(ctx context.Context, emailAddr, role, adminID string) (*model.RoleInvite, error) {
+	emailAddr = normalizeEmail(emailAddr)
+	if !strings.Contains(emailAddr, "@") {
+		return nil, ErrInvalidEmail
+	}
+	if !IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &model.RoleInvite{
+		Email:     emailAddr,
+		Role:      role,
+		InvitedBy: &adminID,
+		ExpiresAt: time.Now().Add(roleInviteTTL),
+	}
+	if err := s.repo.CreateInvite(ctx, inv, auth.HashToken(token)); err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("invite", token)
+	q.Set("email", emailAddr)
+	link := s.baseURL + "/login?" + q.Encode()
+	body := "Hello,\n\nYou have been invited to join the MAHE Innovation Centre portal as " +
+		strings.ToLower(role) + ".\n\nCreate your account within 14 days using the link below:\n" + link +
+		"\n\nBest regards,\nMAHE Innovation Centre"
+
+	go func() {
+		if err := s.emailService.Send(emailAddr, "You're invited to the MAHE Innovation Centre portal", body); err != nil {
+			log.Println("[EMAIL FAILED]", err)
+		}
+	}()
+
+	return inv, nil
+}
+
+func (s *RoleService) ListInvites(ctx context.Context) ([]model.RoleInvite, error) {
+	return s.repo.ListInvites(ctx)
+}
+
+func (s *RoleService) RevokeInvite(ctx context.Context, id string) error {
+	return s.repo.RevokeInvite(ctx, id)
+}
+
+// ChangeUserRole moves a user to a new default role in place of the old one,
+// records who did it and why, and signs the user out so their next token
+// carries the new role.
+func (s *RoleService) ChangeUserRole(ctx context.Context, userID, role, adminID, reason string) error {
+	if !IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("a reason is required")
+	}
+
+	if _, err := s.repo.ChangeUserRole(ctx, userID, role, adminID, reason); err != nil {
+		return err
+	}
+	return s.signOut(ctx, userID)
+}
+
+// GrantRole lets a user act as an additional role. It shows up in their
+// token at the next refresh.
+func (s *RoleService) GrantRole(ctx context.Context, userID, role, adminID, reason string) error {
+	if !IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("a reason is required")
+	}
+	return s.repo.GrantRole(ctx, userID, role, adminID, reason)
+}
+
+// RevokeRole takes an additional role away and signs the user out, since
+// their current tokens still carry it.
+func (s *RoleService) RevokeRole(ctx context.Context, userID, role, adminID, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("a reason is required")
+	}
+	if err := s.repo.RevokeRole(ctx, userID, role, adminID, reason); err != nil {
+		return err
+	}
+	return s.signOut(ctx, userID)
+}
+
+// signOut ends the user's sessions and disables their access tokens, which
+// were created under the roles they held at the time.
+func (s *RoleService) signOut(ctx context.Context, userID string) error {
+	if err := s.accessTokens.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.sessions.RevokeAllForUser(ctx, userID)
+}
+
+func (s *RoleService) ListAudit(ctx context.Context, userID string) ([]model.RoleAuditEntry, error) {
+	return s.repo.ListAudit(ctx, userID)
+}
+
+func normalizeEmail(e string) string {
+	return strings.ToLower(strings.TrimSpace(e))
+}
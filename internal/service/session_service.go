@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+type ActiveSessionRepository interface {
+	ListActive(ctx context.Context, userID string) ([]model.Session, error)
+	Revoke(ctx context.Context, sessionID, userID string) error
+	RevokeOthers(ctx context.Context, userID, keepID string) (int64, error)
+}
+
+// SessionService lets users review where they are signed in and end
+// sessions on other devices.
+type SessionService struct {
+	repo ActiveSessionRepository
+}
+
+func NewSessionService(repo ActiveSessionRepository) *SessionService {
+	return &SessionService{repo: repo}
+}
+
+// List returns the user's live sessions, marking currentID as the current one.
+func (s *SessionService) List(ctx context.Context, userID, currentID string) ([]model.ActiveSession, error) {
+	sessions, err := s.repo.ListActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]model.ActiveSession, 0, len(sessions))
+	for _, sess := range sessions {
+		out = append(out, model.ActiveSession{
+			ID:         sess.ID,
+			Device:     describeDevice(sess.UserAgent),
+			UserAgent:  sess.UserAgent,
+			IPAddress:  sess.IPAddress,
+			LastSeenIP: sess.LastSeenIP,
+			CreatedAt:  sess.CreatedAt,
+			LastSeenAt: sess.LastSeenAt,
+			ExpiresAt:  sess.ExpiresAt,
+			Current:    sess.ID == currentID,
+		})
+	}
+	return out, nil
+}
+
+// Revoke signs one of the user's sessions out. Its access tokens stop
+// working straight away.
+func (s *SessionService) Revoke(ctx context.Context, userID, sessionID string) error {
+	return s.repo.Revoke(ctx, sessionID, userID)
+}
+
+// RevokeOthers signs the user out everywhere except currentID.
+func (s *SessionService) RevokeOthers(ctx context.Context, userID, currentID string) (int64, error) {
+	return s.repo.RevokeOthers(ctx, userID, currentID)
+}
+
+// describeDevice turns a User-Agent into something like "Firefox on Windows".
+func describeDevice(ua string) string {
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "OPR/"), strings.Contains(ua, "Opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"), strings.Contains(ua, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	case strings.HasPrefix(ua, "curl/"):
+		return "curl"
+	}
+
+	platform := "unknown OS"
+	switch {
+	case strings.Contains(ua, "Android"):
+		platform = "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		platform = "iOS"
+	case strings.Contains(ua, "Windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "Mac OS X"), strings.Contains(ua, "Macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "CrOS"):
+		platform = "ChromeOS"
+	case strings.Contains(ua, "Linux"):
+		platform = "Linux"
+	}
+
+	return browser + " on " + platform
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/oidc"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+)
+
+// OIDCLoginStateTTL bounds how long a user may spend at the identity provider.
+const OIDCLoginStateTTL = 10 * time.Minute
+
+var (
+	ErrSSOLoginFailed    = errors.New("single sign-on failed")
+	ErrSSOEmailMissing   = errors.New("identity provider did not share a verified email address")
+	ErrSSORoleNotAllowed = errors.New("your account is not allowed to use this portal")
+)
+
+type IdentityRepository interface {
+	CreateLoginState(ctx context.Context, stateHash string, s model.OIDCLoginState) error
+	ConsumeLoginState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error)
+	FindUserID(ctx context.Context, issuer, subject string) (string, error)
+	Link(ctx context.Context, userID, issuer, subject, email string) error
+}
+
+type SSOService struct {
+	provider   *oidc.Provider
+	identities IdentityRepository
+	verifier   EmailVerifier
+	auth       *AuthService
+}
+
+func NewSSOService(provider *oidc.Provider, identities IdentityRepository, verifier EmailVerifier, authService *AuthService) *SSOService {
+	return &SSOService{
+		provider:   provider,
+		identities: identities,
+		verifier:   verifier,
+		auth:       authService,
+	}
+}
+
+// Begin records a new login attempt and returns the identity provider URL to
+// send the browser to, along with the state the callback must carry.
+// redirectTo is where the frontend goes after login.
+func (s *SSOService) Begin(ctx context.Context, redirectTo string) (string, string, error) {
+	state, err := oidc.NewRandomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.NewRandomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.NewRandomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.identities.CreateLoginState(ctx, auth.HashToken(state), model.OIDCLoginState{
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		RedirectTo:   redirectTo,
+		ExpiresAt:    time.Now().Add(OIDCLoginStateTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// Callback completes a login from the provider's redirect. It returns the
+// login result along with the redirect target given to Begin.
+func (s *SSOService) Callback(ctx context.Context, state string, code string, meta model.SessionMeta) (*LoginResult, string, error) {
+	if state == "" || code == "" {
+		return nil, "", ErrSSOLoginFailed
+	}
+
+	loginState, err := s.identities.ConsumeLoginState(ctx, auth.HashToken(state))
+	if err != nil {
+		return nil, "", ErrSSOLoginFailed
+	}
+
+	idToken, err := s.provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return nil, loginState.RedirectTo, err
+	}
+
+	user, err := s.resolveUser(ctx, idToken)
+	if err != nil {
+		return nil, loginState.RedirectTo, err
+	}
+
+	result, err := s.auth.afterFirstFactor(ctx, user, meta)
+	return result, loginState.RedirectTo, err
+}
+
+// resolveUser finds the account linked to the token's subject. On a first
+// login it links an existing account with the same verified email, or
+// provisions a new one.
+func (s *SSOService) resolveUser(ctx context.Context, t *oidc.IDToken) (*model.User, error) {
+	userID, err := s.identities.FindUserID(ctx, t.Issuer, t.Subject)
+	if err == nil {
+		return s.auth.findUserByID(ctx, userID)
+	}
+	if !errors.Is(err, repository.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(t.Email))
+	if email == "" || !t.EmailVerified {
+		return nil, ErrSSOEmailMissing
+	}
+
+	user, err := s.auth.userRepo.FindByEmail(email)
+	if err == nil {
+		if user.EmailVerifiedAt == nil {
+			if err := s.claimUnverified(ctx, user); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.identities.Link(ctx, user.UserID, t.Issuer, t.Subject, email); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	user, err = s.provision(ctx, t, email)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.identities.Link(ctx, user.UserID, t.Issuer, t.Subject, email); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// claimUnverified hands an account whose email was never verified to the
+// provider's verified owner of that address. Whoever registered it may not
+// own the address, so their password and sessions stop working.
+func (s *SSOService) claimUnverified(ctx context.Context, user *model.User) error {
+	if err := s.auth.userRepo.UpdatePassword(ctx, user.UserID, ""); err != nil {
+		return err
+	}
+	if err := s.auth.sessionRepo.RevokeAllForUser(ctx, user.UserID); err != nil {
+		return err
+	}
+	if err := s.verifier.MarkEmailVerified(ctx, user.UserID); err != nil {
+		return err
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	user.HashedPassword = ""
+	return nil
+}
+
+// provision creates the users, profiles and settings rows for a first SSO
+// login. The provider's role claim is passed to the role policy, where email
+// overrides and invites still take precedence over it.
+func (s *SSOService) provision(ctx context.Context, t *oidc.IDToken, email string) (*model.User, error) {
+	role, err := s.auth.roles.ResolveRole(ctx, email, s.provider.Config().MapRole(t.Groups))
+	if errors.Is(err, ErrInvalidEmail) {
+		return nil, ErrSSORoleNotAllowed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := t.Name
+	if name == "" {
+		name = email
+	}
+
+	// An empty hash never matches, so the account has no usable local
+	// password until the user sets one through a password reset.
+	user := &model.User{
+		Email: email,
+		Role:  role,
+		Name:  name,
+	}
+	if _, err := s.auth.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.verifier.MarkEmailVerified(ctx, user.UserID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	user.Roles = []string{role}
+
+	profile := model.Profile{
+		UserID: user.UserID,
+		Email:  email,
+		Name:   name,
+	}
+	if err := s.auth.profileRepo.StoreUser(ctx, profile); err != nil {
+		return nil, err
+	}
+	_ = s.auth.settingsRepo.CreateDefaults(ctx, user.UserID)
+
+	if err := s.auth.roles.AccountCreated(ctx, email); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
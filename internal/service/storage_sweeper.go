@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/storage"
+)
+
+// storageReleaseGrace is how long a released object is kept before it is
+// deleted. It only has to outlast an upload that is reusing the object.
+const storageReleaseGrace = time.Hour
+
+type StorageReleaseRepository interface {
+	Release(ctx context.Context, key string) error
+	Claim(ctx context.Context, key string) error
+}
+
+type StorageSweepRepository interface {
+	SweepOne(ctx context.Context, cutoff time.Time, remove func(ctx context.Context, key string) error) (bool, error)
+}
+
+// StorageSweeper deletes stored objects that nothing has referred to since
+// they were released.
+type StorageSweeper struct {
+	repo  StorageSweepRepository
+	blobs storage.Blob
+}
+
+func NewStorageSweeper(repo StorageSweepRepository, blobs storage.Blob) *StorageSweeper {
+	return &StorageSweeper{repo: repo, blobs: blobs}
+}
+
+// Sweep deletes every object released more than storageReleaseGrace ago and
+// not referred to again, returning how many releases it handled.
+func (s *StorageSweeper) Sweep(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-storageReleaseGrace)
+	n := 0
+	for {
+		more, err := s.repo.SweepOne(ctx, cutoff, s.blobs.Delete)
+		if err != nil || !more {
+			return n, err
+		}
+		n++
+	}
+}
+
+// Run sweeps every interval until ctx is done.
+func (s *StorageSweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.Sweep(ctx); err != nil {
+			log.Println("[STORAGE] sweep failed:", err)
+		} else if n > 0 {
+			log.Println("[STORAGE] swept", n, "released objects")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/storage"
+)
+
+// SubmissionFilePrefix is the key prefix of attachments in the blob store.
+const SubmissionFilePrefix = "submissions"
+
+var (
+	ErrInvalidSlot = errors.New("slot key must be lowercase letters, digits and underscores, a label is required, and allowed types must be MIME types")
+	slotKeyPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// MissingFilesError stops a submit when required slots are empty.
+type MissingFilesError struct {
+	Slots []string
+}
+
+func (e *MissingFilesError) Error() string {
+	return "missing required files: " + strings.Join(e.Slots, ", ")
+}
+
+type SubmissionFileRepository interface {
+	ListSlots(ctx context.Context, cycleID *string) ([]model.SubmissionFileSlot, error)
+	CycleSlots(ctx context.Context, cycleID *string) ([]model.SubmissionFileSlot, error)
+	SubmissionSlots(ctx context.Context, submissionID string) ([]model.SubmissionFileSlot, error)
+	GetSlot(ctx context.Context, submissionID, key string) (*model.SubmissionFileSlot, error)
+	SetSlot(ctx context.Context, s *model.SubmissionFileSlot, updatedBy string) error
+	DeleteSlot(ctx context.Context, cycleID *string, key string) error
+	MissingRequired(ctx context.Context, submissionID string) ([]string, error)
+	List(ctx context.Context, submissionID string) ([]model.SubmissionFile, error)
+	Get(ctx context.Context, submissionID, fileID string) (*model.SubmissionFile, error)
+	Put(ctx context.Context, f *model.SubmissionFile) (*model.SubmissionFile, error)
+	Delete(ctx context.Context, submissionID, fileID string) (*model.SubmissionFile, error)
+}
+
+// SubmissionFileService stores the attachments of submissions, one file per
+// slot. Uploads and deletes are limited to the owner while the submission is
+// editable; reading is left to callers to authorize.
+type SubmissionFileService struct {
+	repo      SubmissionFileRepository
+	workflow  *SubmissionWorkflow
+	revisions *SubmissionRevisionService
+	uploads   *UploadInspector
+	releases  StorageReleaseRepository
+	blobs     storage.Blob
+}
+
+func NewSubmissionFileService(repo SubmissionFileRepository, workflow *SubmissionWorkflow, revisions *SubmissionRevisionService, uploads *UploadInspector, releases StorageReleaseRepository, blobs storage.Blob) *SubmissionFileService {
+	return &SubmissionFileService{repo: repo, workflow: workflow, revisions: revisions, uploads: uploads, releases: releases, blobs: blobs}
+}
+
+// Slots lists the slots defined for a cycle, or the default set if cycleID
+// is nil.
+func (s *SubmissionFileService) Slots(ctx context.Context, cycleID *string) ([]model.SubmissionFileSlot, error) {
+	return s.repo.ListSlots(ctx, cycleID)
+}
+
+// CycleSlots lists the slots submissions of a cycle fill, falling back to
+// the default set.
+func (s *SubmissionFileService) CycleSlots(ctx context.Context, cycleID *string) ([]model.SubmissionFileSlot, error) {
+	return s.repo.CycleSlots(ctx, cycleID)
+}
+
+// SubmissionSlots lists the slots of a submission's cycle.
+func (s *SubmissionFileService) SubmissionSlots(ctx context.Context, submissionID string) ([]model.SubmissionFileSlot, error) {
+	return s.repo.SubmissionSlots(ctx, submissionID)
+}
+
+// SetSlot creates or updates a slot of slot.CycleID, or of the default set.
+func (s *SubmissionFileService) SetSlot(ctx context.Context, slot *model.SubmissionFileSlot, adminID string) error {
+	slot.Label = strings.TrimSpace(slot.Label)
+	if !slotKeyPattern.MatchString(slot.Key) || slot.Label == "" {
+		return ErrInvalidSlot
+	}
+
+	types := []string{}
+	for _, t := range slot.AllowedTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if _, _, err := mime.ParseMediaType(t); err != nil || !strings.Contains(t, "/") {
+			return ErrInvalidSlot
+		}
+		types = append(types, t)
+	}
+	slot.AllowedTypes = types
+
+	return s.repo.SetSlot(ctx, slot, adminID)
+}
+
+func (s *SubmissionFileService) DeleteSlot(ctx context.Context, cycleID *string, key string) error {
+	return s.repo.DeleteSlot(ctx, cycleID, key)
+}
+
+// CheckRequired is a workflow guard that stops a submission from being
+// submitted or resubmitted while a required slot is empty.
+func (s *SubmissionFileService) CheckRequired(ctx context.Context, state *model.SubmissionState, _ *auth.Claims) error {
+	if !slices.Contains(editableStatuses, state.Status) {
+		return nil
+	}
+	missing, err := s.repo.MissingRequired(ctx, state.SubmissionID)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return &MissingFilesError{Slots: missing}
+	}
+	return nil
+}
+
+func (s *SubmissionFileService) List(ctx context.Context, submissionID string) ([]model.SubmissionFile, error) {
+	return s.repo.List(ctx, submissionID)
+}
+
+// Open returns a file's record and its stored bytes.
+func (s *SubmissionFileService) Open(ctx context.Context, submissionID, fileID string) (*model.SubmissionFile, io.ReadCloser, error) {
+	f, err := s.repo.Get(ctx, submissionID, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rc, err := s.blobs.Get(ctx, f.StoragePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, rc, nil
+}
+
+// Upload checks body against the slot's allowed types and the user's quota,
+// scans it, and stores it in the slot in place of the file that was there.
+// The type is sniffed from the content; fileName is only kept for display.
+func (s *SubmissionFileService) Upload(ctx context.Context, submissionID, slotKey string, actor *auth.Claims, fileName string, body io.Reader) (*model.SubmissionFile, error) {
+	if err := s.workflow.CheckEditable(ctx, submissionID, actor.UserID); err != nil {
+		return nil, err
+	}
+	slot, err := s.repo.GetSlot(ctx, submissionID, slotKey)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.List(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	var replaces int64
+	for _, f := range current {
+		if f.SlotKey == slotKey && f.UploadedBy != nil && *f.UploadedBy == actor.UserID {
+			replaces = f.SizeBytes
+		}
+	}
+
+	stored, err := s.uploads.Store(ctx, &Upload{
+		UserID:       actor.UserID,
+		FileName:     fileName,
+		Body:         body,
+		AllowedTypes: slot.AllowedTypes,
+		Replaces:     replaces,
+		Prefix:       SubmissionFilePrefix,
+		ExtractText:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	f := &model.SubmissionFile{
+		ID:           uuid.NewString(),
+		SubmissionID: submissionID,
+		SlotKey:      slotKey,
+		FileName:     cleanFileName(fileName),
+		StoragePath:  stored.Key,
+		SizeBytes:    stored.Size,
+		MimeType:     stored.ContentType,
+		Checksum:     &stored.SHA256,
+		UploadedBy:   &actor.UserID,
+		Text:         stored.Text,
+	}
+
+	replaced, err := s.repo.Put(ctx, f)
+	if err != nil {
+		s.release(ctx, f.StoragePath)
+		return nil, err
+	}
+	if replaced != nil {
+		s.release(ctx, replaced.StoragePath)
+	}
+
+	if _, err := s.revisions.Record(ctx, submissionID, model.RevisionSave, actor.UserID); err != nil {
+		log.Println("[FILES] failed to record revision:", err)
+	}
+	return f, nil
+}
+
+// Delete empties a slot of the submission and records a new revision.
+func (s *SubmissionFileService) Delete(ctx context.Context, submissionID, fileID string, actor *auth.Claims) error {
+	if err := s.workflow.CheckEditable(ctx, submissionID, actor.UserID); err != nil {
+		return err
+	}
+
+	f, err := s.repo.Delete(ctx, submissionID, fileID)
+	if err != nil {
+		return err
+	}
+	s.release(ctx, f.StoragePath)
+
+	if _, err := s.revisions.Record(ctx, submissionID, model.RevisionSave, actor.UserID); err != nil {
+		log.Println("[FILES] failed to record revision:", err)
+	}
+	return nil
+}
+
+// release hands a stored object to the StorageSweeper, which deletes it
+// later if no file record or revision points at it by then.
+func (s *SubmissionFileService) release(ctx context.Context, key string) {
+	if err := s.releases.Release(ctx, key); err != nil {
+		log.Println("[FILES] failed to release", key+":", err)
+	}
+}
+
+// cleanFileName keeps only the base name of an uploaded file's name.
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		return "file"
+	}
+	return name
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+var ErrInvalidRevisionRange = errors.New("from and to must be revision numbers")
+
+type SubmissionRevisionRepository interface {
+	Record(ctx context.Context, submissionID, kind string, authorID *string) (*model.SubmissionRevision, error)
+	List(ctx context.Context, submissionID string) ([]model.SubmissionRevision, error)
+	Get(ctx context.Context, submissionID string, number int) (*model.SubmissionRevision, error)
+}
+
+// SubmissionRevisionService keeps the history of a submission's content.
+// Callers check that the user may see the submission.
+type SubmissionRevisionService struct {
+	repo SubmissionRevisionRepository
+}
+
+func NewSubmissionRevisionService(repo SubmissionRevisionRepository) *SubmissionRevisionService {
+	return &SubmissionRevisionService{repo: repo}
+}
+
+// Record snapshots the submission's current content.
+func (s *SubmissionRevisionService) Record(ctx context.Context, submissionID, kind, authorID string) (*model.SubmissionRevision, error) {
+	var author *string
+	if authorID != "" {
+		author = &authorID
+	}
+	return s.repo.Record(ctx, submissionID, kind, author)
+}
+
+// SubmittedHook is a workflow hook that records a revision when the owner
+// submits or resubmits.
+func (s *SubmissionRevisionService) SubmittedHook(ctx context.Context, ev TransitionEvent) error {
+	if ev.From != model.SubmissionDraft && ev.From != model.SubmissionRevising {
+		return nil
+	}
+	_, err := s.Record(ctx, ev.Submission.SubmissionID, model.RevisionSubmit, ev.Actor.UserID)
+	return err
+}
+
+func (s *SubmissionRevisionService) List(ctx context.Context, submissionID string) ([]model.SubmissionRevision, error) {
+	return s.repo.List(ctx, submissionID)
+}
+
+func (s *SubmissionRevisionService) Get(ctx context.Context, submissionID string, number int) (*model.SubmissionRevision, error) {
+	return s.repo.Get(ctx, submissionID, number)
+}
+
+// Diff compares the title and description of two revisions word by word
+// and lists the attachments that changed.
+func (s *SubmissionRevisionService) Diff(ctx context.Context, submissionID string, from, to int) (*model.RevisionDiff, error) {
+	if from < 1 || to < 1 {
+		return nil, ErrInvalidRevisionRange
+	}
+	a, err := s.repo.Get(ctx, submissionID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.repo.Get(ctx, submissionID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	files := diffFiles(a.Files, b.Files)
+	return &model.RevisionDiff{
+		SubmissionID: submissionID,
+		From:         from,
+		To:           to,
+		Title:        diffWords(a.Title, b.Title),
+		Description:  diffWords(a.Description, b.Description),
+		Files:        files,
+		FileChanged:  len(files) > 0,
+	}, nil
+}
+
+// diffFiles lists the slots whose file differs between two revisions.
+func diffFiles(a, b []model.RevisionFile) []model.FileDiff {
+	before := make(map[string]model.RevisionFile, len(a))
+	for _, f := range a {
+		before[f.SlotKey] = f
+	}
+
+	diffs := []model.FileDiff{}
+	for _, f := range b {
+		to := f
+		from, ok := before[f.SlotKey]
+		delete(before, f.SlotKey)
+		switch {
+		case !ok:
+			diffs = append(diffs, model.FileDiff{SlotKey: f.SlotKey, Change: "added", To: &to})
+		case from.FileID != f.FileID || from.Checksum != f.Checksum:
+			diffs = append(diffs, model.FileDiff{SlotKey: f.SlotKey, Change: "replaced", From: &from, To: &to})
+		}
+	}
+	for _, f := range a {
+		if from, ok := before[f.SlotKey]; ok {
+			diffs = append(diffs, model.FileDiff{SlotKey: f.SlotKey, Change: "removed", From: &from})
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+const maxRevisionRoundsLimit = 10
+
+var ErrRevisionLimitReached = errors.New("this submission has used all of its revision rounds")
+
+type SubmissionSettingsRepository interface {
+	Get(ctx context.Context) (*model.SubmissionSettings, error)
+	SetMaxRevisionRounds(ctx context.Context, rounds int, updatedBy string) error
+}
+
+// SubmissionSettingsService holds the workflow limits admins can change.
+type SubmissionSettingsService struct {
+	repo SubmissionSettingsRepository
+}
+
+func NewSubmissionSettingsService(repo SubmissionSettingsRepository) *SubmissionSettingsService {
+	return &SubmissionSettingsService{repo: repo}
+}
+
+func (s *SubmissionSettingsService) Get(ctx context.Context) (*model.SubmissionSettings, error) {
+	return s.repo.Get(ctx)
+}
+
+// SetMaxRevisionRounds limits how many times a submission can be reopened
+// after review. 0 turns revise-and-resubmit off.
+func (s *SubmissionSettingsService) SetMaxRevisionRounds(ctx context.Context, rounds int, adminID string) error {
+	if rounds < 0 || rounds > maxRevisionRoundsLimit {
+		return fmt.Errorf("max revision rounds must be between 0 and %d", maxRevisionRoundsLimit)
+	}
+	return s.repo.SetMaxRevisionRounds(ctx, rounds, adminID)
+}
+
+// CheckRevisionLimit is a workflow guard that stops a submission from being
+// reopened once it has used every revision round.
+func (s *SubmissionSettingsService) CheckRevisionLimit(ctx context.Context, state *model.SubmissionState, _ *auth.Claims) error {
+	settings, err := s.repo.Get(ctx)
+	if err != nil {
+		return err
+	}
+	if state.RevisionRound >= settings.MaxRevisionRounds {
+		return ErrRevisionLimitReached
+	}
+	return nil
+}
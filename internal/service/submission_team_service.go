@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/email"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+)
+
+const teamInviteTTL = 7 * 24 * time.Hour
+
+var (
+	ErrNotSubmissionOwner  = errors.New("only the submission owner can manage its team")
+	ErrInvalidMemberRole   = errors.New("role must be editor or viewer")
+	ErrAlreadyOnTeam       = errors.New("this person is already on the team")
+	ErrInviteEmailMismatch = errors.New("this invite was sent to a different email address")
+	ErrOwnerCannotLeave    = errors.New("the owner cannot leave their own submission")
+)
+
+type SubmissionTeamRepository interface {
+	List(ctx context.Context, submissionID string) ([]model.SubmissionMember, error)
+	SetRole(ctx context.Context, submissionID, userID, role string) error
+	Remove(ctx context.Context, submissionID, userID string) error
+	CreateInvite(ctx context.Context, inv *model.SubmissionInvite, tokenHash string) error
+	ListInvites(ctx context.Context, submissionID string) ([]model.SubmissionInvite, error)
+	FindInvite(ctx context.Context, tokenHash string) (*model.SubmissionInvite, error)
+	RevokeInvite(ctx context.Context, submissionID, inviteID string) error
+	AcceptInvite(ctx context.Context, inviteID, userID string) error
+}
+
+// SubmissionTeamService lets the owner of a submission invite co-founders by
+// email as editors or viewers, and manage them afterwards.
+type SubmissionTeamService struct {
+	repo         SubmissionTeamRepository
+	workflow     *SubmissionWorkflow
+	emailService email.Service
+	baseURL      string
+}
+
+func NewSubmissionTeamService(repo SubmissionTeamRepository, workflow *SubmissionWorkflow, emailService email.Service, baseURL string) *SubmissionTeamService {
+	return &SubmissionTeamService{
+		repo:         repo,
+		workflow:     workflow,
+		emailService: emailService,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func validMemberRole(role string) bool {
+	return role == model.MemberEditor || role == model.MemberViewer
+}
+
+// checkTeamOwner returns ErrSubmissionNotFound unless userID is on the team,
+// and ErrNotSubmissionOwner unless they own the submission.
+func (s *SubmissionTeamService) checkTeamOwner(ctx context.Context, submissionID, userID string) error {
+	role, err := s.workflow.CheckMember(ctx, submissionID, userID)
+	if err != nil {
+		return err
+	}
+	if role != model.MemberOwner {
+		return ErrNotSubmissionOwner
+	}
+	return nil
+}
+
+// Members lists a submission's team to anyone on it.
+func (s *SubmissionTeamService) Members(ctx context.Context, submissionID, userID string) ([]model.SubmissionMember, error) {
+	if _, err := s.workflow.CheckMember(ctx, submissionID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, submissionID)
+}
+
+// Invite emails emailAddr a link to join the submission with role. Any
+// earlier pending invite for the same address stops working.
+func (s *SubmissionTeamService) Invite(ctx context.Context, submissionID, emailAddr, role string, actor *auth.Claims) (*model.SubmissionInvite, error) {
+	if err := s.checkTeamOwner(ctx, submissionID, actor.UserID); err != nil {
+		return nil, err
+	}
+	emailAddr = normalizeEmail(emailAddr)
+	if !strings.Contains(emailAddr, "@") {
+		return nil, ErrInvalidEmail
+	}
+	if !validMemberRole(role) {
+		return nil, ErrInvalidMemberRole
+	}
+
+	members, err := s.repo.List(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		if normalizeEmail(m.Email) == emailAddr {
+			return nil, ErrAlreadyOnTeam
+		}
+	}
+
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &model.SubmissionInvite{
+		SubmissionID: submissionID,
+		Email:        emailAddr,
+		Role:         role,
+		InvitedBy:    &actor.UserID,
+		ExpiresAt:    time.Now().Add(teamInviteTTL),
+	}
+	if err := s.repo.CreateInvite(ctx, inv, auth.HashToken(token)); err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("team_invite", token)
+	link := s.baseURL + "/submissions?" + q.Encode()
+	body := "Hello,\n\n" + actor.Email + " has invited you to work on the idea '" + inv.SubmissionTitle +
+		"' on the MAHE Innovation Centre portal as " + role + ".\n\nSign in with this email address and accept within 7 days using the link below:\n" +
+		link + "\n\nBest regards,\nMAHE Innovation Centre"
+
+	go func() {
+		if err := s.emailService.Send(emailAddr, "You're invited to join '"+inv.SubmissionTitle+"'", body); err != nil {
+			log.Println("[EMAIL FAILED]", err)
+		}
+	}()
+
+	return inv, nil
+}
+
+// Invites lists the pending invites of a submission to its owner.
+func (s *SubmissionTeamService) Invites(ctx context.Context, submissionID, userID string) ([]model.SubmissionInvite, error) {
+	if err := s.checkTeamOwner(ctx, submissionID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListInvites(ctx, submissionID)
+}
+
+func (s *SubmissionTeamService) RevokeInvite(ctx context.Context, submissionID, inviteID, userID string) error {
+	if err := s.checkTeamOwner(ctx, submissionID, userID); err != nil {
+		return err
+	}
+	return s.repo.RevokeInvite(ctx, submissionID, inviteID)
+}
+
+// SetRole changes what a co-founder may do. The owner's role never changes.
+func (s *SubmissionTeamService) SetRole(ctx context.Context, submissionID, memberID, role, userID string) error {
+	if err := s.checkTeamOwner(ctx, submissionID, userID); err != nil {
+		return err
+	}
+	if !validMemberRole(role) {
+		return ErrInvalidMemberRole
+	}
+	return s.repo.SetRole(ctx, submissionID, memberID, role)
+}
+
+// Remove takes memberID off the team. The owner can remove anyone else and
+// every other member can remove themselves.
+func (s *SubmissionTeamService) Remove(ctx context.Context, submissionID, memberID, userID string) error {
+	if memberID != userID {
+		if err := s.checkTeamOwner(ctx, submissionID, userID); err != nil {
+			return err
+		}
+		return s.repo.Remove(ctx, submissionID, memberID)
+	}
+
+	role, err := s.workflow.CheckMember(ctx, submissionID, userID)
+	if err != nil {
+		return err
+	}
+	if role == model.MemberOwner {
+		return ErrOwnerCannotLeave
+	}
+	return s.repo.Remove(ctx, submissionID, userID)
+}
+
+// Accept adds actor to the team an invite token was sent for. The invite
+// only works for the account with the address it was sent to.
+func (s *SubmissionTeamService) Accept(ctx context.Context, token string, actor *auth.Claims) (*model.SubmissionInvite, error) {
+	inv, err := s.repo.FindInvite(ctx, auth.HashToken(token))
+	if errors.Is(err, repository.ErrTeamInviteNotFound) {
+		return nil, ErrInvalidInvite
+	}
+	if err != nil {
+		return nil, err
+	}
+	if normalizeEmail(actor.Email) != inv.Email {
+		return nil, ErrInviteEmailMismatch
+	}
+
+	if err := s.repo.AcceptInvite(ctx, inv.ID, actor.UserID); err != nil {
+		if errors.Is(err, repository.ErrTeamInviteNotFound) {
+			return nil, ErrInvalidInvite
+		}
+		return nil, err
+	}
+	return inv, nil
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+	"github.com/rudraa2005/mic-website-main/backend/internal/repository"
+)
+
+var (
+	ErrTransitionNotAllowed = errors.New("this status change is not allowed")
+	ErrTransitionForbidden  = errors.New("you are not allowed to make this status change")
+	ErrReasonRequired       = errors.New("a reason is required for this status change")
+	ErrSubmissionLocked     = errors.New("this submission can no longer be edited")
+	ErrViewerCannotEdit     = errors.New("viewers cannot edit this submission")
+)
+
+// SubmissionTransition is one allowed status change. Permission is what the
+// actor's roles must grant; OwnerOnly also requires the actor to own the
+// submission, so editors can prepare a draft but only the owner sends it.
+// StartsRound moves the submission to its next revision round.
+type SubmissionTransition struct {
+	From           string
+	To             string
+	Permission     string
+	OwnerOnly      bool
+	ReasonRequired bool
+	StartsRound    bool
+}
+
+// submissionTransitions is the submission life cycle:
+//
+//	draft -> submitted -> admin_approved -> approved
+//	                   \-> admin_rejected \-> rejected
+//	                                       \-> needs_improvement -> revising
+//	                                                                   |
+//	                   admin_approved <- (resubmitted to reviewers) <--/
+var submissionTransitions = []SubmissionTransition{
+	{From: model.SubmissionDraft, To: model.SubmissionSubmitted, Permission: "submissions.create", OwnerOnly: true},
+	{From: model.SubmissionSubmitted, To: model.SubmissionAdminApproved, Permission: "submissions.decide"},
+	{From: model.SubmissionSubmitted, To: model.SubmissionAdminRejected, Permission: "submissions.decide"},
+	{From: model.SubmissionAdminApproved, To: model.SubmissionApproved, Permission: "reviews.perform"},
+	{From: model.SubmissionAdminApproved, To: model.SubmissionRejected, Permission: "reviews.perform"},
+	{From: model.SubmissionAdminApproved, To: model.SubmissionNeedsImprovement, Permission: "reviews.perform"},
+	{From: model.SubmissionNeedsImprovement, To: model.SubmissionRevising, Permission: "submissions.create", OwnerOnly: true, StartsRound: true},
+	{From: model.SubmissionRevising, To: model.SubmissionAdminApproved, Permission: "submissions.create", OwnerOnly: true, ReasonRequired: true},
+}
+
+// facultyVisibleStatuses are the statuses in which faculty reviewers can see
+// a submission.
+var facultyVisibleStatuses = []string{
+	model.SubmissionAdminApproved,
+	model.SubmissionApproved,
+	model.SubmissionRejected,
+	model.SubmissionNeedsImprovement,
+	model.SubmissionRevising,
+}
+
+// editableStatuses are the statuses in which the owner and editors can
+// change a submission's content and files.
+var editableStatuses = []string{model.SubmissionDraft, model.SubmissionRevising}
+
+type SubmissionWorkflowRepository interface {
+	GetState(ctx context.Context, submissionID string) (*model.SubmissionState, error)
+	MemberRole(ctx context.Context, submissionID, userID string) (string, error)
+	Transition(ctx context.Context, submissionID, from, to string, newRound bool, change *model.StatusChange) error
+	RecordCreated(ctx context.Context, submissionID, status string, change *model.StatusChange) error
+	History(ctx context.Context, submissionID string) ([]model.StatusChange, error)
+}
+
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, roles []string, permission string) (bool, error)
+}
+
+// TransitionEvent is passed to hooks after a status change is saved.
+type TransitionEvent struct {
+	Submission model.SubmissionState
+	From       string
+	To         string
+	Actor      *auth.Claims
+	Reason     string
+}
+
+// TransitionHook runs after a transition has been saved. An error is logged
+// and does not undo the transition.
+type TransitionHook func(ctx context.Context, ev TransitionEvent) error
+
+// TransitionGuard runs before a transition is saved and stops it by
+// returning an error.
+type TransitionGuard func(ctx context.Context, state *model.SubmissionState, actor *auth.Claims) error
+
+// SubmissionWorkflow is the single place submission statuses change. It
+// checks the move is allowed and that the actor may make it, records it in
+// the submission's history and then runs the hooks for the new status.
+type SubmissionWorkflow struct {
+	repo        SubmissionWorkflowRepository
+	permissions PermissionChecker
+	hooks       map[string][]TransitionHook
+	guards      map[string][]TransitionGuard
+}
+
+func NewSubmissionWorkflow(repo SubmissionWorkflowRepository, permissions PermissionChecker) *SubmissionWorkflow {
+	return &SubmissionWorkflow{
+		repo:        repo,
+		permissions: permissions,
+		hooks:       make(map[string][]TransitionHook),
+		guards:      make(map[string][]TransitionGuard),
+	}
+}
+
+// OnEnter registers a hook that runs whenever a submission moves to status.
+// An empty status runs the hook on every transition.
+func (w *SubmissionWorkflow) OnEnter(status string, hook TransitionHook) {
+	w.hooks[status] = append(w.hooks[status], hook)
+}
+
+// Guard registers a check that must pass before a submission moves to
+// status.
+func (w *SubmissionWorkflow) Guard(status string, guard TransitionGuard) {
+	w.guards[status] = append(w.guards[status], guard)
+}
+
+// Transitions lists the allowed status changes.
+func (w *SubmissionWorkflow) Transitions() []SubmissionTransition {
+	return submissionTransitions
+}
+
+func findTransition(from, to string) (SubmissionTransition, bool) {
+	for _, t := range submissionTransitions {
+		if t.From == from && t.To == to {
+			return t, true
+		}
+	}
+	return SubmissionTransition{}, false
+}
+
+// Transition moves a submission to status to on behalf of actor. reason is
+// stored with the change and may be empty.
+func (w *SubmissionWorkflow) Transition(ctx context.Context, submissionID, to string, actor *auth.Claims, reason string) (*model.SubmissionState, error) {
+	state, err := w.repo.GetState(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := findTransition(state.Status, to)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s to %s", ErrTransitionNotAllowed, state.Status, to)
+	}
+	if err := w.authorize(ctx, t, state, actor); err != nil {
+		return nil, err
+	}
+	reason = strings.TrimSpace(reason)
+	if t.ReasonRequired && reason == "" {
+		return nil, ErrReasonRequired
+	}
+	for _, guard := range w.guards[to] {
+		if err := guard(ctx, state, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	from := state.Status
+	change := &model.StatusChange{ActorID: &actor.UserID, ActorRole: &actor.Role}
+	if reason != "" {
+		change.Reason = &reason
+	}
+	if err := w.repo.Transition(ctx, submissionID, from, to, t.StartsRound, change); err != nil {
+		return nil, err
+	}
+	state.Status = to
+	state.RevisionRound = change.RevisionRound
+
+	ev := TransitionEvent{Submission: *state, From: from, To: to, Actor: actor, Reason: reason}
+	for _, hook := range append(w.hooks[to], w.hooks[""]...) {
+		if err := hook(ctx, ev); err != nil {
+			log.Printf("[WORKFLOW] hook for %s -> %s on %s failed: %v", from, to, submissionID, err)
+		}
+	}
+	return state, nil
+}
+
+func (w *SubmissionWorkflow) authorize(ctx context.Context, t SubmissionTransition, state *model.SubmissionState, actor *auth.Claims) error {
+	if actor == nil {
+		return ErrTransitionForbidden
+	}
+	if t.OwnerOnly && state.UserID != actor.UserID {
+		return ErrTransitionForbidden
+	}
+	if actor.IsPersonalAccessToken() && !slices.Contains(actor.Scopes, t.Permission) {
+		return ErrTransitionForbidden
+	}
+	ok, err := w.permissions.HasPermission(ctx, actor.RoleSet(), t.Permission)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTransitionForbidden
+	}
+	return nil
+}
+
+type IncubationStarter interface {
+	StartIncubation(ctx context.Context, submissionID string) error
+}
+
+// StartIncubationHook creates the work record when faculty approve a
+// submission.
+func StartIncubationHook(repo IncubationStarter) TransitionHook {
+	return func(ctx context.Context, ev TransitionEvent) error {
+		return repo.StartIncubation(ctx, ev.Submission.SubmissionID)
+	}
+}
+
+// Created records the first entry in a new submission's timeline.
+func (w *SubmissionWorkflow) Created(ctx context.Context, submissionID string, actor *auth.Claims) error {
+	change := &model.StatusChange{ActorID: &actor.UserID, ActorRole: &actor.Role}
+	return w.repo.RecordCreated(ctx, submissionID, model.SubmissionDraft, change)
+}
+
+// CheckOwner returns ErrSubmissionNotFound unless the submission belongs to
+// userID.
+func (w *SubmissionWorkflow) CheckOwner(ctx context.Context, submissionID, userID string) error {
+	state, err := w.repo.GetState(ctx, submissionID)
+	if err != nil {
+		return err
+	}
+	if state.UserID != userID {
+		return repository.ErrSubmissionNotFound
+	}
+	return nil
+}
+
+// CheckMember returns userID's role on the submission's team, or
+// ErrSubmissionNotFound if they are not on it.
+func (w *SubmissionWorkflow) CheckMember(ctx context.Context, submissionID, userID string) (string, error) {
+	if _, err := w.repo.GetState(ctx, submissionID); err != nil {
+		return "", err
+	}
+	role, err := w.repo.MemberRole(ctx, submissionID, userID)
+	if err != nil {
+		return "", err
+	}
+	if role == "" {
+		return "", repository.ErrSubmissionNotFound
+	}
+	return role, nil
+}
+
+// CheckEditable returns ErrSubmissionNotFound unless userID is on the
+// submission's team, ErrTransitionForbidden if they only view it, and
+// ErrSubmissionLocked unless it is a draft or being revised.
+func (w *SubmissionWorkflow) CheckEditable(ctx context.Context, submissionID, userID string) error {
+	state, err := w.repo.GetState(ctx, submissionID)
+	if err != nil {
+		return err
+	}
+	role, err := w.repo.MemberRole(ctx, submissionID, userID)
+	if err != nil {
+		return err
+	}
+	switch role {
+	case model.MemberOwner, model.MemberEditor:
+	case "":
+		return repository.ErrSubmissionNotFound
+	default:
+		return ErrViewerCannotEdit
+	}
+	if !slices.Contains(editableStatuses, state.Status) {
+		return ErrSubmissionLocked
+	}
+	return nil
+}
+
+// CheckReviewer returns ErrSubmissionNotFound unless faculty reviewers can
+// see the submission, i.e. it has passed admin screening.
+func (w *SubmissionWorkflow) CheckReviewer(ctx context.Context, submissionID string) error {
+	state, err := w.repo.GetState(ctx, submissionID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(facultyVisibleStatuses, state.Status) {
+		return repository.ErrSubmissionNotFound
+	}
+	return nil
+}
+
+// Timeline returns a submission's history for its team.
+func (w *SubmissionWorkflow) Timeline(ctx context.Context, submissionID, userID string) ([]model.StatusChange, error) {
+	if _, err := w.CheckMember(ctx, submissionID, userID); err != nil {
+		return nil, err
+	}
+	return w.repo.History(ctx, submissionID)
+}
+
+// FullTimeline returns a submission's history for admins.
+func (w *SubmissionWorkflow) FullTimeline(ctx context.Context, submissionID string) ([]model.StatusChange, error) {
+	if _, err := w.repo.GetState(ctx, submissionID); err != nil {
+		return nil, err
+	}
+	return w.repo.History(ctx, submissionID)
+}
+
+// ReviewerTimeline returns a submission's history for faculty.
+func (w *SubmissionWorkflow) ReviewerTimeline(ctx context.Context, submissionID string) ([]model.StatusChange, error) {
+	if err := w.CheckReviewer(ctx, submissionID); err != nil {
+		return nil, err
+	}
+	return w.repo.History(ctx, submissionID)
+}
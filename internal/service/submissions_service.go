@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"io"
 	"log"
 
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
 	"github.com/rudraa2005/mic-website-main/backend/internal/model"
 )
 
@@ -13,44 +15,77 @@ type SubmissionsRepo interface {
 	GetByUserID(ctx context.Context, userID string) ([]model.Submission, error)
 	GetBySubmissionID(ctx context.Context, submissionID string) (*model.Submission, error)
 	Delete(ctx context.Context, submissionID string, userID string) error
-	MarkSubmitted(ctx context.Context, submissionID string, userID string) error
-	AttachFile(ctx context.Context, submissionID string, userID string, filePath string) error
-
-	UpdateStatus(
-		ctx context.Context,
-		submissionID string,
-		newStatus string,
-		newStage string,
-	) error
 }
 
 type SubmissionsService struct {
-	NotificationService *NotificationService
-	submissionsRepo     SubmissionsRepo
-	UserRepo            ProfileRepo
-	AIService           *AIService
+	submissionsRepo SubmissionsRepo
+	workflow        *SubmissionWorkflow
+	revisions       *SubmissionRevisionService
+	files           *SubmissionFileService
+	cycles          *ApplicationCycleService
+	forms           *ApplicationFormService
+	AIService       *AIService
 }
 
 func NewSubmissionsService(
-	notificationService *NotificationService,
 	submissionsRepo SubmissionsRepo,
-	UserRepo ProfileRepo,
+	workflow *SubmissionWorkflow,
+	revisions *SubmissionRevisionService,
+	files *SubmissionFileService,
+	cycles *ApplicationCycleService,
+	forms *ApplicationFormService,
 	AIService *AIService,
 ) *SubmissionsService {
 	return &SubmissionsService{
-		NotificationService: notificationService,
-		submissionsRepo:     submissionsRepo,
-		UserRepo:            UserRepo,
-		AIService:           AIService,
+		submissionsRepo: submissionsRepo,
+		workflow:        workflow,
+		revisions:       revisions,
+		files:           files,
+		cycles:          cycles,
+		forms:           forms,
+		AIService:       AIService,
 	}
 }
 
-func (s *SubmissionsService) Create(ctx context.Context, submission *model.Submission) error {
-	return s.submissionsRepo.Create(ctx, submission)
+// Create stores a new draft and starts its timeline and revision history.
+// Its cycle must not have closed yet.
+func (s *SubmissionsService) Create(ctx context.Context, submission *model.Submission, actor *auth.Claims) error {
+	if submission.CycleID == nil {
+		return ErrCycleRequired
+	}
+	if err := s.cycles.CheckSelectable(ctx, *submission.CycleID); err != nil {
+		return err
+	}
+	if err := s.submissionsRepo.Create(ctx, submission); err != nil {
+		return err
+	}
+	if err := s.workflow.Created(ctx, submission.SubmissionID, actor); err != nil {
+		log.Println("[SUBMIT SERVICE] failed to record creation:", err)
+	}
+	if _, err := s.revisions.Record(ctx, submission.SubmissionID, model.RevisionSave, actor.UserID); err != nil {
+		log.Println("[SUBMIT SERVICE] failed to record first revision:", err)
+	}
+	return nil
 }
 
+// UpdateDraft saves edits by the owner or an editor as a new revision.
+// Answers, when given, must fit the cycle's form but may be incomplete.
 func (s *SubmissionsService) UpdateDraft(ctx context.Context, submission *model.Submission) error {
-	return s.submissionsRepo.UpdateDraft(ctx, submission)
+	if submission.CycleID != nil {
+		if err := s.cycles.CheckSelectable(ctx, *submission.CycleID); err != nil {
+			return err
+		}
+	}
+	if submission.Answers != nil {
+		if err := s.forms.PrepareAnswers(ctx, submission); err != nil {
+			return err
+		}
+	}
+	if err := s.submissionsRepo.UpdateDraft(ctx, submission); err != nil {
+		return err
+	}
+	_, err := s.revisions.Record(ctx, submission.SubmissionID, model.RevisionSave, submission.UserID)
+	return err
 }
 
 func (s *SubmissionsService) GetByUserID(
@@ -62,95 +97,142 @@ func (s *SubmissionsService) GetByUserID(
 	return submission, nil
 }
 
-func (s *SubmissionsService) GetBySubmissionID(ctx context.Context, submissionID string) (*model.Submission, error) {
-	return s.submissionsRepo.GetBySubmissionID(ctx, submissionID)
+// GetBySubmissionID returns a submission and its form to a member of its
+// team.
+func (s *SubmissionsService) GetBySubmissionID(ctx context.Context, submissionID, userID string) (*model.Submission, error) {
+	role, err := s.workflow.CheckMember(ctx, submissionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	submission, err := s.submissionsRepo.GetBySubmissionID(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	submission.Role = role
+	if submission.Form, err = s.forms.ForSubmission(ctx, submissionID); err != nil {
+		return nil, err
+	}
+	return submission, nil
 }
 
 func (s *SubmissionsService) Delete(ctx context.Context, submissionID string, userID string) error {
 	return s.submissionsRepo.Delete(ctx, submissionID, userID)
 }
 
+// Submit sends the student's draft for admin screening. It fails with
+// ErrCycleClosed outside the window of the submission's cycle and with
+// InvalidAnswersError until the cycle's form is filled in.
 func (s *SubmissionsService) Submit(
 	ctx context.Context,
 	submissionID string,
-	userID string,
-	email string,
+	actor *auth.Claims,
 ) error {
+	_, err := s.workflow.Transition(ctx, submissionID, model.SubmissionSubmitted, actor, "")
+	return err
+}
 
-	if err := s.submissionsRepo.MarkSubmitted(ctx, submissionID, userID); err != nil {
-		return err
-	}
-
-	submission, err := s.submissionsRepo.GetBySubmissionID(ctx, submissionID)
-	if err != nil {
-		return err
-	}
-	user, err := s.UserRepo.GetUserByID(ctx, userID)
-	if err != nil || len(user.UserID) == 0 {
-		return err
-	}
-
-	log.Println("[SUBMIT SERVICE] status updated, calling notification")
-	err = s.NotificationService.NotifyStatusChange(
-		ctx,
-		submission.UserID,
-		email,
-		submissionID,
-		"draft",
-		"submitted",
-	)
-	if err != nil {
-		log.Println("notification failed:", err)
-	}
-	return nil
+// Reopen lets the student edit a submission that reviewers sent back. It
+// starts the next revision round.
+func (s *SubmissionsService) Reopen(ctx context.Context, submissionID string, actor *auth.Claims) error {
+	_, err := s.workflow.Transition(ctx, submissionID, model.SubmissionRevising, actor, "")
+	return err
 }
 
-func (s *SubmissionsService) AttachFile(
-	ctx context.Context,
-	submissionID string,
-	userID string,
-	filePath string,
-) error {
-	return s.submissionsRepo.AttachFile(ctx, submissionID, userID, filePath)
+// Resubmit sends a revised submission back to its reviewers together with
+// the student's response to their feedback.
+func (s *SubmissionsService) Resubmit(ctx context.Context, submissionID string, actor *auth.Claims, response string) error {
+	_, err := s.workflow.Transition(ctx, submissionID, model.SubmissionAdminApproved, actor, response)
+	return err
 }
 
+// UpdateStatus moves a submission to newStatus through the workflow.
 func (s *SubmissionsService) UpdateStatus(
 	ctx context.Context,
 	submissionID string,
 	newStatus string,
-	user model.User,
+	actor *auth.Claims,
+	reason string,
 ) error {
+	_, err := s.workflow.Transition(ctx, submissionID, newStatus, actor, reason)
+	return err
+}
 
-	submission, err := s.submissionsRepo.GetBySubmissionID(ctx, submissionID)
-	if err != nil {
-		return err
+// Timeline returns the status history of a submission on the user's team.
+func (s *SubmissionsService) Timeline(ctx context.Context, submissionID, userID string) ([]model.StatusChange, error) {
+	return s.workflow.Timeline(ctx, submissionID, userID)
+}
+
+// Revisions lists the saved versions of a submission on the user's team.
+func (s *SubmissionsService) Revisions(ctx context.Context, submissionID, userID string) ([]model.SubmissionRevision, error) {
+	if _, err := s.workflow.CheckMember(ctx, submissionID, userID); err != nil {
+		return nil, err
 	}
+	return s.revisions.List(ctx, submissionID)
+}
 
-	oldStatus := submission.Status
-	if oldStatus == newStatus {
-		return nil
+func (s *SubmissionsService) Revision(ctx context.Context, submissionID, userID string, number int) (*model.SubmissionRevision, error) {
+	if _, err := s.workflow.CheckMember(ctx, submissionID, userID); err != nil {
+		return nil, err
 	}
+	return s.revisions.Get(ctx, submissionID, number)
+}
 
-	err = s.submissionsRepo.UpdateStatus(
-		ctx,
-		submissionID,
-		newStatus,
-		submission.Stage,
-	)
-	if err != nil {
-		log.Println("notification failed:", err)
+// RevisionDiff shows what changed between two versions of a submission on the
+// user's team.
+func (s *SubmissionsService) RevisionDiff(ctx context.Context, submissionID, userID string, from, to int) (*model.RevisionDiff, error) {
+	if _, err := s.workflow.CheckMember(ctx, submissionID, userID); err != nil {
+		return nil, err
 	}
+	return s.revisions.Diff(ctx, submissionID, from, to)
+}
 
-	go s.NotificationService.NotifyStatusChange(
-		ctx,
-		user.UserID,
-		user.Email,
-		submissionID,
-		oldStatus,
-		newStatus,
-	)
+// OpenCycles lists the application cycles accepting submissions now.
+func (s *SubmissionsService) OpenCycles(ctx context.Context) ([]model.ApplicationCycle, error) {
+	return s.cycles.Open(ctx)
+}
 
-	return nil
+// CycleForm returns the form new drafts of a cycle are given.
+func (s *SubmissionsService) CycleForm(ctx context.Context, cycleID string) (*model.ApplicationForm, error) {
+	return s.forms.Current(ctx, cycleID)
+}
+
+// FileSlots lists the kinds of attachment submissions of a cycle carry, or
+// those of submissions without a cycle if cycleID is nil.
+func (s *SubmissionsService) FileSlots(ctx context.Context, cycleID *string) ([]model.SubmissionFileSlot, error) {
+	return s.files.CycleSlots(ctx, cycleID)
+}
+
+// SubmissionFileSlots lists the slots of a submission on the user's team.
+func (s *SubmissionsService) SubmissionFileSlots(ctx context.Context, submissionID, userID string) ([]model.SubmissionFileSlot, error) {
+	if _, err := s.workflow.CheckMember(ctx, submissionID, userID); err != nil {
+		return nil, err
+	}
+	return s.files.SubmissionSlots(ctx, submissionID)
+}
+
+// Files lists the attachments of a submission on the user's team.
+func (s *SubmissionsService) Files(ctx context.Context, submissionID, userID string) ([]model.SubmissionFile, error) {
+	if _, err := s.workflow.CheckMember(ctx, submissionID, userID); err != nil {
+		return nil, err
+	}
+	return s.files.List(ctx, submissionID)
+}
+
+// OpenFile returns one attachment of a submission on the user's team for
+// download.
+func (s *SubmissionsService) OpenFile(ctx context.Context, submissionID, userID, fileID string) (*model.SubmissionFile, io.ReadCloser, error) {
+	if _, err := s.workflow.CheckMember(ctx, submissionID, userID); err != nil {
+		return nil, nil, err
+	}
+	return s.files.Open(ctx, submissionID, fileID)
+}
+
+func (s *SubmissionsService) UploadFile(ctx context.Context, submissionID, slotKey string, actor *auth.Claims, fileName string, body io.Reader) (*model.SubmissionFile, error) {
+	return s.files.Upload(ctx, submissionID, slotKey, actor, fileName, body)
+}
+
+func (s *SubmissionsService) DeleteFile(ctx context.Context, submissionID, fileID string, actor *auth.Claims) error {
+	return s.files.Delete(ctx, submissionID, fileID, actor)
 }
 
 func (s *SubmissionsService) GetAIInsights(
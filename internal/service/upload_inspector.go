@@ -0,0 +1,257 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rudraa2005/mic-website-main/backend/internal/scan"
+	"github.com/rudraa2005/mic-website-main/backend/internal/storage"
+	"github.com/rudraa2005/mic-website-main/backend/internal/textextract"
+)
+
+// MaxUploadSize is the largest file accepted per upload.
+const MaxUploadSize = 10 << 20
+
+// quarantinePrefix holds uploads while they are scanned. Nothing is ever
+// served from it.
+const quarantinePrefix = "quarantine"
+
+var (
+	ErrFileTooLarge       = errors.New("file is larger than 10MB")
+	ErrFileTypeNotAllowed = errors.New("file type is not allowed")
+	ErrQuotaExceeded      = errors.New("storage quota exceeded")
+	ErrScanUnavailable    = errors.New("file could not be scanned, try again later")
+)
+
+// InfectedFileError rejects an upload the scanner flagged.
+type InfectedFileError struct {
+	Signature string
+}
+
+func (e *InfectedFileError) Error() string {
+	return "file rejected by virus scan: " + e.Signature
+}
+
+type UploadUsageRepository interface {
+	// Usage is the number of stored bytes uploaded by a user.
+	Usage(ctx context.Context, userID string) (int64, error)
+}
+
+// Upload is a file on its way into the blob store.
+type Upload struct {
+	UserID   string
+	FileName string
+	Body     io.Reader
+	// AllowedTypes lists the accepted MIME types. "image/*" accepts a whole
+	// family and an empty list accepts anything.
+	AllowedTypes []string
+	// Replaces is the size of the user's file this upload takes the place
+	// of, which stops counting against the quota.
+	Replaces int64
+	// Prefix is the key prefix the file is stored under once it is clean.
+	Prefix string
+	// ExtractText asks for the file's text to be read for search.
+	ExtractText bool
+}
+
+// InspectedFile is an upload that passed every check and has been stored.
+type InspectedFile struct {
+	storage.Object
+	ContentType string
+	// Text is the file's readable text when ExtractText was set.
+	Text string
+}
+
+// UploadInspector is the single way uploads reach the blob store. It checks
+// size, content type and quota, keeps the file in quarantine while it is
+// scanned, and only then stores it under its content key.
+type UploadInspector struct {
+	blobs    storage.Blob
+	scanner  scan.Scanner
+	usage    UploadUsageRepository
+	releases StorageReleaseRepository
+	quota    int64
+}
+
+// NewUploadInspector limits every user to quota stored bytes; 0 means no
+// limit.
+func NewUploadInspector(blobs storage.Blob, scanner scan.Scanner, usage UploadUsageRepository, releases StorageReleaseRepository, quota int64) *UploadInspector {
+	return &UploadInspector{blobs: blobs, scanner: scanner, usage: usage, releases: releases, quota: quota}
+}
+
+func (u *UploadInspector) Store(ctx context.Context, up *Upload) (*InspectedFile, error) {
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(up.Body, MaxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxUploadSize {
+		return nil, ErrFileTooLarge
+	}
+
+	head := make([]byte, 512)
+	n, err := tmp.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	contentType := sniffContentType(head[:n], up.FileName)
+	if !typeAllowed(contentType, up.AllowedTypes) {
+		return nil, fmt.Errorf("%w: %s", ErrFileTypeNotAllowed, contentType)
+	}
+
+	if u.quota > 0 {
+		used, err := u.usage.Usage(ctx, up.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if used-up.Replaces+size > u.quota {
+			return nil, ErrQuotaExceeded
+		}
+	}
+
+	if err := u.scan(ctx, tmp, size, contentType); err != nil {
+		return nil, err
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	f := &InspectedFile{
+		Object:      storage.Object{Key: storage.ContentKey(up.Prefix, sum), Size: size, SHA256: sum},
+		ContentType: contentType,
+	}
+	if up.ExtractText && textextract.Supported(contentType) {
+		extractCtx, cancel := context.WithTimeout(ctx, textextract.Timeout)
+		f.Text, err = textextract.Extract(extractCtx, tmp, size, contentType)
+		cancel()
+		if err != nil {
+			log.Println("[UPLOAD] failed to extract text:", err)
+		}
+	}
+
+	// An object of identical content may have been released. Claiming it
+	// first stops the sweep from deleting it under this upload.
+	if err := u.releases.Claim(ctx, f.Key); err != nil {
+		return nil, err
+	}
+	exists, err := u.blobs.Exists(ctx, f.Key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := u.blobs.Put(ctx, f.Key, tmp, size, contentType); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// scan stores the file in quarantine, scans what was stored and removes the
+// quarantined copy again.
+func (u *UploadInspector) scan(ctx context.Context, tmp *os.File, size int64, contentType string) error {
+	key := quarantinePrefix + "/" + uuid.NewString()
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := u.blobs.Put(ctx, key, tmp, size, contentType); err != nil {
+		return err
+	}
+	defer func() {
+		if err := u.blobs.Delete(context.WithoutCancel(ctx), key); err != nil {
+			log.Println("[UPLOAD] failed to remove", key+":", err)
+		}
+	}()
+
+	body, err := u.blobs.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	result, err := u.scanner.Scan(ctx, body)
+	if err != nil {
+		log.Println("[UPLOAD] scan failed:", err)
+		return ErrScanUnavailable
+	}
+	if result.Infected {
+		log.Println("[UPLOAD] rejected infected file:", result.Signature)
+		return &InfectedFileError{Signature: result.Signature}
+	}
+	return nil
+}
+
+// oleHeader starts the legacy .doc, .xls and .ppt formats.
+var oleHeader = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// containerTypes narrows sniffed container formats by extension. Office
+// documents are ZIP or OLE files that sniffing alone cannot tell apart, and
+// CSV is plain text. The extension is never trusted on its own.
+var containerTypes = map[string]map[string]string{
+	"application/zip": {
+		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	},
+	"application/x-ole-storage": {
+		".doc": "application/msword",
+		".xls": "application/vnd.ms-excel",
+		".ppt": "application/vnd.ms-powerpoint",
+	},
+	"text/plain": {
+		".csv": "text/csv",
+		".md":  "text/markdown",
+	},
+}
+
+// sniffContentType detects a file's MIME type from its first bytes.
+func sniffContentType(head []byte, fileName string) string {
+	contentType := http.DetectContentType(head)
+	if bytes.HasPrefix(head, oleHeader) {
+		contentType = "application/x-ole-storage"
+	}
+	if base, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = base
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if narrowed, ok := containerTypes[contentType][ext]; ok {
+		return narrowed
+	}
+	return contentType
+}
+
+func typeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == contentType {
+			return true
+		}
+		if family, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(contentType, family+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"unicode"
+
+	"github.com/rudraa2005/mic-website-main/backend/internal/model"
+)
+
+// maxDiffEdits bounds the work done by diffWords. Texts further apart than
+// this are shown as one deletion followed by one insertion.
+const maxDiffEdits = 1000
+
+const (
+	diffEqual  = "equal"
+	diffInsert = "insert"
+	diffDelete = "delete"
+)
+
+// diffWords compares two texts word by word with Myers' algorithm. Runs of
+// whitespace are tokens too, so joining the equal and deleted segments gives
+// back a and joining the equal and inserted ones gives back b.
+func diffWords(a, b string) []model.DiffSegment {
+	x, y := splitWords(a), splitWords(b)
+
+	ops, ok := myersDiff(x, y)
+	if !ok {
+		var segs []model.DiffSegment
+		if a != "" {
+			segs = append(segs, model.DiffSegment{Op: diffDelete, Text: a})
+		}
+		if b != "" {
+			segs = append(segs, model.DiffSegment{Op: diffInsert, Text: b})
+		}
+		return segs
+	}
+
+	segs := []model.DiffSegment{}
+	for _, op := range ops {
+		if n := len(segs); n > 0 && segs[n-1].Op == op.op {
+			segs[n-1].Text += op.text
+			continue
+		}
+		segs = append(segs, model.DiffSegment{Op: op.op, Text: op.text})
+	}
+	return segs
+}
+
+// splitWords breaks s into alternating runs of whitespace and non-whitespace.
+func splitWords(s string) []string {
+	var tokens []string
+	start := 0
+	prevSpace := false
+	for i, r := range s {
+		space := unicode.IsSpace(r)
+		if i > 0 && space != prevSpace {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		prevSpace = space
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+type diffOp struct {
+	op   string
+	text string
+}
+
+// myersDiff returns the shortest edit script turning a into b, or false if
+// it needs more than maxDiffEdits edits.
+func myersDiff(a, b []string) ([]diffOp, bool) {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxDiffEdits)
+
+	// v[offset+k] is the furthest x reached on diagonal k. trace[d] keeps
+	// the diagonals -d..d after round d for the walk back.
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				return backtrackDiff(a, b, trace), true
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	return nil, false
+}
+
+func backtrackDiff(a, b []string, trace [][]int) []diffOp {
+	x, y := len(a), len(b)
+	var ops []diffOp
+
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{diffEqual, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			ops = append(ops, diffOp{diffInsert, b[prevY]})
+		} else {
+			ops = append(ops, diffOp{diffDelete, a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		ops = append(ops, diffOp{diffEqual, a[x-1]})
+		x--
+		y--
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,98 @@
+// Package storage keeps uploaded files in a blob store. Files are addressed by
+// keys derived from their content, so the database never holds disk paths.
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Blob is a flat store of objects addressed by slash-separated keys.
+type Blob interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get returns the object's bytes. The reader is an io.ReadSeeker when the
+	// backend can seek.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete removes an object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Object describes content written with PutContent.
+type Object struct {
+	Key    string
+	Size   int64
+	SHA256 string
+}
+
+// ContentKey is the key of content with the given SHA-256 under prefix, e.g.
+// "photos/ab/cd/abcd…".
+func ContentKey(prefix, sum string) string {
+	return prefix + "/" + sum[:2] + "/" + sum[2:4] + "/" + sum
+}
+
+// IsSHA256 reports whether s is a hex-encoded SHA-256 sum.
+func IsSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil && strings.ToLower(s) == s
+}
+
+// PutContent stores body under its content key in prefix. The body is
+// spooled to a temporary file first because the key depends on the whole
+// content. Content that is already stored is not uploaded again.
+func PutContent(ctx context.Context, b Blob, prefix string, body io.Reader, contentType string) (*Object, error) {
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), body)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	obj := &Object{Key: ContentKey(prefix, sum), Size: size, SHA256: sum}
+
+	exists, err := b.Exists(ctx, obj.Key)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return obj, nil
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := b.Put(ctx, obj.Key, tmp, size, contentType); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// validKey rejects empty keys, absolute keys and keys that climb out of the
+// store with "..".
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	return path.Clean(key) == key && key != ".." && !strings.HasPrefix(key, "../")
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+)
+
+// Config selects and configures the blob store.
+type Config struct {
+	// Backend is "local" or "s3".
+	Backend string
+	// Dir is the root directory of the local backend.
+	Dir string
+	S3  S3Config
+}
+
+// ConfigFromEnv reads STORAGE_* and S3_* variables. Without them files are
+// kept under ./storage.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Backend: os.Getenv("STORAGE_BACKEND"),
+		Dir:     os.Getenv("STORAGE_DIR"),
+		S3: S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") == "true",
+		},
+	}
+	if cfg.Backend == "" {
+		cfg.Backend = "local"
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = "./storage"
+	}
+	return cfg
+}
+
+// Open returns the blob store cfg describes.
+func Open(cfg Config) (Blob, error) {
+	switch cfg.Backend {
+	case "local":
+		return NewLocal(cfg.Dir)
+	case "s3":
+		return NewS3(cfg.S3, nil)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
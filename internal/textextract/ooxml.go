@@ -0,0 +1,86 @@
+package textextract
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+)
+
+// maxPartSize stops a small archive from inflating into a huge XML part.
+const maxPartSize = 20 << 20
+
+// textParts are the parts of Word, PowerPoint and Excel files that hold
+// their text.
+var textParts = []string{
+	"word/document.xml",
+	"ppt/slides/slide*.xml",
+	"xl/sharedStrings.xml",
+}
+
+// extractOOXML reads the text runs of a .docx, .pptx or .xlsx file. All three
+// keep text in elements named t, with paragraphs in p and spreadsheet
+// strings in si.
+func extractOOXML(b *limitedBuilder, r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range zr.File {
+		if b.full() {
+			break
+		}
+		if !isTextPart(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = xmlText(io.LimitReader(rc, maxPartSize), b)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isTextPart(name string) bool {
+	for _, pattern := range textParts {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func xmlText(r io.Reader, b *limitedBuilder) error {
+	dec := xml.NewDecoder(r)
+	inText := false
+	for !b.full() {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			inText = t.Name.Local == "t"
+		case xml.EndElement:
+			inText = false
+			if t.Name.Local == "p" || t.Name.Local == "si" {
+				b.add("\n")
+			}
+		case xml.CharData:
+			if inText {
+				b.add(string(t))
+			}
+		}
+	}
+	return nil
+}
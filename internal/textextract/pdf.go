@@ -0,0 +1,184 @@
+package textextract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"strconv"
+)
+
+// extractPDF reads the strings drawn by the text operators of a PDF's
+// content streams. It does not map embedded font encodings, so documents
+// that use them contribute no text.
+func extractPDF(b *limitedBuilder, r io.ReaderAt, size int64) error {
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return err
+	}
+
+	for rest := data; !b.full(); {
+		start := bytes.Index(rest, []byte("stream"))
+		if start < 0 {
+			break
+		}
+		rest = rest[start+len("stream"):]
+		rest = bytes.TrimPrefix(rest, []byte("\r"))
+		rest = bytes.TrimPrefix(rest, []byte("\n"))
+		end := bytes.Index(rest, []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		pdfText(inflate(rest[:end]), b)
+		rest = rest[end+len("endstream"):]
+	}
+	return nil
+}
+
+// inflate decompresses a FlateDecode stream, or returns it unchanged if it
+// is not one.
+func inflate(stream []byte) []byte {
+	zr, err := zlib.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		return stream
+	}
+	defer zr.Close()
+	out, _ := io.ReadAll(io.LimitReader(zr, maxPartSize))
+	return out
+}
+
+// pdfText collects the literal strings shown by Tj, TJ, ' and " between BT
+// and ET. Large negative kerning inside a TJ array is a word gap.
+func pdfText(content []byte, b *limitedBuilder) {
+	var pending bytes.Buffer
+	inText, inArray := false, false
+
+	for i := 0; i < len(content) && !b.full(); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, n := literalString(content[i:])
+			if inText && readable(s) {
+				pending.Write(s)
+			}
+			i += n
+			continue
+		case c == '[':
+			inArray = true
+		case c == ']':
+			inArray = false
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+			continue
+		case isPDFDelimiter(c) || c == ' ' || c == '\n' || c == '\r' || c == '\t':
+		default:
+			j := i
+			for j < len(content) && !isPDFDelimiter(content[j]) && content[j] > ' ' {
+				j++
+			}
+			if j == i {
+				// Control bytes, such as those of image data, are no
+				// operator or operand.
+				i++
+				continue
+			}
+			word := string(content[i:j])
+			i = j
+			if inArray {
+				if n, err := strconv.ParseFloat(word, 64); err == nil && n < -200 {
+					pending.WriteByte(' ')
+				}
+				continue
+			}
+			switch word {
+			case "BT":
+				inText = true
+			case "ET":
+				inText = false
+				b.add("\n")
+			case "Tj", "TJ", "'", "\"":
+				b.add(latin1(pending.Bytes()))
+				pending.Reset()
+			case "Td", "TD", "T*", "Tm":
+				b.add(" ")
+			}
+			continue
+		}
+		i++
+	}
+}
+
+// literalString decodes the PDF string starting at s[0] == '(' and returns
+// it with the number of bytes it took up.
+func literalString(s []byte) ([]byte, int) {
+	var out []byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out, i + 1
+			}
+			out = append(out, c)
+		case '\\':
+			i++
+			if i >= len(s) {
+				return out, i
+			}
+			switch e := s[i]; e {
+			case 'n', 'r', 't', 'b', 'f':
+				out = append(out, ' ')
+			case '\r', '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					j := i
+					for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+						j++
+					}
+					v, _ := strconv.ParseUint(string(s[i:j]), 8, 8)
+					out = append(out, byte(v))
+					i = j - 1
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, len(s)
+}
+
+// readable reports whether a string looks like single-byte text rather than
+// glyph IDs of an embedded font.
+func readable(s []byte) bool {
+	if len(s) == 0 {
+		return false
+	}
+	odd := 0
+	for _, c := range s {
+		if c < ' ' && c != '\t' {
+			odd++
+		}
+	}
+	return odd*10 < len(s)
+}
+
+func latin1(s []byte) string {
+	r := make([]rune, len(s))
+	for i, c := range s {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
+
+func isPDFDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
@@ -0,0 +1,104 @@
+// Package textextract pulls the readable text out of uploaded documents so
+// they can be searched.
+package textextract
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxText is the most text kept per file. Postgres limits a tsvector to 1MB
+// and the start of a document is what matters for finding it.
+const MaxText = 256 << 10
+
+// Timeout is how long callers should let the extraction of one file run.
+// Files that take longer are left without text.
+const Timeout = 5 * time.Second
+
+// Supported reports whether text can be extracted from files of contentType.
+func Supported(contentType string) bool {
+	_, ok := extractors[contentType]
+	return ok || strings.HasPrefix(contentType, "text/")
+}
+
+var extractors = map[string]func(b *limitedBuilder, r io.ReaderAt, size int64) error{
+	"application/pdf": extractPDF,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   extractOOXML,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": extractOOXML,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         extractOOXML,
+}
+
+// Extract returns the text of a file of contentType, cut to MaxText. Files
+// of unsupported types have none. Text that cannot be read, such as a PDF
+// with embedded font encodings, is skipped rather than reported. Extraction
+// stops with ctx's error once ctx is done.
+func Extract(ctx context.Context, r io.ReaderAt, size int64, contentType string) (string, error) {
+	b := &limitedBuilder{ctx: ctx}
+	var err error
+	if extract, ok := extractors[contentType]; ok {
+		err = extract(b, r, size)
+	} else if strings.HasPrefix(contentType, "text/") {
+		_, err = io.Copy(b, io.NewSectionReader(r, 0, min(size, MaxText)))
+	}
+	if err == nil {
+		err = b.err
+	}
+	if err != nil {
+		return "", err
+	}
+	return clean(b.String()), nil
+}
+
+// clean makes text valid UTF-8 without control characters, collapses runs
+// of whitespace and cuts it to MaxText.
+func clean(text string) string {
+	text = strings.ToValidUTF8(text, " ")
+	var b strings.Builder
+	space := false
+	for _, r := range text {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			space = b.Len() > 0
+			continue
+		}
+		if b.Len()+utf8.RuneLen(r)+1 > MaxText {
+			break
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// limitedBuilder collects text and reports when MaxText is reached or its
+// context is done so extractors can stop early.
+type limitedBuilder struct {
+	strings.Builder
+	ctx   context.Context
+	err   error
+	polls int
+}
+
+// full is called for every byte or token an extractor reads, so the context
+// is only looked at now and then.
+func (b *limitedBuilder) full() bool {
+	if b.err != nil || b.Len() >= MaxText {
+		return true
+	}
+	if b.polls++; b.polls%1024 == 0 {
+		b.err = b.ctx.Err()
+	}
+	return b.err != nil
+}
+
+func (b *limitedBuilder) add(s string) {
+	if !b.full() {
+		b.WriteString(s)
+	}
+}
@@ -0,0 +1,14 @@
+-- Track whether a user has proven they own their email address.
+-- Accounts that existed before verification was introduced are treated as verified.
+-- The backfill only runs when the column is added, so later runs of the
+-- migrations leave pending signups unverified.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'email_verified_at') THEN
+        ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+        UPDATE users
+        SET email_verified_at = created_at;
+    END IF;
+END $$;
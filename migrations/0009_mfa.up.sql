@@ -0,0 +1,29 @@
+-- TOTP two-factor authentication
+
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id UUID PRIMARY KEY,
+    totp_secret TEXT NOT NULL,
+    enabled_at TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE(user_id, code_hash)
+);
+
+-- Per-role policy: when required, users with that role cannot reach routes
+-- guarded by RequireMFA until they have completed a second factor.
+CREATE TABLE IF NOT EXISTS mfa_policies (
+    role TEXT PRIMARY KEY,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by UUID,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS mfa_verified BOOLEAN NOT NULL DEFAULT FALSE;
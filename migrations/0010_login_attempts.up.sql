@@ -0,0 +1,11 @@
+-- Failed login counters, keyed by "account:<email>" or "ip:<address>", so
+-- lockouts survive a restart and are shared between server instances.
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key TEXT PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL DEFAULT now(),
+    locked_until TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_locked_until ON login_attempts(locked_until);
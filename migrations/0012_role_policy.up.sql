@@ -0,0 +1,48 @@
+-- Stored role assignment policy, replacing the hard-coded email suffixes.
+-- A new account's role comes from, in order: an email override, a pending
+-- invite, the SSO role claim, then the domain rule for its email domain.
+
+CREATE TABLE IF NOT EXISTS role_domain_rules (
+    domain TEXT PRIMARY KEY,
+    role TEXT NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+INSERT INTO role_domain_rules (domain, role) VALUES
+    ('learner.manipal.edu', 'STUDENT'),
+    ('manipal.edu', 'FACULTY')
+ON CONFLICT (domain) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS role_email_overrides (
+    email TEXT PRIMARY KEY,
+    role TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS role_invites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email TEXT NOT NULL,
+    role TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_invites_email ON role_invites(email);
+
+CREATE TABLE IF NOT EXISTS role_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    previous_role TEXT NOT NULL,
+    new_role TEXT NOT NULL,
+    changed_by UUID,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_audit_log_user ON role_audit_log(user_id);
@@ -0,0 +1,54 @@
+-- One identity store with several roles per user.
+--
+-- users.role is kept as the user's default role: it is the role a new
+-- session acts as until the user switches. user_roles holds every role the
+-- user may act as, including the default one.
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    granted_by UUID,
+    granted_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role ON user_roles(role);
+
+-- Move accounts from the old separate faculty table into users, keeping
+-- their ids so existing references stay valid. The table is renamed rather
+-- than dropped so it can be inspected after the upgrade.
+DO $$
+BEGIN
+    IF to_regclass('public.faculty') IS NOT NULL THEN
+        INSERT INTO users (id, name, email, password_hash, role, email_verified_at)
+        SELECT id, name, email, password_hash, role, now()
+        FROM faculty
+        ON CONFLICT DO NOTHING;
+
+        ALTER TABLE faculty RENAME TO faculty_legacy;
+    END IF;
+END $$;
+
+INSERT INTO user_roles (user_id, role)
+SELECT id, role FROM users
+ON CONFLICT DO NOTHING;
+
+-- Every new account holds its default role, whichever code path created it
+CREATE OR REPLACE FUNCTION grant_default_role()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO user_roles (user_id, role)
+    VALUES (NEW.id, NEW.role)
+    ON CONFLICT DO NOTHING;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS users_grant_default_role ON users;
+CREATE TRIGGER users_grant_default_role
+AFTER INSERT ON users
+FOR EACH ROW
+EXECUTE FUNCTION grant_default_role();
+
+-- The role a session is currently acting as
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS active_role TEXT;
@@ -0,0 +1,61 @@
+-- Named permissions grouped into roles. Routes are guarded with
+-- RequirePermission instead of role names, so what a role may do can be
+-- changed here without a deploy.
+
+CREATE TABLE IF NOT EXISTS roles (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission TEXT NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    granted_by UUID,
+    granted_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('ADMIN', 'Innovation centre staff'),
+    ('FACULTY', 'Faculty reviewers and mentors'),
+    ('STUDENT', 'Students submitting ideas')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('content.write', 'Create, edit and delete site content'),
+    ('submissions.view', 'List and read every submission'),
+    ('submissions.decide', 'Accept or reject submissions and edit their tags'),
+    ('submissions.assign', 'Assign faculty reviewers to submissions'),
+    ('submissions.create', 'Create and manage one''s own submissions, startups and queries'),
+    ('reviews.perform', 'Review assigned submissions and track their progress'),
+    ('faculty.view', 'List faculty accounts'),
+    ('faculty.manage', 'Create, update and delete faculty accounts'),
+    ('work.manage', 'Manage the incubation pipeline and partner companies'),
+    ('security.manage', 'Set two-factor policies and clear login lockouts'),
+    ('roles.manage', 'Manage role assignment, users'' roles and role permissions'),
+    ('settings.manage', 'Change one''s own account settings')
+ON CONFLICT DO NOTHING;
+
+-- Matches what the hard-coded role checks allowed, except that faculty can
+-- no longer create, update or delete other faculty.
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'content.write'),
+    ('ADMIN', 'submissions.view'),
+    ('ADMIN', 'submissions.decide'),
+    ('ADMIN', 'submissions.assign'),
+    ('ADMIN', 'faculty.view'),
+    ('ADMIN', 'faculty.manage'),
+    ('ADMIN', 'work.manage'),
+    ('ADMIN', 'security.manage'),
+    ('ADMIN', 'roles.manage'),
+    ('FACULTY', 'content.write'),
+    ('FACULTY', 'faculty.view'),
+    ('FACULTY', 'reviews.perform'),
+    ('STUDENT', 'submissions.create'),
+    ('STUDENT', 'settings.manage')
+ON CONFLICT DO NOTHING;
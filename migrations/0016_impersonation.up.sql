@@ -0,0 +1,36 @@
+-- Admins viewing the site as another user. The impersonation token is tied
+-- to the admin's own session, so signing out ends it too. Every request
+-- made with it is written to impersonation_requests.
+
+CREATE TABLE IF NOT EXISTS impersonations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id UUID NOT NULL,
+    reason TEXT NOT NULL,
+    read_only BOOLEAN NOT NULL DEFAULT TRUE,
+    started_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonations_started_at ON impersonations(started_at DESC);
+
+CREATE TABLE IF NOT EXISTS impersonation_requests (
+    id BIGSERIAL PRIMARY KEY,
+    impersonation_id UUID NOT NULL REFERENCES impersonations(id) ON DELETE CASCADE,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    status INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonation_requests_impersonation ON impersonation_requests(impersonation_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users.impersonate', 'View the site as another user')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'users.impersonate')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+-- When and from where each session was last used, so users can review and
+-- end their sessions from settings. AuthMiddleware updates these at most
+-- once a minute per session.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP NOT NULL DEFAULT now();
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS last_seen_ip TEXT;
+
+UPDATE sessions SET last_seen_at = refreshed_at, last_seen_ip = ip_address;
@@ -0,0 +1,24 @@
+-- Every submission status change, written by the submission workflow in the
+-- same transaction as the change itself. from_status is NULL for the entry
+-- that records a submission being created.
+CREATE TABLE IF NOT EXISTS submission_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    submission_id UUID NOT NULL REFERENCES submissions(submission_id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    actor_role TEXT,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_status_history_submission
+    ON submission_status_history(submission_id, created_at);
+
+-- Existing submissions start their timeline at their current status
+INSERT INTO submission_status_history (submission_id, from_status, to_status, reason, created_at)
+SELECT s.submission_id, NULL, s.status, 'recorded when status history was introduced', s.updated_at
+FROM submissions s
+WHERE NOT EXISTS (
+    SELECT 1 FROM submission_status_history h WHERE h.submission_id = s.submission_id
+);
@@ -0,0 +1,15 @@
+-- Revise-and-resubmit. A submission sent back as needs_improvement can be
+-- reopened by its owner (status revising) and resubmitted straight to its
+-- reviewers. revision_round counts how many times that has happened.
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS revision_round INT NOT NULL DEFAULT 0;
+ALTER TABLE submission_status_history ADD COLUMN IF NOT EXISTS revision_round INT NOT NULL DEFAULT 0;
+
+-- Submission settings admins can change at runtime. There is a single row.
+CREATE TABLE IF NOT EXISTS submission_settings (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    max_revision_rounds INT NOT NULL DEFAULT 3 CHECK (max_revision_rounds >= 0),
+    updated_by UUID,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+INSERT INTO submission_settings (id) VALUES (TRUE) ON CONFLICT DO NOTHING;
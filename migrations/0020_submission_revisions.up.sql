@@ -0,0 +1,39 @@
+-- Immutable snapshots of a submission's content. One is written on every
+-- save and every submit, numbered from 1 per submission.
+CREATE TABLE IF NOT EXISTS submission_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    submission_id UUID NOT NULL REFERENCES submissions(submission_id) ON DELETE CASCADE,
+    number INT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('save', 'submit')),
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    file_path TEXT,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (submission_id, number)
+);
+
+CREATE OR REPLACE FUNCTION reject_submission_revision_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'submission revisions cannot be changed';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS submission_revisions_immutable ON submission_revisions;
+CREATE TRIGGER submission_revisions_immutable
+BEFORE UPDATE ON submission_revisions
+FOR EACH ROW
+EXECUTE FUNCTION reject_submission_revision_update();
+
+-- Existing submissions start with their current content as revision 1
+INSERT INTO submission_revisions (submission_id, number, kind, title, description, file_path, author_id, created_at)
+SELECT s.submission_id, 1, CASE WHEN s.status = 'draft' THEN 'save' ELSE 'submit' END,
+       COALESCE(s.title, ''), COALESCE(s.description, ''), s.file_path, s.user_id, s.updated_at
+FROM submissions s
+WHERE NOT EXISTS (
+    SELECT 1 FROM submission_revisions r WHERE r.submission_id = s.submission_id
+);
+
+-- The revision a piece of feedback was written against
+ALTER TABLE feedbacks ADD COLUMN IF NOT EXISTS revision_id UUID REFERENCES submission_revisions(id) ON DELETE SET NULL;
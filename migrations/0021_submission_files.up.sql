@@ -0,0 +1,69 @@
+-- Typed attachment slots a submission can fill, e.g. a pitch deck and a
+-- financial model. Required slots must hold a file before submitting.
+CREATE TABLE IF NOT EXISTS submission_file_slots (
+    key TEXT PRIMARY KEY CHECK (key ~ '^[a-z0-9_]+$'),
+    label TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    position INT NOT NULL DEFAULT 0,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+INSERT INTO submission_file_slots (key, label, description, required, position) VALUES
+    ('pitch_deck', 'Pitch deck', 'Slides presenting the idea', TRUE, 1),
+    ('business_plan', 'Business plan', 'Market, model and go-to-market plan', FALSE, 2),
+    ('financial_model', 'Financial model', 'Spreadsheet with projections', FALSE, 3)
+ON CONFLICT DO NOTHING;
+
+-- One file per slot per submission. Uploading to a filled slot replaces it.
+CREATE TABLE IF NOT EXISTS submission_files (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    submission_id UUID NOT NULL REFERENCES submissions(submission_id) ON DELETE CASCADE,
+    slot_key TEXT NOT NULL REFERENCES submission_file_slots(key) ON UPDATE CASCADE,
+    file_name TEXT NOT NULL,
+    storage_path TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    mime_type TEXT NOT NULL DEFAULT 'application/octet-stream',
+    checksum TEXT,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (submission_id, slot_key)
+);
+
+-- The single file_path each submission had becomes its pitch deck. Size and
+-- checksum of those files are unknown. submissions.file_path is cleared so
+-- running the migrations again cannot bring back a pitch deck that was
+-- deleted since, and it is no longer written.
+INSERT INTO submission_files (submission_id, slot_key, file_name, storage_path, uploaded_by, created_at)
+SELECT s.submission_id, 'pitch_deck', regexp_replace(s.file_path, '^.*/', ''), s.file_path, s.user_id, s.updated_at
+FROM submissions s
+WHERE s.file_path IS NOT NULL AND s.file_path <> ''
+ON CONFLICT DO NOTHING;
+
+UPDATE submissions SET file_path = NULL WHERE file_path IS NOT NULL;
+
+-- Revisions snapshot the set of attached files instead of one path
+ALTER TABLE submission_revisions ADD COLUMN IF NOT EXISTS files JSONB NOT NULL DEFAULT '[]';
+
+-- Only possible the first time, while revisions still have file_path
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'submission_revisions' AND column_name = 'file_path') THEN
+        ALTER TABLE submission_revisions DISABLE TRIGGER submission_revisions_immutable;
+        UPDATE submission_revisions rv
+        SET files = jsonb_build_array(jsonb_build_object(
+            'slot_key', f.slot_key,
+            'file_id', f.id,
+            'file_name', f.file_name,
+            'size_bytes', f.size_bytes,
+            'checksum', f.checksum
+        ))
+        FROM submission_files f
+        WHERE f.submission_id = rv.submission_id
+          AND f.storage_path = rv.file_path;
+        ALTER TABLE submission_revisions ENABLE TRIGGER submission_revisions_immutable;
+
+        ALTER TABLE submission_revisions DROP COLUMN file_path;
+    END IF;
+END $$;
@@ -0,0 +1,27 @@
+-- MIME types each slot accepts, e.g. application/pdf or image/*. An empty
+-- list accepts any type.
+ALTER TABLE submission_file_slots ADD COLUMN IF NOT EXISTS allowed_types TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE submission_file_slots SET allowed_types = ARRAY[
+    'application/pdf',
+    'application/vnd.openxmlformats-officedocument.presentationml.presentation',
+    'application/vnd.ms-powerpoint'
+] WHERE key = 'pitch_deck' AND allowed_types = '{}';
+
+UPDATE submission_file_slots SET allowed_types = ARRAY[
+    'application/pdf',
+    'application/vnd.openxmlformats-officedocument.wordprocessingml.document',
+    'application/msword'
+] WHERE key = 'business_plan' AND allowed_types = '{}';
+
+UPDATE submission_file_slots SET allowed_types = ARRAY[
+    'application/pdf',
+    'application/vnd.openxmlformats-officedocument.spreadsheetml.sheet',
+    'application/vnd.ms-excel',
+    'text/csv'
+] WHERE key = 'financial_model' AND allowed_types = '{}';
+
+-- Profile photos count towards their owner's storage quota
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS photo_size_bytes BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_submission_files_uploaded_by ON submission_files(uploaded_by);
@@ -0,0 +1,37 @@
+-- The people working on a submission. The owner is submissions.user_id;
+-- editors can change the draft and viewers can only read it.
+CREATE TABLE IF NOT EXISTS submission_members (
+    submission_id UUID NOT NULL REFERENCES submissions(submission_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (submission_id, user_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_submission_members_owner ON submission_members(submission_id) WHERE role = 'owner';
+CREATE INDEX IF NOT EXISTS idx_submission_members_user ON submission_members(user_id);
+
+INSERT INTO submission_members (submission_id, user_id, role, created_at)
+SELECT submission_id, user_id, 'owner', created_at
+FROM submissions
+WHERE user_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Emailed invitations to join a submission's team, accepted through a
+-- one-time token link.
+CREATE TABLE IF NOT EXISTS submission_invites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    submission_id UUID NOT NULL REFERENCES submissions(submission_id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('editor', 'viewer')),
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_invites_submission ON submission_invites(submission_id);
@@ -0,0 +1,22 @@
+-- Intakes the centre runs, e.g. "Spring 2027 Pre-Incubation". Students can
+-- only submit to a cycle while it is open. Times carry a zone because admins
+-- set them by the clock.
+CREATE TABLE IF NOT EXISTS application_cycles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    opens_at TIMESTAMPTZ NOT NULL,
+    closes_at TIMESTAMPTZ NOT NULL,
+    review_deadline TIMESTAMPTZ,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK (closes_at > opens_at),
+    CHECK (review_deadline IS NULL OR review_deadline >= closes_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_application_cycles_window ON application_cycles(opens_at, closes_at);
+
+-- Submissions made before cycles existed have none and are not limited.
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS cycle_id UUID REFERENCES application_cycles(id);
+CREATE INDEX IF NOT EXISTS idx_submissions_cycle ON submissions(cycle_id);
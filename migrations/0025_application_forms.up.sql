@@ -0,0 +1,16 @@
+-- The questions a cycle asks on top of title and description. Each change
+-- an admin publishes is a new version; older versions are kept so answers
+-- always have the questions they were given to.
+CREATE TABLE IF NOT EXISTS application_forms (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cycle_id UUID NOT NULL REFERENCES application_cycles(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    fields JSONB NOT NULL DEFAULT '[]',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (cycle_id, version)
+);
+
+-- form_id is the version the answers were last checked against.
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS answers JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS form_id UUID REFERENCES application_forms(id);
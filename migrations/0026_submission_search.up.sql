@@ -0,0 +1,54 @@
+-- Full-text search over submissions. Attachments contribute the text read
+-- from them on upload; go run ./cmd/extract-text fills it in for older files.
+ALTER TABLE submission_files ADD COLUMN IF NOT EXISTS text_content TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+
+-- Titles rank above tags and domain, which rank above the description and
+-- then attachments. Arguments: submission_id, title, description, tags,
+-- domain.
+CREATE OR REPLACE FUNCTION submission_search_vector(UUID, TEXT, TEXT, TEXT[], TEXT)
+RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('english', COALESCE($2, '')), 'A')
+        || setweight(to_tsvector('english', array_to_string(COALESCE($4, '{}'), ' ') || ' ' || COALESCE($5, '')), 'B')
+        || setweight(to_tsvector('english', COALESCE($3, '')), 'C')
+        || setweight(to_tsvector('english', COALESCE(
+               (SELECT string_agg(f.text_content, ' ') FROM submission_files f WHERE f.submission_id = $1), ''
+           )), 'D')
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION refresh_submission_search()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := submission_search_vector(NEW.submission_id, NEW.title, NEW.description, NEW.tags, NEW.domain);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS submissions_search ON submissions;
+CREATE TRIGGER submissions_search
+BEFORE INSERT OR UPDATE OF title, description, tags, domain ON submissions
+FOR EACH ROW
+EXECUTE FUNCTION refresh_submission_search();
+
+CREATE OR REPLACE FUNCTION refresh_submission_search_for_file()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE submissions s
+    SET search_vector = submission_search_vector(s.submission_id, s.title, s.description, s.tags, s.domain)
+    WHERE s.submission_id = COALESCE(NEW.submission_id, OLD.submission_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS submission_files_search ON submission_files;
+CREATE TRIGGER submission_files_search
+AFTER INSERT OR DELETE OR UPDATE OF text_content ON submission_files
+FOR EACH ROW
+EXECUTE FUNCTION refresh_submission_search_for_file();
+
+UPDATE submissions
+SET search_vector = submission_search_vector(submission_id, title, description, tags, domain);
+
+CREATE INDEX IF NOT EXISTS idx_submissions_search ON submissions USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_submissions_tags ON submissions USING GIN (tags);
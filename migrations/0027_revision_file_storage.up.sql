@@ -0,0 +1,16 @@
+-- Revisions remember where each file's bytes are stored, so a replaced or
+-- deleted file is kept for as long as a revision refers to it.
+ALTER TABLE submission_revisions DISABLE TRIGGER submission_revisions_immutable;
+UPDATE submission_revisions rv
+SET files = (
+    SELECT jsonb_agg(CASE
+        WHEN f.id IS NULL THEN e
+        ELSE e || jsonb_build_object('storage_path', f.storage_path)
+    END ORDER BY ord)
+    FROM jsonb_array_elements(rv.files) WITH ORDINALITY AS x(e, ord)
+    LEFT JOIN submission_files f ON f.id::text = e->>'file_id'
+)
+WHERE jsonb_array_length(rv.files) > 0;
+ALTER TABLE submission_revisions ENABLE TRIGGER submission_revisions_immutable;
+
+CREATE INDEX IF NOT EXISTS idx_submission_revisions_files ON submission_revisions USING GIN (files jsonb_path_ops);
@@ -0,0 +1,19 @@
+-- File slots belong to an application cycle. Slots without a cycle are the
+-- default set, used by submissions without a cycle and by cycles that have
+-- no slots of their own.
+ALTER TABLE submission_files DROP CONSTRAINT IF EXISTS submission_files_slot_key_fkey;
+ALTER TABLE submission_file_slots DROP CONSTRAINT IF EXISTS submission_file_slots_pkey;
+
+ALTER TABLE submission_file_slots ADD COLUMN IF NOT EXISTS id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE submission_file_slots ADD PRIMARY KEY (id);
+ALTER TABLE submission_file_slots ADD COLUMN IF NOT EXISTS cycle_id UUID REFERENCES application_cycles(id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_submission_file_slots_cycle_key
+    ON submission_file_slots ((COALESCE(cycle_id, '00000000-0000-0000-0000-000000000000'::uuid)), key);
+
+-- The cycle whose slots apply to submissions of a cycle, or NULL for the
+-- default set.
+CREATE OR REPLACE FUNCTION file_slot_cycle(cycle UUID)
+RETURNS UUID AS $$
+    SELECT cycle WHERE EXISTS (SELECT 1 FROM submission_file_slots WHERE cycle_id = cycle)
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1,8 @@
+-- Stored objects no file points at any more. They are deleted by a sweep
+-- some time later rather than right away, because an upload of identical
+-- content may be about to reuse them. Uploads remove their key from here
+-- before checking whether the object exists.
+CREATE TABLE IF NOT EXISTS storage_releases (
+    key TEXT PRIMARY KEY,
+    released_at TIMESTAMP NOT NULL DEFAULT now()
+);
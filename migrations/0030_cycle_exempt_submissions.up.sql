@@ -0,0 +1,11 @@
+-- Only submissions made before there was any cycle to choose may be
+-- submitted without one. Everything else must pick a cycle and is limited
+-- to its window.
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS cycle_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE submissions s
+SET cycle_exempt = TRUE
+WHERE s.cycle_id IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM application_cycles c WHERE c.created_at <= s.created_at
+  );
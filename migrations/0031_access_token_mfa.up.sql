@@ -0,0 +1,18 @@
+-- Whether a personal access token was created from a 2FA-verified session.
+-- RequireMFA trusts the token only while this is set and the owner still has
+-- 2FA turned on. Existing tokens are backfilled from the owner's current
+-- enrolment, once, when the column is added.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'personal_access_tokens' AND column_name = 'mfa_verified'
+    ) THEN
+        ALTER TABLE personal_access_tokens ADD COLUMN mfa_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+        UPDATE personal_access_tokens t
+        SET mfa_verified = TRUE
+        FROM user_mfa m
+        WHERE m.user_id = t.user_id AND m.enabled_at IS NOT NULL;
+    END IF;
+END $$;
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"os"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/rudraa2005/mic-website-main/backend/internal/auth"
 )
 
 func main() {
@@ -14,14 +14,14 @@ func main() {
 	}
 
 	password := os.Args[1]
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	hash, err := auth.HashPassword(password)
 	if err != nil {
 		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
 
 	fmt.Println("Password:", password)
-	fmt.Println("Hash:", string(hash))
+	fmt.Println("Hash:", hash)
 	fmt.Println("\nSQL to update password:")
-	fmt.Printf("UPDATE users SET password_hash = '%s', updated_at = NOW() WHERE email = 'YOUR_EMAIL';\n", string(hash))
+	fmt.Printf("UPDATE users SET password_hash = '%s', updated_at = NOW() WHERE email = 'YOUR_EMAIL';\n", hash)
 }
@@ -1,6 +1,9 @@
 -- Create admin user for MIC Website
 -- Password should be changed after first login
 -- Run this SQL in your PostgreSQL database
+--
+-- This is only needed for the very first admin. After that, admins can grant
+-- roles with PUT /api/admin/roles/overrides/{email} or /api/admin/roles/invites.
 
 -- First, check if admin user already exists
 DO $$
@@ -19,7 +22,8 @@ BEGIN
             'MIC Admin',
             'admin@mic.mahe.edu.in',
             -- Password: MICAdmin@2024 (you should change this)
-            -- This is a bcrypt hash - you may need to generate a new one
+            -- This is a legacy bcrypt hash; it is upgraded to Argon2id on first
+            -- login. Generate your own with: go run scripts/generate_hash.go <password>
             '$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy',
             'ADMIN',
             NOW(),